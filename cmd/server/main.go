@@ -5,24 +5,51 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 	"time"
 
-	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 
 	"go-api-scaffold/internal/config"
+	"go-api-scaffold/internal/event"
 	"go-api-scaffold/internal/handler"
 	"go-api-scaffold/internal/middleware"
 	"go-api-scaffold/internal/model"
 	"go-api-scaffold/internal/repository"
 	"go-api-scaffold/internal/router"
 	"go-api-scaffold/internal/service"
+	"go-api-scaffold/internal/webhook"
+	"go-api-scaffold/internal/worker"
+	"go-api-scaffold/pkg/apiversion"
+	"go-api-scaffold/pkg/cache"
 	"go-api-scaffold/pkg/database"
+	"go-api-scaffold/pkg/eventbus"
+	"go-api-scaffold/pkg/idempotency"
+	"go-api-scaffold/pkg/jobs"
+	"go-api-scaffold/pkg/lifecycle"
 	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/response"
+	"go-api-scaffold/pkg/scheduler"
+	"go-api-scaffold/pkg/tracing"
 )
 
+// @title Go API Scaffold
+// @version 1.0
+// @description 一个基于Go语言的RESTful API脚手架框架
+// @description 接口响应按Accept头协商格式：application/json（默认）、application/xml、application/msgpack、application/x-protobuf（google.protobuf.Struct），请求体按Content-Type解析；文档中的结构以JSON描述
+// @description 错误响应默认为统一响应结构，可配置为 RFC 7807 问题详情（application/problem+json），Accept中请求 application/problem+json 时总是返回问题详情
+// @termsOfService http://swagger.io/terms/
+// @contact.name API Support
+// @contact.url http://www.swagger.io/support
+// @contact.email support@swagger.io
+// @license.name MIT
+// @license.url https://opensource.org/licenses/MIT
+// @BasePath /
+// @schemes http https
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description API密钥认证，格式为 "Bearer <key>"，密钥在 auth.api_keys 中配置
 func main() {
 	// 加载配置
 	cfg, err := config.Load()
@@ -31,72 +58,235 @@ func main() {
 	}
 
 	// 初始化日志
-	appLogger := logger.New(cfg.Log.Level)
+	appLogger := logger.New(cfg.Log)
+
+	// 生命周期管理，按阶段顺序执行关闭钩子
+	lc := lifecycle.New(lifecycle.Options{
+		Timeout:      cfg.Server.ShutdownTimeout,
+		PreStopDelay: cfg.Server.PreStopDelay,
+	}, appLogger)
+
+	// 分布式追踪，未启用时使用no-op实现
+	tracerProvider, err := tracing.New(cfg.Tracing, cfg.App)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	lc.OnShutdown(lifecycle.PhaseFlush, "tracing", tracerProvider.Shutdown)
 
 	// 连接数据库
-	db, err := database.New(cfg.Database)
+	db, err := database.New(cfg.Database, appLogger)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	appLogger.Info("Database connected successfully")
+	lc.OnShutdown(lifecycle.PhaseResources, "database", func(context.Context) error {
+		return database.Close(db)
+	})
+	if tracerProvider != nil {
+		if err := db.Use(tracing.NewGormPlugin()); err != nil {
+			log.Fatalf("Failed to register database tracing: %v", err)
+		}
+	}
+
+	// 运行期连接探测，断线时由就绪检查反映而不是退出进程
+	dbMonitor := database.NewMonitor(db, appLogger, cfg.Database.HealthCheckPeriod)
+	lc.OnShutdown(lifecycle.PhaseResources, "database_monitor", func(context.Context) error {
+		dbMonitor.Stop()
+		return nil
+	})
 
 	// 自动迁移数据库表
-	if err := db.AutoMigrate(&model.User{}); err != nil {
+	if err := db.AutoMigrate(&model.User{}, &model.AuditLog{}, &model.OutboxMessage{},
+		&model.WebhookSubscription{}, &model.WebhookDelivery{}, &jobs.Record{},
+		&scheduler.Lease{}, &scheduler.Run{}, &idempotency.Record{}); err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 	appLogger.Info("Database migration completed")
 
+	// 初始化Redis（仅在缓存或幂等键使用redis存储时）
+	var redisClient *redis.Client
+	if strings.EqualFold(cfg.Cache.Store, "redis") ||
+		cfg.Idempotency.Enabled && strings.EqualFold(cfg.Idempotency.Store, "redis") {
+		redisClient = cache.NewRedisClient(cfg.Redis)
+		lc.OnShutdown(lifecycle.PhaseResources, "redis", func(context.Context) error {
+			return redisClient.Close()
+		})
+	}
+
 	// 初始化仓储层
-	userRepo := repository.NewUserRepository(db)
+	repos := repository.New(db)
+	if err := repos.EnableCache(cfg.Cache, redisClient, appLogger); err != nil {
+		log.Fatalf("Failed to initialize cache: %v", err)
+	}
+
+	// 公共接口共享响应缓存
+	var responseCache cache.Store
+	if cfg.Cache.Resource("http").Enabled {
+		if responseCache, err = cache.NewStore(cfg.Cache, redisClient, "http"); err != nil {
+			log.Fatalf("Failed to initialize response cache: %v", err)
+		}
+	}
+
+	// POST接口幂等键存储
+	var idempotencyStore idempotency.Store
+	if cfg.Idempotency.Enabled {
+		if idempotencyStore, err = idempotency.NewStore(cfg.Idempotency, db, redisClient); err != nil {
+			log.Fatalf("Failed to initialize idempotency store: %v", err)
+		}
+	}
+
+	// 领域事件：进程内总线，outbox中继投递到外部sink
+	eventBus := eventbus.New(appLogger)
+	lc.OnShutdown(lifecycle.PhaseWorkers, "event_bus", eventBus.Shutdown)
+	eventSink, err := eventbus.NewSink(cfg.Events, appLogger)
+	if err != nil {
+		log.Fatalf("Failed to initialize event sink: %v", err)
+	}
+	var sinks []eventbus.Sink
+	if eventSink != nil {
+		sinks = append(sinks, eventSink)
+	}
+
+	// Webhook：中继将事件展开为各订阅的投递记录，由worker签名投递
+	if cfg.Webhooks.Enabled {
+		webhookWorker := webhook.NewWorker(repos.Webhook, webhook.WorkerOptions{
+			Timeout:        cfg.Webhooks.Timeout,
+			PollInterval:   cfg.Webhooks.PollInterval,
+			BatchSize:      cfg.Webhooks.BatchSize,
+			MaxAttempts:    cfg.Webhooks.MaxAttempts,
+			InitialBackoff: cfg.Webhooks.InitialBackoff,
+			MaxBackoff:     cfg.Webhooks.MaxBackoff,
+		}, appLogger)
+		lc.OnShutdown(lifecycle.PhaseWorkers, "webhook_worker", webhookWorker.Shutdown)
+		sinks = append(sinks, webhook.NewDispatcher(repos.Webhook, webhookWorker.Notify))
+	}
+
+	if len(sinks) > 0 {
+		relay := event.NewRelay(repos.Outbox, eventbus.MultiSink(sinks...), event.RelayOptions{
+			Interval:    cfg.Events.RelayInterval,
+			BatchSize:   cfg.Events.BatchSize,
+			MaxAttempts: cfg.Events.MaxAttempts,
+			Lease:       cfg.Events.ClaimLease,
+		}, appLogger)
+		lc.OnShutdown(lifecycle.PhaseWorkers, "outbox_relay", relay.Shutdown)
+	}
 
 	// 初始化服务层
-	userService := service.NewUserService(userRepo, appLogger)
+	services := service.New(repos, eventBus, appLogger)
+
+	// 后台任务工作池
+	jobQueue := jobs.NewQueue(db, repository.Conn)
+	if cfg.Jobs.Enabled {
+		jobWorker := jobs.NewWorker(jobQueue, jobs.Options{
+			Concurrency:    cfg.Jobs.Concurrency,
+			PollInterval:   cfg.Jobs.PollInterval,
+			Timeout:        cfg.Jobs.Timeout,
+			InitialBackoff: cfg.Jobs.InitialBackoff,
+			MaxBackoff:     cfg.Jobs.MaxBackoff,
+		}, appLogger)
+		worker.Register(jobWorker, services.User, cfg.Jobs, appLogger)
+		jobWorker.Start()
+		// 停止领取新任务，等待执行中的任务完成
+		lc.OnShutdown(lifecycle.PhaseWorkers, "jobs", jobWorker.Shutdown)
+	}
+
+	// 定时任务调度器，多副本下只有租约持有者触发任务
+	var sched *scheduler.Scheduler
+	if cfg.Scheduler.Enabled {
+		location := time.Local
+		if cfg.Scheduler.Timezone != "" {
+			if location, err = time.LoadLocation(cfg.Scheduler.Timezone); err != nil {
+				log.Fatalf("Invalid scheduler timezone: %v", err)
+			}
+		}
+		sched = scheduler.New(db, scheduler.Options{
+			LeaseTTL:         cfg.Scheduler.LeaseTTL,
+			Timeout:          cfg.Scheduler.Timeout,
+			Location:         location,
+			HistoryRetention: cfg.Scheduler.HistoryRetention,
+		}, appLogger)
+		if err := worker.Schedule(sched, jobQueue, cfg.Scheduler); err != nil {
+			log.Fatalf("Failed to register scheduled tasks: %v", err)
+		}
+		sched.Start()
+		lc.OnShutdown(lifecycle.PhaseWorkers, "scheduler", sched.Shutdown)
+	}
 
 	// 初始化处理器
-	healthHandler := handler.NewHealthHandler(appLogger)
-	userHandler := handler.NewUserHandler(userService, appLogger)
+	handlers := handler.New(services, appLogger)
+	if sched != nil {
+		handlers.Scheduler = handler.NewSchedulerHandler(sched, appLogger)
+	}
+	handlers.EnableSwagger = cfg.Swagger.Enabled
+	handlers.EnableDebugVars = cfg.Server.DebugVars
+	handlers.App = cfg.App
+	handlers.ResponseCache = responseCache
+	if handlers.APIKeys, err = handler.APIKeys(cfg.Auth); err != nil {
+		log.Fatalf("Invalid auth config: %v", err)
+	}
+	// 携带Idempotency-Key的POST请求重试时重放首次的响应
+	handlers.Idempotency = middleware.IdempotencyOptions{
+		Store:        idempotencyStore,
+		TTL:          cfg.Idempotency.TTL,
+		LockTTL:      cfg.Idempotency.LockTTL,
+		MaxBodyBytes: int64(cfg.Idempotency.MaxBodySize) << 20,
+	}
+	if handlers.Versions, err = handler.VersionOptions(cfg.API); err != nil {
+		log.Fatalf("Invalid API version config: %v", err)
+	}
+	// 错误响应格式：统一响应结构或 RFC 7807 问题详情
+	if err := response.SetErrorOptions(response.ErrorOptions{
+		Format:      response.ErrorFormat(cfg.Errors.Format),
+		TypeBaseURL: cfg.Errors.TypeBaseURL,
+	}); err != nil {
+		log.Fatalf("Invalid errors config: %v", err)
+	}
+	handlers.Health.AddReadinessCheck("database", dbMonitor.Check)
+	lc.OnShutdown(lifecycle.PhaseReadiness, "readiness", func(context.Context) error {
+		handlers.Health.SetShuttingDown()
+		return nil
+	})
+	if redisClient != nil {
+		handlers.Health.AddReadinessCheck("redis", func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		})
+	}
 
 	// 初始化路由
 	r := router.New()
 
 	// 注册中间件
+	r.Use(middleware.RequestID())
+	r.Use(middleware.Tracing())
 	r.Use(middleware.Logger(appLogger))
 	r.Use(middleware.Recovery(appLogger))
 	r.Use(middleware.CORS())
 
-	// 注册路由
-	v1 := r.Group("/api/v1")
-	{
-		// 健康检查
-		health := v1.Group("/health")
-		{
-			health.GET("/check", healthHandler.Check)
-			health.GET("/ready", healthHandler.Ready)
-			health.GET("/live", healthHandler.Live)
+	// 按接口文档校验请求，开发环境下同时校验响应
+	validateResponses := cfg.OpenAPI.ValidateResponses && cfg.App.Environment == "development"
+	if cfg.OpenAPI.ValidateRequests || validateResponses {
+		spec, err := handler.OpenAPISpec()
+		if err != nil {
+			log.Fatalf("Failed to load API docs: %v", err)
 		}
-
-		// 用户相关接口
-		users := v1.Group("/users")
-		{
-			users.POST("/", userHandler.Create)
-			users.GET("/:id", userHandler.GetByID)
-			users.PUT("/:id", userHandler.Update)
-			users.DELETE("/:id", userHandler.Delete)
-			users.GET("/", userHandler.List)
+		validator, err := middleware.OpenAPIValidator(spec, middleware.OpenAPIOptions{
+			ValidateRequests:  cfg.OpenAPI.ValidateRequests,
+			ValidateResponses: validateResponses,
+			StreamingRoutes:   handler.StreamingRoutes,
+		}, appLogger)
+		if err != nil {
+			log.Fatalf("Failed to initialize API validation: %v", err)
 		}
-
-		// 认证相关接口
-		v1.POST("/login", userHandler.Login)
-
-		// 示例API
-		v1.GET("/ping", PingHandler)
-		v1.GET("/version", VersionHandler(cfg))
+		r.Use(validator)
 	}
 
-	// 创建HTTP服务器
+	// 注册路由，与 internal/app 共用同一份路由表
+	handlers.RegisterRoutes(r)
+
+	// 创建HTTP服务器，未带版本的接口请求按Accept头选择版本
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
-		Handler:      r,
+		Handler:      apiversion.Negotiate(r, handlers.Versions),
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
@@ -110,39 +300,21 @@ func main() {
 		}
 	}()
 
-	// 等待中断信号
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-
-	log.Println("Shutting down server...")
+	// 停止接收新连接并等待处理中的请求完成
+	lc.OnShutdown(lifecycle.PhaseServer, "http_server", srv.Shutdown)
 
-	// 优雅关闭
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	// 等待中断信号
+	sig := lc.Wait()
+	log.Printf("Received %s, shutting down server...", sig)
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+	// 优雅关闭，日志在所有关闭钩子之后才关闭，保证关闭过程的日志不丢失
+	err = lc.Shutdown()
+	if closeErr := logger.Close(appLogger); closeErr != nil {
+		log.Printf("Failed to close logger: %v", closeErr)
+	}
+	if err != nil {
+		log.Fatalf("Server shutdown incomplete: %v", err)
 	}
 
 	log.Println("Server exited")
 }
-
-// PingHandler Ping测试接口
-func PingHandler(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"message": "pong",
-		"time":    time.Now().Format(time.RFC3339),
-	})
-}
-
-// VersionHandler 获取版本信息
-func VersionHandler(cfg *config.Config) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"name":    cfg.App.Name,
-			"version": cfg.App.Version,
-			"env":     cfg.App.Environment,
-		})
-	}
-}
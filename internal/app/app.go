@@ -4,19 +4,30 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"go-api-scaffold/internal/config"
+	"go-api-scaffold/internal/event"
 	"go-api-scaffold/internal/handler"
 	"go-api-scaffold/internal/middleware"
 	"go-api-scaffold/internal/repository"
 	"go-api-scaffold/internal/service"
+	"go-api-scaffold/internal/webhook"
+	"go-api-scaffold/internal/worker"
+	"go-api-scaffold/pkg/apiversion"
+	"go-api-scaffold/pkg/cache"
 	"go-api-scaffold/pkg/database"
+	"go-api-scaffold/pkg/eventbus"
+	"go-api-scaffold/pkg/idempotency"
+	"go-api-scaffold/pkg/jobs"
+	"go-api-scaffold/pkg/lifecycle"
 	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/response"
+	"go-api-scaffold/pkg/scheduler"
+	"go-api-scaffold/pkg/tracing"
 )
 
 // App 应用结构体
@@ -36,22 +47,186 @@ func New(cfg *config.Config) *App {
 // Run 启动应用
 func (a *App) Run() error {
 	// 初始化日志
-	a.logger = logger.New(a.config.Log.Level)
+	a.logger = logger.New(a.config.Log)
+
+	// 生命周期管理，按阶段顺序执行关闭钩子
+	lc := lifecycle.New(lifecycle.Options{
+		Timeout:      a.config.Server.ShutdownTimeout,
+		PreStopDelay: a.config.Server.PreStopDelay,
+	}, a.logger)
+
+	// 分布式追踪，未启用时使用no-op实现
+	tracerProvider, err := tracing.New(a.config.Tracing, a.config.App)
+	if err != nil {
+		return fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+	lc.OnShutdown(lifecycle.PhaseFlush, "tracing", tracerProvider.Shutdown)
 
 	// 初始化数据库
-	db, err := database.New(a.config.Database)
+	db, err := database.New(a.config.Database, a.logger)
 	if err != nil {
 		return fmt.Errorf("failed to connect database: %w", err)
 	}
+	lc.OnShutdown(lifecycle.PhaseResources, "database", func(context.Context) error {
+		return database.Close(db)
+	})
+	if tracerProvider != nil {
+		if err := db.Use(tracing.NewGormPlugin()); err != nil {
+			return fmt.Errorf("failed to register database tracing: %w", err)
+		}
+	}
+
+	// 运行期连接探测，断线时由就绪检查反映而不是退出进程
+	dbMonitor := database.NewMonitor(db, a.logger, a.config.Database.HealthCheckPeriod)
+	lc.OnShutdown(lifecycle.PhaseResources, "database_monitor", func(context.Context) error {
+		dbMonitor.Stop()
+		return nil
+	})
+
+	// 初始化Redis（仅在缓存或幂等键使用redis存储时）
+	var redisClient *redis.Client
+	if strings.EqualFold(a.config.Cache.Store, "redis") ||
+		a.config.Idempotency.Enabled && strings.EqualFold(a.config.Idempotency.Store, "redis") {
+		redisClient = cache.NewRedisClient(a.config.Redis)
+		lc.OnShutdown(lifecycle.PhaseResources, "redis", func(context.Context) error {
+			return redisClient.Close()
+		})
+	}
 
 	// 初始化仓储层
 	repos := repository.New(db)
+	if err := repos.EnableCache(a.config.Cache, redisClient, a.logger); err != nil {
+		return fmt.Errorf("failed to initialize cache: %w", err)
+	}
+
+	// 公共接口共享响应缓存
+	var responseCache cache.Store
+	if a.config.Cache.Resource("http").Enabled {
+		if responseCache, err = cache.NewStore(a.config.Cache, redisClient, "http"); err != nil {
+			return fmt.Errorf("failed to initialize response cache: %w", err)
+		}
+	}
+
+	// 领域事件：进程内总线，outbox中继投递到外部sink
+	eventBus := eventbus.New(a.logger)
+	lc.OnShutdown(lifecycle.PhaseWorkers, "event_bus", eventBus.Shutdown)
+	eventSink, err := eventbus.NewSink(a.config.Events, a.logger)
+	if err != nil {
+		return fmt.Errorf("failed to initialize event sink: %w", err)
+	}
+	var sinks []eventbus.Sink
+	if eventSink != nil {
+		sinks = append(sinks, eventSink)
+	}
+
+	// Webhook：中继将事件展开为各订阅的投递记录，由worker签名投递
+	if a.config.Webhooks.Enabled {
+		webhookWorker := webhook.NewWorker(repos.Webhook, webhook.WorkerOptions{
+			Timeout:        a.config.Webhooks.Timeout,
+			PollInterval:   a.config.Webhooks.PollInterval,
+			BatchSize:      a.config.Webhooks.BatchSize,
+			MaxAttempts:    a.config.Webhooks.MaxAttempts,
+			InitialBackoff: a.config.Webhooks.InitialBackoff,
+			MaxBackoff:     a.config.Webhooks.MaxBackoff,
+		}, a.logger)
+		lc.OnShutdown(lifecycle.PhaseWorkers, "webhook_worker", webhookWorker.Shutdown)
+		sinks = append(sinks, webhook.NewDispatcher(repos.Webhook, webhookWorker.Notify))
+	}
+
+	if len(sinks) > 0 {
+		relay := event.NewRelay(repos.Outbox, eventbus.MultiSink(sinks...), event.RelayOptions{
+			Interval:    a.config.Events.RelayInterval,
+			BatchSize:   a.config.Events.BatchSize,
+			MaxAttempts: a.config.Events.MaxAttempts,
+			Lease:       a.config.Events.ClaimLease,
+		}, a.logger)
+		lc.OnShutdown(lifecycle.PhaseWorkers, "outbox_relay", relay.Shutdown)
+	}
 
 	// 初始化服务层
-	services := service.New(repos, a.logger)
+	services := service.New(repos, eventBus, a.logger)
+
+	// 后台任务工作池
+	jobQueue := jobs.NewQueue(db, repository.Conn)
+	if a.config.Jobs.Enabled {
+		jobWorker := jobs.NewWorker(jobQueue, jobs.Options{
+			Concurrency:    a.config.Jobs.Concurrency,
+			PollInterval:   a.config.Jobs.PollInterval,
+			Timeout:        a.config.Jobs.Timeout,
+			InitialBackoff: a.config.Jobs.InitialBackoff,
+			MaxBackoff:     a.config.Jobs.MaxBackoff,
+		}, a.logger)
+		worker.Register(jobWorker, services.User, a.config.Jobs, a.logger)
+		jobWorker.Start()
+		// 停止领取新任务，等待执行中的任务完成
+		lc.OnShutdown(lifecycle.PhaseWorkers, "jobs", jobWorker.Shutdown)
+	}
+
+	// 定时任务调度器，多副本下只有租约持有者触发任务
+	var sched *scheduler.Scheduler
+	if a.config.Scheduler.Enabled {
+		location := time.Local
+		if a.config.Scheduler.Timezone != "" {
+			if location, err = time.LoadLocation(a.config.Scheduler.Timezone); err != nil {
+				return fmt.Errorf("invalid scheduler timezone: %w", err)
+			}
+		}
+		sched = scheduler.New(db, scheduler.Options{
+			LeaseTTL:         a.config.Scheduler.LeaseTTL,
+			Timeout:          a.config.Scheduler.Timeout,
+			Location:         location,
+			HistoryRetention: a.config.Scheduler.HistoryRetention,
+		}, a.logger)
+		if err := worker.Schedule(sched, jobQueue, a.config.Scheduler); err != nil {
+			return fmt.Errorf("failed to register scheduled tasks: %w", err)
+		}
+		sched.Start()
+		lc.OnShutdown(lifecycle.PhaseWorkers, "scheduler", sched.Shutdown)
+	}
 
 	// 初始化处理器
 	handlers := handler.New(services, a.logger)
+	if sched != nil {
+		handlers.Scheduler = handler.NewSchedulerHandler(sched, a.logger)
+	}
+	handlers.EnableSwagger = a.config.Swagger.Enabled
+	handlers.EnableDebugVars = a.config.Server.DebugVars
+	handlers.App = a.config.App
+	handlers.ResponseCache = responseCache
+	if handlers.APIKeys, err = handler.APIKeys(a.config.Auth); err != nil {
+		return fmt.Errorf("invalid auth config: %w", err)
+	}
+	if handlers.Versions, err = handler.VersionOptions(a.config.API); err != nil {
+		return fmt.Errorf("invalid api version config: %w", err)
+	}
+	if err := response.SetErrorOptions(response.ErrorOptions{
+		Format:      response.ErrorFormat(a.config.Errors.Format),
+		TypeBaseURL: a.config.Errors.TypeBaseURL,
+	}); err != nil {
+		return fmt.Errorf("invalid errors config: %w", err)
+	}
+	if a.config.Idempotency.Enabled {
+		store, err := idempotency.NewStore(a.config.Idempotency, db, redisClient)
+		if err != nil {
+			return fmt.Errorf("failed to initialize idempotency store: %w", err)
+		}
+		handlers.Idempotency = middleware.IdempotencyOptions{
+			Store:        store,
+			TTL:          a.config.Idempotency.TTL,
+			LockTTL:      a.config.Idempotency.LockTTL,
+			MaxBodyBytes: int64(a.config.Idempotency.MaxBodySize) << 20,
+		}
+	}
+	handlers.Health.AddReadinessCheck("database", dbMonitor.Check)
+	lc.OnShutdown(lifecycle.PhaseReadiness, "readiness", func(context.Context) error {
+		handlers.Health.SetShuttingDown()
+		return nil
+	})
+	if redisClient != nil {
+		handlers.Health.AddReadinessCheck("redis", func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		})
+	}
 
 	// 设置Gin模式
 	if a.config.App.Environment == "production" {
@@ -62,42 +237,61 @@ func (a *App) Run() error {
 	router := gin.New()
 
 	// 注册中间件
+	router.Use(middleware.RequestID())
+	router.Use(middleware.Tracing())
 	router.Use(middleware.Logger(a.logger))
 	router.Use(middleware.Recovery(a.logger))
 	router.Use(middleware.CORS())
 
+	// 按接口文档校验请求，开发环境下同时校验响应
+	validateResponses := a.config.OpenAPI.ValidateResponses && a.config.App.Environment == "development"
+	if a.config.OpenAPI.ValidateRequests || validateResponses {
+		spec, err := handler.OpenAPISpec()
+		if err != nil {
+			return fmt.Errorf("failed to load api docs: %w", err)
+		}
+		validator, err := middleware.OpenAPIValidator(spec, middleware.OpenAPIOptions{
+			ValidateRequests:  a.config.OpenAPI.ValidateRequests,
+			ValidateResponses: validateResponses,
+			StreamingRoutes:   handler.StreamingRoutes,
+		}, a.logger)
+		if err != nil {
+			return fmt.Errorf("failed to initialize api validation: %w", err)
+		}
+		router.Use(validator)
+	}
+
 	// 注册路由
 	handlers.RegisterRoutes(router)
 
-	// 创建HTTP服务器
+	// 创建HTTP服务器，未带版本的接口请求按Accept头选择版本
 	a.server = &http.Server{
 		Addr:    fmt.Sprintf(":%d", a.config.Server.Port),
-		Handler: router,
+		Handler: apiversion.Negotiate(router, handlers.Versions),
 	}
 
 	// 启动服务器
 	go func() {
-		a.logger.Info(fmt.Sprintf("Server starting on port %d", a.config.Server.Port))
+		a.logger.Infof("Server starting on port %d", a.config.Server.Port)
 		if err := a.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			a.logger.Error(fmt.Sprintf("Server failed to start: %v", err))
+			a.logger.WithError(err).Error("Server failed to start")
 		}
 	}()
 
-	// 等待中断信号
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	// 停止接收新连接并等待处理中的请求完成
+	lc.OnShutdown(lifecycle.PhaseServer, "http_server", a.server.Shutdown)
 
-	a.logger.Info("Server shutting down...")
-
-	// 优雅关闭
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	// 等待中断信号
+	sig := lc.Wait()
+	a.logger.Infof("Received %s, server shutting down...", sig)
 
-	if err := a.server.Shutdown(ctx); err != nil {
-		return fmt.Errorf("server forced to shutdown: %w", err)
+	// 优雅关闭，日志在所有关闭钩子之后才关闭，保证关闭过程的日志不丢失
+	err = lc.Shutdown()
+	if closeErr := logger.Close(a.logger); closeErr != nil && err == nil {
+		return fmt.Errorf("failed to close logger: %w", closeErr)
+	}
+	if err != nil {
+		return fmt.Errorf("server shutdown incomplete: %w", err)
 	}
-
-	a.logger.Info("Server exited")
 	return nil
 }
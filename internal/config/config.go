@@ -1,19 +1,34 @@
 package config
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
 )
 
 // Config 应用配置结构
 type Config struct {
-	App      AppConfig      `mapstructure:"app"`
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Log      LogConfig      `mapstructure:"log"`
+	App         AppConfig         `mapstructure:"app"`
+	Server      ServerConfig      `mapstructure:"server"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	Log         LogConfig         `mapstructure:"log"`
+	Redis       RedisConfig       `mapstructure:"redis"`
+	Cache       CacheConfig       `mapstructure:"cache"`
+	Events      EventsConfig      `mapstructure:"events"`
+	Webhooks    WebhooksConfig    `mapstructure:"webhooks"`
+	Jobs        JobsConfig        `mapstructure:"jobs"`
+	Scheduler   SchedulerConfig   `mapstructure:"scheduler"`
+	Tracing     TracingConfig     `mapstructure:"tracing"`
+	Swagger     SwaggerConfig     `mapstructure:"swagger"`
+	OpenAPI     OpenAPIConfig     `mapstructure:"openapi"`
+	API         APIConfig         `mapstructure:"api"`
+	Errors      ErrorsConfig      `mapstructure:"errors"`
+	Idempotency IdempotencyConfig `mapstructure:"idempotency"`
+	Auth        AuthConfig        `mapstructure:"auth"`
 }
 
 // AppConfig 应用配置
@@ -29,6 +44,13 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
 	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
+
+	// 优雅关闭：就绪检查置为失败后等待PreStopDelay，再在ShutdownTimeout内依次执行关闭钩子
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+	PreStopDelay    time.Duration `mapstructure:"pre_stop_delay"`
+
+	// DebugVars 为true时注册 /debug/vars（expvar），其中包含启动参数和内部计数器，仅在内网或调试时开启
+	DebugVars bool `mapstructure:"debug_vars"`
 }
 
 // DatabaseConfig 数据库配置
@@ -37,18 +59,234 @@ type DatabaseConfig struct {
 	Host            string `mapstructure:"host"`
 	Port            int    `mapstructure:"port"`
 	Username        string `mapstructure:"username"`
-	Password        string `mapstructure:"password"`
+	Password        Secret `mapstructure:"password"`
 	Database        string `mapstructure:"database"`
 	MaxOpenConns    int    `mapstructure:"max_open_conns"`
 	MaxIdleConns    int    `mapstructure:"max_idle_conns"`
 	ConnMaxLifetime int    `mapstructure:"conn_max_lifetime"`
+
+	// SQL日志配置，LogLevel为空时沿用log.level；SQL以info、慢查询以warn、错误以error级别写入应用日志，同样受log.level过滤
+	LogLevel      string        `mapstructure:"log_level"`
+	SlowThreshold time.Duration `mapstructure:"slow_threshold"`
+	RedactColumns []string      `mapstructure:"redact_columns"`
+
+	// 只读副本配置，查询自动路由到健康副本
+	Replicas           []ReplicaConfig `mapstructure:"replicas"`
+	ReplicaCheckPeriod time.Duration   `mapstructure:"replica_check_period"`
+	ReplicaMaxFailures int             `mapstructure:"replica_max_failures"`
+
+	// 启动连接重试及运行期连接探测
+	Retry             DatabaseRetryConfig `mapstructure:"retry"`
+	HealthCheckPeriod time.Duration       `mapstructure:"health_check_period"`
+}
+
+// DatabaseRetryConfig 数据库启动连接重试配置（指数退避+抖动）
+type DatabaseRetryConfig struct {
+	InitialInterval time.Duration `mapstructure:"initial_interval"`
+	MaxInterval     time.Duration `mapstructure:"max_interval"`
+	MaxWait         time.Duration `mapstructure:"max_wait"` // 总等待上限，为0时只尝试一次
+}
+
+// ReplicaConfig 只读副本配置，未填写的字段沿用主库配置
+type ReplicaConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password Secret `mapstructure:"password"`
+	Database string `mapstructure:"database"`
+}
+
+// RedisConfig Redis配置
+type RedisConfig struct {
+	Addr     string `mapstructure:"addr"`
+	Password Secret `mapstructure:"password"`
+	DB       int    `mapstructure:"db"`
+}
+
+// CacheConfig 缓存配置
+type CacheConfig struct {
+	Store     string                         `mapstructure:"store"` // memory 或 redis
+	KeyPrefix string                         `mapstructure:"key_prefix"`
+	Resources map[string]CacheResourceConfig `mapstructure:"resources"`
+}
+
+// CacheResourceConfig 单个资源的缓存配置
+type CacheResourceConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	TTL         time.Duration `mapstructure:"ttl"`
+	NegativeTTL time.Duration `mapstructure:"negative_ttl"` // 不存在结果的缓存时间，为0时不缓存
+	MaxEntries  int           `mapstructure:"max_entries"`  // 仅memory存储生效
+}
+
+// Resource 获取指定资源的缓存配置，未配置时返回禁用状态
+func (c CacheConfig) Resource(name string) CacheResourceConfig {
+	return c.Resources[name]
+}
+
+// EventsConfig 领域事件配置，事件先写入outbox表，由中继投递到sink
+type EventsConfig struct {
+	Sink           string        `mapstructure:"sink"` // log、webhook、memory 或 none
+	WebhookURL     string        `mapstructure:"webhook_url"`
+	WebhookTimeout time.Duration `mapstructure:"webhook_timeout"`
+	RelayInterval  time.Duration `mapstructure:"relay_interval"` // outbox轮询周期
+	BatchSize      int           `mapstructure:"batch_size"`     // 每次轮询最多投递条数
+	MaxAttempts    int           `mapstructure:"max_attempts"`   // 达到后标记为失败不再重试
+	ClaimLease     time.Duration `mapstructure:"claim_lease"`    // 领取后的投递租约，到期未完成的消息会被重新领取
+}
+
+// WebhooksConfig 外发Webhook配置
+type WebhooksConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	Timeout        time.Duration `mapstructure:"timeout"`         // 单次请求超时
+	PollInterval   time.Duration `mapstructure:"poll_interval"`   // 投递记录轮询周期
+	BatchSize      int           `mapstructure:"batch_size"`      // 每次轮询最多投递条数
+	MaxAttempts    int           `mapstructure:"max_attempts"`    // 达到后进入死信状态
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"` // 首次重试间隔，之后指数增长
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`
+}
+
+// JobsConfig 后台任务配置
+type JobsConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	Concurrency    int           `mapstructure:"concurrency"`     // 同时执行的任务数上限
+	PollInterval   time.Duration `mapstructure:"poll_interval"`   // 任务表轮询周期
+	Timeout        time.Duration `mapstructure:"timeout"`         // 单个任务执行超时
+	InitialBackoff time.Duration `mapstructure:"initial_backoff"` // 首次重试间隔，之后指数增长
+	MaxBackoff     time.Duration `mapstructure:"max_backoff"`
+
+	// 软删除用户保留时长，超过后由清理任务物理删除
+	PurgeDeletedUsersAfter time.Duration `mapstructure:"purge_deleted_users_after"`
+}
+
+// SchedulerConfig 定时任务调度配置
+type SchedulerConfig struct {
+	Enabled          bool                  `mapstructure:"enabled"`
+	Timezone         string                `mapstructure:"timezone"`          // cron表达式时区，为空时使用本地时区
+	LeaseTTL         time.Duration         `mapstructure:"lease_ttl"`         // 领导者租约时长
+	Timeout          time.Duration         `mapstructure:"timeout"`           // 单次执行超时
+	HistoryRetention time.Duration         `mapstructure:"history_retention"` // 执行记录保留时长
+	Tasks            []SchedulerTaskConfig `mapstructure:"tasks"`             // 覆盖内置任务的cron表达式
+}
+
+// SchedulerTaskConfig 单个定时任务配置
+// 任务名包含"."，viper会将map键按"."拆分，因此使用列表而不是map
+type SchedulerTaskConfig struct {
+	Name string `mapstructure:"name"`
+	Spec string `mapstructure:"spec"` // "off"表示禁用
+}
+
+// TaskSpec 获取任务的cron表达式，未配置时返回默认值，禁用时返回空
+func (c SchedulerConfig) TaskSpec(name, fallback string) string {
+	for _, task := range c.Tasks {
+		if task.Name != name || task.Spec == "" {
+			continue
+		}
+		if strings.EqualFold(task.Spec, "off") {
+			return ""
+		}
+		return task.Spec
+	}
+	return fallback
+}
+
+// TracingConfig 分布式追踪配置
+type TracingConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Exporter    string        `mapstructure:"exporter"`     // otlp、stdout、memory 或 none
+	Endpoint    string        `mapstructure:"endpoint"`     // OTLP/HTTP地址，如 http://localhost:4318，为空时读取OTEL_EXPORTER_OTLP_ENDPOINT
+	Timeout     time.Duration `mapstructure:"timeout"`      // 单次导出超时
+	SampleRatio float64       `mapstructure:"sample_ratio"` // 根span采样比例，上游已采样的请求始终跟随
+}
+
+// SwaggerConfig 接口文档配置
+type SwaggerConfig struct {
+	Enabled bool `mapstructure:"enabled"` // 是否提供 /swagger/* 文档页面，生产环境建议关闭
+}
+
+// OpenAPIConfig 基于接口文档的运行时校验配置
+type OpenAPIConfig struct {
+	ValidateRequests  bool `mapstructure:"validate_requests"`  // 请求不符合文档时返回400
+	ValidateResponses bool `mapstructure:"validate_responses"` // 仅开发环境生效，响应不符合文档时记录日志
+}
+
+// APIConfig 接口版本配置，支持哪些版本由代码决定，这里只配置默认版本与弃用计划
+type APIConfig struct {
+	DefaultVersion int                `mapstructure:"default_version"` // 未在路径或Accept头中指定版本时使用
+	MediaType      string             `mapstructure:"media_type"`      // Accept头中携带version参数的媒体类型
+	Versions       []APIVersionConfig `mapstructure:"versions"`
+}
+
+// APIVersionConfig 单个版本的弃用计划，时间使用RFC 3339格式
+type APIVersionConfig struct {
+	Version    int       `mapstructure:"version"`
+	Deprecated time.Time `mapstructure:"deprecated"` // 弃用时间，响应输出Deprecation头
+	Sunset     time.Time `mapstructure:"sunset"`     // 下线时间，响应输出Sunset头
+	Link       string    `mapstructure:"link"`       // 迁移说明文档
+}
+
+// Version 获取指定版本的弃用计划，未配置时返回零值
+func (c APIConfig) Version(version int) APIVersionConfig {
+	for _, v := range c.Versions {
+		if v.Version == version {
+			return v
+		}
+	}
+	return APIVersionConfig{Version: version}
+}
+
+// ErrorsConfig 错误响应配置
+type ErrorsConfig struct {
+	Format      string `mapstructure:"format"`        // envelope 使用统一响应结构，problem 使用 RFC 7807 问题详情
+	TypeBaseURL string `mapstructure:"type_base_url"` // 问题详情type的URI前缀，为空时使用about:blank
+}
+
+// IdempotencyConfig 幂等键配置，POST请求携带Idempotency-Key时保存响应，重试时重放
+type IdempotencyConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Store     string        `mapstructure:"store"`      // memory、database 或 redis
+	TTL       time.Duration `mapstructure:"ttl"`        // 响应保存时长，期间同一键的重试直接返回保存的响应
+	LockTTL   time.Duration `mapstructure:"lock_ttl"`   // 处理中锁的最长时间，超过后视为处理失败，允许重试
+	KeyPrefix string        `mapstructure:"key_prefix"` // 仅redis存储生效
+	// MaxBodySize 携带幂等键的请求体最大尺寸(MB)，请求体需完整读入内存计算指纹
+	MaxBodySize int `mapstructure:"max_body_size"`
+}
+
+// AuthConfig 认证配置
+type AuthConfig struct {
+	// APIKeys 可用的API密钥，请求通过 Authorization: Bearer <key> 认证；未配置时需要认证的接口始终返回401
+	APIKeys []APIKeyConfig `mapstructure:"api_keys"`
+}
+
+// APIKeyConfig API密钥配置，Key支持 env:NAME、file:///path 等密钥引用
+type APIKeyConfig struct {
+	Actor string `mapstructure:"actor"` // 操作者标识，写入审计日志的actor_id
+	Key   Secret `mapstructure:"key"`
 }
 
 // LogConfig 日志配置
 type LogConfig struct {
-	Level  string `mapstructure:"level"`
-	Format string `mapstructure:"format"`
-	Output string `mapstructure:"output"`
+	Level    string            `mapstructure:"level"`
+	Format   string            `mapstructure:"format"`
+	Output   string            `mapstructure:"output"`
+	File     LogFileConfig     `mapstructure:"file"`
+	Sampling LogSamplingConfig `mapstructure:"sampling"`
+}
+
+// LogFileConfig 日志文件配置（output为file时生效）
+type LogFileConfig struct {
+	Path       string `mapstructure:"path"`
+	MaxSize    int    `mapstructure:"max_size"`    // 单个文件最大尺寸(MB)
+	MaxAge     int    `mapstructure:"max_age"`     // 旧文件保留天数
+	MaxBackups int    `mapstructure:"max_backups"` // 旧文件保留个数
+	Compress   bool   `mapstructure:"compress"`    // 是否gzip压缩旧文件
+}
+
+// LogSamplingConfig Info日志采样配置
+type LogSamplingConfig struct {
+	Enabled    bool          `mapstructure:"enabled"`
+	Initial    int           `mapstructure:"initial"`    // 每个周期内同一消息前N条全部输出
+	Thereafter int           `mapstructure:"thereafter"` // 超出后每M条输出1条
+	Tick       time.Duration `mapstructure:"tick"`       // 采样周期
 }
 
 // Load 加载配置
@@ -76,10 +314,25 @@ func Load() (*Config, error) {
 
 	// 解析配置
 	var cfg Config
-	if err := viper.Unmarshal(&cfg); err != nil {
+	// 在默认的时长、列表转换之外支持RFC 3339时间
+	decodeHook := viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		mapstructure.StringToTimeHookFunc(time.RFC3339),
+	))
+	if err := viper.Unmarshal(&cfg, decodeHook); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
 
+	if cfg.Database.LogLevel == "" {
+		cfg.Database.LogLevel = cfg.Log.Level
+	}
+
+	// 解析密钥引用（env:NAME、file:///path 等）
+	if err := resolveSecrets(context.Background(), &cfg); err != nil {
+		return nil, fmt.Errorf("failed to resolve secrets: %w", err)
+	}
+
 	return &cfg, nil
 }
 
@@ -95,6 +348,9 @@ func setDefaults() {
 	viper.SetDefault("server.read_timeout", "30s")
 	viper.SetDefault("server.write_timeout", "30s")
 	viper.SetDefault("server.idle_timeout", "60s")
+	viper.SetDefault("server.shutdown_timeout", "30s")
+	viper.SetDefault("server.pre_stop_delay", "0s")
+	viper.SetDefault("server.debug_vars", false)
 
 	// 数据库默认配置
 	viper.SetDefault("database.driver", "mysql")
@@ -106,10 +362,95 @@ func setDefaults() {
 	viper.SetDefault("database.max_open_conns", 100)
 	viper.SetDefault("database.max_idle_conns", 10)
 	viper.SetDefault("database.conn_max_lifetime", 3600)
+	viper.SetDefault("database.slow_threshold", "200ms")
+	viper.SetDefault("database.redact_columns", []string{"password", "secret", "token"})
+	viper.SetDefault("database.replica_check_period", "10s")
+	viper.SetDefault("database.replica_max_failures", 3)
+	viper.SetDefault("database.retry.initial_interval", "1s")
+	viper.SetDefault("database.retry.max_interval", "15s")
+	viper.SetDefault("database.retry.max_wait", "2m")
+	viper.SetDefault("database.health_check_period", "10s")
+
+	// Redis默认配置
+	viper.SetDefault("redis.addr", "127.0.0.1:6379")
+	viper.SetDefault("redis.db", 0)
+
+	// 缓存默认配置
+	viper.SetDefault("cache.store", "memory")
+	viper.SetDefault("cache.key_prefix", "go-api-scaffold:")
+
+	// 领域事件默认配置
+	viper.SetDefault("events.sink", "log")
+	viper.SetDefault("events.webhook_timeout", "10s")
+	viper.SetDefault("events.relay_interval", "1s")
+	viper.SetDefault("events.batch_size", 100)
+	viper.SetDefault("events.max_attempts", 10)
+	viper.SetDefault("events.claim_lease", "1m")
+
+	// Webhook默认配置
+	viper.SetDefault("webhooks.enabled", true)
+	viper.SetDefault("webhooks.timeout", "10s")
+	viper.SetDefault("webhooks.poll_interval", "1s")
+	viper.SetDefault("webhooks.batch_size", 50)
+	viper.SetDefault("webhooks.max_attempts", 8)
+	viper.SetDefault("webhooks.initial_backoff", "30s")
+	viper.SetDefault("webhooks.max_backoff", "1h")
+
+	// 后台任务默认配置
+	viper.SetDefault("jobs.enabled", true)
+	viper.SetDefault("jobs.concurrency", 4)
+	viper.SetDefault("jobs.poll_interval", "1s")
+	viper.SetDefault("jobs.timeout", "5m")
+	viper.SetDefault("jobs.initial_backoff", "10s")
+	viper.SetDefault("jobs.max_backoff", "30m")
+	viper.SetDefault("jobs.purge_deleted_users_after", "720h")
+
+	// 定时任务默认配置
+	viper.SetDefault("scheduler.enabled", true)
+	viper.SetDefault("scheduler.lease_ttl", "30s")
+	viper.SetDefault("scheduler.timeout", "10m")
+	viper.SetDefault("scheduler.history_retention", "720h")
+
+	// 分布式追踪默认配置
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.exporter", "otlp")
+	viper.SetDefault("tracing.timeout", "10s")
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+
+	// 接口文档默认配置
+	viper.SetDefault("swagger.enabled", false)
+
+	// 接口文档校验默认配置
+	viper.SetDefault("openapi.validate_requests", false)
+	viper.SetDefault("openapi.validate_responses", false)
+
+	// 接口版本默认配置
+	viper.SetDefault("api.default_version", 1)
+	viper.SetDefault("api.media_type", "application/vnd.go-api-scaffold+json")
+
+	// 错误响应默认配置
+	viper.SetDefault("errors.format", "envelope")
+
+	// 幂等键默认配置
+	viper.SetDefault("idempotency.enabled", true)
+	viper.SetDefault("idempotency.store", "memory")
+	viper.SetDefault("idempotency.ttl", "24h")
+	viper.SetDefault("idempotency.lock_ttl", "30s")
+	viper.SetDefault("idempotency.key_prefix", "idempotency:")
+	viper.SetDefault("idempotency.max_body_size", 10)
 
 	// 日志默认配置
 	viper.SetDefault("log.level", "info")
 	viper.SetDefault("log.format", "json")
 	viper.SetDefault("log.output", "stdout")
+	viper.SetDefault("log.file.path", "logs/app.log")
+	viper.SetDefault("log.file.max_size", 100)
+	viper.SetDefault("log.file.max_age", 7)
+	viper.SetDefault("log.file.max_backups", 10)
+	viper.SetDefault("log.file.compress", true)
+	viper.SetDefault("log.sampling.enabled", false)
+	viper.SetDefault("log.sampling.initial", 100)
+	viper.SetDefault("log.sampling.thereafter", 100)
+	viper.SetDefault("log.sampling.tick", "1s")
 
 }
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// secretMask 密钥脱敏后的占位符
+const secretMask = "******"
+
+// Secret 敏感配置值，格式化输出和序列化时自动脱敏
+type Secret string
+
+// Value 获取明文值，仅在真正使用时调用
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// String 实现fmt.Stringer，防止密钥出现在日志中
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return secretMask
+}
+
+// GoString 实现fmt.GoStringer，覆盖%#v输出
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+// MarshalText 序列化时输出脱敏值（JSON/YAML等编码器均会使用）
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// SecretProvider 密钥提供者接口
+// key为引用中scheme之后的部分，如 "env:DB_PASSWORD" 中的 "DB_PASSWORD"
+type SecretProvider interface {
+	Resolve(ctx context.Context, key string) (string, error)
+}
+
+// SecretProviderFunc 函数适配器
+type SecretProviderFunc func(ctx context.Context, key string) (string, error)
+
+// Resolve 实现SecretProvider接口
+func (f SecretProviderFunc) Resolve(ctx context.Context, key string) (string, error) {
+	return f(ctx, key)
+}
+
+var (
+	secretProvidersMu sync.RWMutex
+	secretProviders   = map[string]SecretProvider{
+		"env":  SecretProviderFunc(resolveEnvSecret),
+		"file": SecretProviderFunc(resolveFileSecret),
+	}
+)
+
+// RegisterSecretProvider 注册密钥提供者，重复注册同一scheme会覆盖
+// 例如接入Vault时注册 "vault" scheme，配置中即可使用 "vault:secret/data/db#password"
+func RegisterSecretProvider(scheme string, provider SecretProvider) {
+	secretProvidersMu.Lock()
+	defer secretProvidersMu.Unlock()
+	secretProviders[scheme] = provider
+}
+
+// lookupSecretProvider 查找已注册的密钥提供者
+func lookupSecretProvider(scheme string) (SecretProvider, bool) {
+	secretProvidersMu.RLock()
+	defer secretProvidersMu.RUnlock()
+	p, ok := secretProviders[scheme]
+	return p, ok
+}
+
+// MapSecretProvider 基于内存的密钥提供者，适用于测试和本地开发
+type MapSecretProvider map[string]string
+
+// Resolve 实现SecretProvider接口
+func (m MapSecretProvider) Resolve(_ context.Context, key string) (string, error) {
+	v, ok := m[key]
+	if !ok {
+		return "", fmt.Errorf("secret %q not found", key)
+	}
+	return v, nil
+}
+
+// ResolveSecret 解析单个密钥引用，非引用格式的值原样返回
+func ResolveSecret(ctx context.Context, value string) (string, error) {
+	scheme, key, ok := strings.Cut(value, ":")
+	if !ok {
+		return value, nil
+	}
+
+	provider, ok := lookupSecretProvider(scheme)
+	if !ok {
+		return value, nil
+	}
+
+	resolved, err := provider.Resolve(ctx, key)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s secret: %w", scheme, err)
+	}
+	return resolved, nil
+}
+
+// resolveSecrets 递归解析配置中所有Secret类型字段
+func resolveSecrets(ctx context.Context, cfg *Config) error {
+	return resolveSecretsValue(ctx, reflect.ValueOf(cfg).Elem(), "")
+}
+
+// resolveSecretsValue 遍历结构体字段并替换密钥引用
+func resolveSecretsValue(ctx context.Context, v reflect.Value, path string) error {
+	secretType := reflect.TypeOf(Secret(""))
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if !t.Field(i).IsExported() {
+				continue
+			}
+			name := t.Field(i).Tag.Get("mapstructure")
+			if name == "" {
+				name = strings.ToLower(t.Field(i).Name)
+			}
+			if path != "" {
+				name = path + "." + name
+			}
+			if err := resolveSecretsValue(ctx, v.Field(i), name); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			if err := resolveSecretsValue(ctx, v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.String:
+		if v.Type() != secretType || v.String() == "" {
+			return nil
+		}
+		resolved, err := ResolveSecret(ctx, v.String())
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		v.SetString(resolved)
+	}
+
+	return nil
+}
+
+// resolveEnvSecret 从环境变量读取密钥
+func resolveEnvSecret(_ context.Context, key string) (string, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", key)
+	}
+	return v, nil
+}
+
+// resolveFileSecret 从文件读取密钥，支持 file:///path 和 file:/path 两种写法
+func resolveFileSecret(_ context.Context, key string) (string, error) {
+	path := strings.TrimPrefix(key, "//")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	// 去除文件末尾换行，兼容 echo 生成的密钥文件
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
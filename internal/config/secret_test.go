@@ -0,0 +1,149 @@
+package config_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/spf13/viper"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/internal/handler"
+	"go-api-scaffold/pkg/logger"
+)
+
+const (
+	envPassword  = "env-db-password"
+	filePassword = "file-redis-password"
+	fakeAPIKey   = "fake-api-key"
+)
+
+// loadWithSecrets 通过环境变量、密钥文件和注册的提供者配置密钥后加载配置
+// 测试目录下没有app.yaml，其余配置使用默认值
+func loadWithSecrets(t *testing.T) *config.Config {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	t.Setenv("TEST_DB_PASSWORD", envPassword)
+	t.Setenv("API_DATABASE_PASSWORD", "env:TEST_DB_PASSWORD")
+
+	path := filepath.Join(t.TempDir(), "redis_password")
+	if err := os.WriteFile(path, []byte(filePassword+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	viper.Set("redis.password", "file://"+path)
+
+	config.RegisterSecretProvider("fake", config.MapSecretProvider{"ops": fakeAPIKey})
+	viper.Set("auth.api_keys", []map[string]interface{}{{"actor": "ops", "key": "fake:ops"}})
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return cfg
+}
+
+func TestLoadResolvesSecrets(t *testing.T) {
+	cfg := loadWithSecrets(t)
+
+	if got := cfg.Database.Password.Value(); got != envPassword {
+		t.Errorf("database.password = %q, want %q", got, envPassword)
+	}
+	if got := cfg.Redis.Password.Value(); got != filePassword {
+		t.Errorf("redis.password = %q, want %q", got, filePassword)
+	}
+	if len(cfg.Auth.APIKeys) != 1 || cfg.Auth.APIKeys[0].Key.Value() != fakeAPIKey {
+		t.Errorf("auth.api_keys = %#v, want key resolved by fake provider", cfg.Auth.APIKeys)
+	}
+}
+
+func TestLoadMissingSecret(t *testing.T) {
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	t.Setenv("API_DATABASE_PASSWORD", "env:TEST_DB_PASSWORD_UNSET")
+
+	if _, err := config.Load(); err == nil || !strings.Contains(err.Error(), "database.password") {
+		t.Fatalf("Load() error = %v, want error naming database.password", err)
+	}
+}
+
+func TestResolveSecret(t *testing.T) {
+	config.RegisterSecretProvider("fake", config.MapSecretProvider{"ops": fakeAPIKey})
+
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"plain-value", "plain-value", false},
+		{"fake:ops", fakeAPIKey, false},
+		{"fake:missing", "", true},
+		// 未注册的scheme原样返回，避免误解析含冒号的明文值
+		{"unknown:value", "unknown:value", false},
+	}
+	for _, tt := range tests {
+		got, err := config.ResolveSecret(context.Background(), tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ResolveSecret(%q) = %q, %v, want %q, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// assertRedacted 输出中不应出现任何解析后的密钥明文
+func assertRedacted(t *testing.T, where, out string) {
+	t.Helper()
+	for _, secret := range []string{envPassword, filePassword, fakeAPIKey} {
+		if strings.Contains(out, secret) {
+			t.Errorf("%s contains plaintext secret %q: %s", where, secret, out)
+		}
+	}
+}
+
+func TestSecretsRedacted(t *testing.T) {
+	cfg := loadWithSecrets(t)
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertRedacted(t, "json", string(data))
+	assertRedacted(t, "%v", fmt.Sprintf("%v", cfg))
+	assertRedacted(t, "%+v", fmt.Sprintf("%+v", cfg))
+	assertRedacted(t, "%#v", fmt.Sprintf("%#v", cfg))
+
+	for _, format := range []string{"text", "json"} {
+		path := filepath.Join(t.TempDir(), "app.log")
+		log := logger.New(config.LogConfig{Level: "info", Format: format, Output: "file", File: config.LogFileConfig{Path: path}})
+		log.WithField("database", cfg.Database).WithField("auth", cfg.Auth).Info("config loaded")
+		log.Infof("redis config: %+v", cfg.Redis)
+		if err := logger.Close(log); err != nil {
+			t.Fatal(err)
+		}
+		out, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(out) == 0 {
+			t.Fatalf("%s log is empty", format)
+		}
+		assertRedacted(t, format+" log", string(out))
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/v1/version", handler.Version(cfg.App))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/version", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("/version status = %d", w.Code)
+	}
+	assertRedacted(t, "/version", w.Body.String())
+}
@@ -0,0 +1,79 @@
+package event
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/internal/repository"
+	"go-api-scaffold/pkg/eventbus"
+	"go-api-scaffold/pkg/logger"
+)
+
+// Publisher 领域事件发布器
+type Publisher interface {
+	// Publish 发布事件，ctx处于事务中时事件随业务变更一起提交，提交后才分发给进程内订阅者
+	Publish(ctx context.Context, events ...eventbus.Event) error
+}
+
+// outboxPublisher 基于事务性outbox的发布器实现
+// 事件写入outbox表保证外部投递不丢失，同时在提交后分发到进程内事件总线
+type outboxPublisher struct {
+	repo   repository.OutboxRepository
+	bus    *eventbus.Bus
+	logger logger.Logger
+}
+
+// NewPublisher 创建事件发布器，bus为nil时仅写入outbox
+func NewPublisher(repo repository.OutboxRepository, bus *eventbus.Bus, logger logger.Logger) Publisher {
+	return &outboxPublisher{
+		repo:   repo,
+		bus:    bus,
+		logger: logger.WithField("component", "event_publisher"),
+	}
+}
+
+// Publish 发布事件
+func (p *outboxPublisher) Publish(ctx context.Context, events ...eventbus.Event) error {
+	now := time.Now()
+	msgs := make([]*model.OutboxMessage, 0, len(events))
+	for _, e := range events {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, &model.OutboxMessage{
+			EventID:       newEventID(),
+			EventName:     e.EventName(),
+			Payload:       string(payload),
+			Status:        model.OutboxStatusPending,
+			NextAttemptAt: now,
+		})
+	}
+	if err := p.repo.Create(ctx, msgs...); err != nil {
+		return err
+	}
+
+	if p.bus == nil {
+		return nil
+	}
+	repository.AfterCommit(ctx, func(ctx context.Context) {
+		for _, e := range events {
+			// 事务已提交，同步订阅者的错误无法回滚，仅记录日志
+			if err := p.bus.Publish(ctx, e); err != nil {
+				p.logger.WithContext(ctx).WithError(err).WithField("event", e.EventName()).Error("Event subscriber failed")
+			}
+		}
+	})
+	return nil
+}
+
+// newEventID 生成16字节随机事件ID，供下游去重
+func newEventID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,162 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/internal/repository"
+	"go-api-scaffold/pkg/eventbus"
+	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/retry"
+	"go-api-scaffold/pkg/strutil"
+)
+
+// maxRetryDelay 投递失败后的最大重试间隔
+const maxRetryDelay = 5 * time.Minute
+
+// RelayOptions outbox中继选项
+type RelayOptions struct {
+	Interval    time.Duration
+	BatchSize   int
+	MaxAttempts int
+	// Lease 领取后推迟下次投递的时间，应大于一批消息的投递耗时；实例在投递中途退出时到期后被重新领取
+	Lease time.Duration
+}
+
+// Relay outbox中继，周期性将已提交的outbox消息投递到sink
+// 投递语义为至少一次，下游应按事件ID去重；消息在短事务中领取，投递在事务外进行，
+// 多实例部署时领取的消息在租约期内不会被其他实例重复投递
+type Relay struct {
+	repo   repository.OutboxRepository
+	sink   eventbus.Sink
+	opts   RelayOptions
+	logger logger.Logger
+
+	ctx    context.Context // 投递上下文，强制关闭时取消
+	cancel context.CancelFunc
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewRelay 创建outbox中继并立即开始轮询
+func NewRelay(repo repository.OutboxRepository, sink eventbus.Sink, opts RelayOptions, logger logger.Logger) *Relay {
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 100
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 10
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = time.Minute
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &Relay{
+		repo:   repo,
+		sink:   sink,
+		opts:   opts,
+		logger: logger.WithField("component", "outbox_relay"),
+		ctx:    ctx,
+		cancel: cancel,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Shutdown 停止轮询并等待当前批次投递完成
+// ctx到期时取消投递中的请求并返回ctx.Err()，未更新状态的消息在租约到期后重新投递
+func (r *Relay) Shutdown(ctx context.Context) error {
+	close(r.stop)
+	defer r.cancel()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run 周期性投递，一批满载时立即继续下一批
+func (r *Relay) run() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			for {
+				n, err := r.Flush(r.ctx)
+				if err != nil {
+					r.logger.WithError(err).Error("Failed to relay outbox messages")
+				}
+				if err != nil || n < r.opts.BatchSize {
+					break
+				}
+				select {
+				case <-r.stop:
+					return
+				default:
+				}
+			}
+		}
+	}
+}
+
+// Flush 领取一批到期消息并逐条投递，返回处理的消息数
+// 投递不在事务中进行，慢速sink不会长时间占用数据库连接和行锁
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	msgs, err := r.repo.ClaimPending(ctx, time.Now(), r.opts.Lease, r.opts.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, msg := range msgs {
+		if err := r.deliver(ctx, msg); err != nil {
+			// 状态更新失败的消息在租约到期后重新投递
+			r.logger.WithContext(ctx).WithError(err).WithField("event_id", msg.EventID).Error("Failed to update outbox message")
+		}
+	}
+	return len(msgs), nil
+}
+
+// deliver 投递单条消息并更新状态，只有更新状态失败时返回错误
+func (r *Relay) deliver(ctx context.Context, msg *model.OutboxMessage) error {
+	sendErr := r.sink.Send(ctx, eventbus.Message{
+		ID:         msg.EventID,
+		Name:       msg.EventName,
+		Payload:    json.RawMessage(msg.Payload),
+		OccurredAt: msg.CreatedAt,
+	})
+	if sendErr == nil {
+		return r.repo.MarkPublished(ctx, msg.ID, time.Now())
+	}
+
+	msg.Attempts++
+	msg.LastError = strutil.Truncate(sendErr.Error(), 1024)
+	msg.NextAttemptAt = time.Now().Add(retry.Backoff(r.opts.Interval, maxRetryDelay, msg.Attempts))
+
+	log := r.logger.WithContext(ctx).WithError(sendErr).WithFields(map[string]interface{}{
+		"event_id":   msg.EventID,
+		"event_name": msg.EventName,
+		"attempts":   msg.Attempts,
+	})
+	if msg.Attempts >= r.opts.MaxAttempts {
+		msg.Status = model.OutboxStatusFailed
+		log.Error("Outbox message delivery failed permanently")
+	} else {
+		log.Warn("Outbox message delivery failed, will retry")
+	}
+	return r.repo.MarkFailed(ctx, msg)
+}
@@ -0,0 +1,173 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/internal/repository"
+	"go-api-scaffold/pkg/eventbus"
+	"go-api-scaffold/pkg/logger"
+)
+
+// memoryOutbox 内存实现的outbox仓储
+type memoryOutbox struct {
+	mu   sync.Mutex
+	msgs []*model.OutboxMessage
+}
+
+func (r *memoryOutbox) Create(_ context.Context, msgs ...*model.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range msgs {
+		msg.ID = uint(len(r.msgs) + 1)
+		r.msgs = append(r.msgs, msg)
+	}
+	return nil
+}
+
+func (r *memoryOutbox) ClaimPending(_ context.Context, now time.Time, lease time.Duration, limit int) ([]*model.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claimed []*model.OutboxMessage
+	for _, msg := range r.msgs {
+		if len(claimed) == limit {
+			break
+		}
+		if msg.Status == model.OutboxStatusPending && !msg.NextAttemptAt.After(now) {
+			msg.NextAttemptAt = now.Add(lease)
+			copied := *msg
+			claimed = append(claimed, &copied)
+		}
+	}
+	return claimed, nil
+}
+
+func (r *memoryOutbox) MarkPublished(_ context.Context, id uint, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	msg := r.msgs[id-1]
+	msg.Status = model.OutboxStatusPublished
+	msg.PublishedAt = &at
+	msg.LastError = ""
+	return nil
+}
+
+func (r *memoryOutbox) MarkFailed(_ context.Context, msg *model.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := r.msgs[msg.ID-1]
+	stored.Status = msg.Status
+	stored.Attempts = msg.Attempts
+	stored.LastError = msg.LastError
+	stored.NextAttemptAt = msg.NextAttemptAt
+	return nil
+}
+
+// flakySink 前failures次投递失败的sink，记录投递时是否处于事务中
+type flakySink struct {
+	failures      int
+	sent          []string
+	inTransaction bool
+}
+
+func (s *flakySink) Send(ctx context.Context, msg eventbus.Message) error {
+	if repository.InTransaction(ctx) {
+		s.inTransaction = true
+	}
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	s.sent = append(s.sent, msg.ID)
+	return nil
+}
+
+func TestRelayFlush(t *testing.T) {
+	repo := &memoryOutbox{}
+	now := time.Now()
+	_ = repo.Create(context.Background(),
+		&model.OutboxMessage{EventID: "evt-1", EventName: UserCreatedName, Payload: "{}", Status: model.OutboxStatusPending, NextAttemptAt: now},
+		&model.OutboxMessage{EventID: "evt-2", EventName: UserDeletedName, Payload: "{}", Status: model.OutboxStatusPending, NextAttemptAt: now},
+	)
+
+	sink := &flakySink{failures: 1}
+	log := logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"})
+	relay := NewRelay(repo, sink, RelayOptions{Interval: time.Hour, MaxAttempts: 2, Lease: time.Minute}, log)
+	defer relay.Shutdown(context.Background())
+
+	n, err := relay.Flush(context.Background())
+	if err != nil || n != 2 {
+		t.Fatalf("Flush() = %d, %v, want 2, nil", n, err)
+	}
+	if sink.inTransaction {
+		t.Fatal("sink was called inside a database transaction")
+	}
+
+	first, second := repo.msgs[0], repo.msgs[1]
+	if first.Status != model.OutboxStatusPending || first.Attempts != 1 || first.LastError != "sink unavailable" {
+		t.Fatalf("failed message = %+v", first)
+	}
+	if second.Status != model.OutboxStatusPublished || second.PublishedAt == nil {
+		t.Fatalf("published message = %+v", second)
+	}
+
+	// 重试时间未到，不会重新领取
+	if n, _ := relay.Flush(context.Background()); n != 0 {
+		t.Fatalf("Flush() before retry = %d, want 0", n)
+	}
+
+	first.NextAttemptAt = time.Now().Add(-time.Second)
+	if n, _ := relay.Flush(context.Background()); n != 1 {
+		t.Fatalf("Flush() after retry delay = %d, want 1", n)
+	}
+	if first.Status != model.OutboxStatusPublished {
+		t.Fatalf("retried message status = %s", first.Status)
+	}
+	if len(sink.sent) != 2 || sink.sent[0] != "evt-2" || sink.sent[1] != "evt-1" {
+		t.Fatalf("sent = %v", sink.sent)
+	}
+}
+
+// blockingSink 投递阻塞直到上下文取消
+type blockingSink struct {
+	started  chan struct{}
+	canceled chan error
+}
+
+func (s *blockingSink) Send(ctx context.Context, _ eventbus.Message) error {
+	close(s.started)
+	<-ctx.Done()
+	s.canceled <- ctx.Err()
+	return ctx.Err()
+}
+
+func TestRelayShutdownDeadline(t *testing.T) {
+	repo := &memoryOutbox{}
+	_ = repo.Create(context.Background(),
+		&model.OutboxMessage{EventID: "evt-1", EventName: UserCreatedName, Payload: "{}", Status: model.OutboxStatusPending, NextAttemptAt: time.Now()},
+	)
+
+	sink := &blockingSink{started: make(chan struct{}), canceled: make(chan error, 1)}
+	log := logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"})
+	relay := NewRelay(repo, sink, RelayOptions{Interval: 10 * time.Millisecond, Lease: time.Minute}, log)
+	<-sink.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := relay.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() = %v, want deadline exceeded", err)
+	}
+	select {
+	case err := <-sink.canceled:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("delivery context error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("in-flight delivery not canceled after shutdown deadline")
+	}
+}
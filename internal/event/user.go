@@ -0,0 +1,70 @@
+package event
+
+import (
+	"go-api-scaffold/internal/model"
+)
+
+// 用户事件名称
+const (
+	UserCreatedName  = "user.created"
+	UserUpdatedName  = "user.updated"
+	UserDeletedName  = "user.deleted"
+	UserRestoredName = "user.restored"
+	UserLoggedInName = "user.logged_in"
+)
+
+// Names 全部已知事件名称，用于校验订阅
+var Names = []string{UserCreatedName, UserUpdatedName, UserDeletedName, UserRestoredName, UserLoggedInName}
+
+// Known 判断事件名称是否已知
+func Known(name string) bool {
+	for _, n := range Names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// UserCreated 用户已创建
+type UserCreated struct {
+	User *model.UserResponse `json:"user"`
+}
+
+// EventName 实现eventbus.Event接口
+func (UserCreated) EventName() string { return UserCreatedName }
+
+// UserUpdated 用户已更新
+type UserUpdated struct {
+	Before *model.UserResponse `json:"before"`
+	After  *model.UserResponse `json:"after"`
+}
+
+// EventName 实现eventbus.Event接口
+func (UserUpdated) EventName() string { return UserUpdatedName }
+
+// UserDeleted 用户已删除
+type UserDeleted struct {
+	User *model.UserResponse `json:"user"`
+}
+
+// EventName 实现eventbus.Event接口
+func (UserDeleted) EventName() string { return UserDeletedName }
+
+// UserRestored 已删除的用户已恢复
+type UserRestored struct {
+	User *model.UserResponse `json:"user"`
+}
+
+// EventName 实现eventbus.Event接口
+func (UserRestored) EventName() string { return UserRestoredName }
+
+// UserLoggedIn 用户已登录
+type UserLoggedIn struct {
+	UserID    uint   `json:"user_id"`
+	IP        string `json:"ip,omitempty"`
+	UserAgent string `json:"user_agent,omitempty"`
+}
+
+// EventName 实现eventbus.Event接口
+func (UserLoggedIn) EventName() string { return UserLoggedInName }
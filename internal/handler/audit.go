@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/internal/service"
+	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/response"
+)
+
+// AuditHandler 审计日志处理器
+type AuditHandler struct {
+	service service.AuditService
+	logger  logger.Logger
+}
+
+// NewAuditHandler 创建审计日志处理器实例
+func NewAuditHandler(service service.AuditService, logger logger.Logger) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List 查询审计日志
+// 支持按actor_id、action、resource_type、resource_id过滤，from/to为RFC3339时间
+// @Summary 查询审计日志
+// @Tags 审计日志
+// @Produce json
+// @Param actor_id query string false "操作者ID"
+// @Param action query string false "操作类型"
+// @Param resource_type query string false "资源类型"
+// @Param resource_id query string false "资源ID"
+// @Param from query string false "起始时间（RFC3339）"
+// @Param to query string false "结束时间（RFC3339）"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量，最大100" default(20)
+// @Security BearerAuth
+// @Success 200 {object} response.PageResponse{data=[]model.AuditLog} "审计日志列表"
+// @Failure 400 {object} response.Response "时间格式错误"
+// @Failure 401 {object} response.Response "未认证或API密钥无效"
+// @Router /api/v1/audit-logs [get]
+func (h *AuditHandler) List(c *gin.Context) {
+	filter := model.AuditLogFilter{
+		ActorID:      c.Query("actor_id"),
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+	}
+
+	var err error
+	if filter.From, err = parseTimeQuery(c, "from"); err != nil {
+		response.BadRequest(c, "Invalid from time, expected RFC3339")
+		return
+	}
+	if filter.To, err = parseTimeQuery(c, "to"); err != nil {
+		response.BadRequest(c, "Invalid to time, expected RFC3339")
+		return
+	}
+
+	// 获取分页参数
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	// 限制分页大小
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	logs, meta, err := h.service.List(c.Request.Context(), filter, page, pageSize)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to get audit logs")
+		response.ServerError(c, "Failed to get audit logs")
+		return
+	}
+
+	response.SuccessPage(c, logs, *meta)
+}
+
+// parseTimeQuery 解析RFC3339格式的时间查询参数，未提供时返回nil
+func parseTimeQuery(c *gin.Context, key string) (*time.Time, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
@@ -1,30 +1,64 @@
 package handler
 
 import (
+	"expvar"
+	"fmt"
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/internal/middleware"
 	"go-api-scaffold/internal/service"
+	"go-api-scaffold/pkg/apiversion"
+	"go-api-scaffold/pkg/cache"
 	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/response"
 )
 
 // Handler 处理器集合
 type Handler struct {
-	User   *UserHandler
-	Health *HealthHandler
-	logger logger.Logger
+	User    *UserHandler
+	Audit   *AuditHandler
+	Webhook *WebhookHandler
+	Health  *HealthHandler
+	// Scheduler 启用定时任务时设置，为nil时不注册管理接口
+	Scheduler *SchedulerHandler
+	// EnableSwagger 为true时注册 /swagger/* 文档页面
+	EnableSwagger bool
+	// EnableDebugVars 为true时注册 /debug/vars 运行指标
+	EnableDebugVars bool
+	// Versions 接口版本选项，默认支持全部版本且均未弃用
+	Versions apiversion.Options
+	// APIKeys 认证使用的API密钥，审计日志和管理接口要求认证
+	APIKeys []middleware.APIKey
+	// Idempotency POST接口的幂等键选项，Store为nil时不启用
+	Idempotency middleware.IdempotencyOptions
+	// App 应用信息，由 /api/v1/version 返回
+	App config.AppConfig
+	// ResponseCache 公共接口共享响应缓存，为nil时只设置缓存头
+	ResponseCache cache.Store
+	logger        logger.Logger
 }
 
 // New 创建处理器实例
 func New(service *service.Service, logger logger.Logger) *Handler {
+	versions, _ := VersionOptions(config.APIConfig{})
 	return &Handler{
-		User:   NewUserHandler(service.User, logger),
-		Health: NewHealthHandler(logger),
-		logger: logger,
+		User:     NewUserHandler(service.User, logger),
+		Audit:    NewAuditHandler(service.Audit, logger),
+		Webhook:  NewWebhookHandler(service.Webhook, logger),
+		Health:   NewHealthHandler(logger),
+		Versions: versions,
+		logger:   logger,
 	}
 }
 
 // RegisterRoutes 注册路由
 func (h *Handler) RegisterRoutes(router *gin.Engine) {
-	// 健康检查
+	// 未匹配的路由与其他错误使用相同的响应格式
+	router.NoRoute(NoRoute)
+
+	// 健康检查，根路径供探针直接访问，/api/v1/health 下同样注册一份
 	health := router.Group("/health")
 	{
 		health.GET("/", h.Health.Check)
@@ -32,23 +66,123 @@ func (h *Handler) RegisterRoutes(router *gin.Engine) {
 		health.GET("/live", h.Health.Live)
 	}
 
-	// API路由组
-	api := router.Group("/api/v1")
-	{
-		// 用户相关路由
+	// 运行指标（缓存命中率等），包含启动参数等内部信息，默认不注册
+	if h.EnableDebugVars {
+		router.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+	}
+
+	// 接口文档
+	if h.EnableSwagger {
+		router.GET("/swagger/*any", SwaggerUI())
+	}
+
+	// API路由，按版本注册，响应格式按Accept头协商
+	// 认证在幂等中间件之前执行，幂等键按操作者隔离
+	authenticate := middleware.Authenticate(h.APIKeys)
+	requireAuth := middleware.RequireAuth()
+	versions := apiversion.New(router.Group("", authenticate, middleware.ContentNegotiation()), h.Versions)
+	idempotent := middleware.Idempotency(h.Idempotency, h.logger)
+
+	// 用户相关路由，v2使用新的响应结构
+	versions.Register(func(api *gin.RouterGroup) {
 		users := api.Group("/users")
 		{
-			users.POST("/", h.User.Create)
-			users.GET("/:id", h.User.GetByID)
+			users.POST("/", idempotent, h.User.Create)
+			users.POST("/batch", requireAuth, idempotent, h.User.Batch)
+			users.GET("/:id", middleware.HTTPCache(UserCachePolicy), h.User.GetByID)
 			users.PUT("/:id", h.User.Update)
 			users.DELETE("/:id", h.User.Delete)
 			users.GET("/", h.User.List)
 		}
+	}, 1, 2)
 
-		// 认证相关路由
+	// 用户批量导入导出使用CSV、NDJSON等格式，不经过内容协商中间件，由处理器自行选择格式
+	// 导出包含全部用户的邮箱、手机号，导入可一次创建大量账号，均要求认证
+	bulk := apiversion.New(router.Group("", authenticate, requireAuth), h.Versions)
+	bulk.Register(func(api *gin.RouterGroup) {
+		api.POST("/users/import", idempotent, h.User.Import)
+		api.GET("/users/export", h.User.Export)
+	}, 1)
+
+	api := versions.Group(1)
+	{
+		// 审计日志，包含客户端IP、UA及登录失败的用户名，要求认证
+		api.GET("/audit-logs", requireAuth, h.Audit.List)
+
+		// 健康检查
+		apiHealth := api.Group("/health")
+		{
+			apiHealth.GET("/check", h.Health.Check)
+			apiHealth.GET("/ready", h.Health.Ready)
+			apiHealth.GET("/live", h.Health.Live)
+		}
+
+		// Webhook订阅，事件和投递记录中包含用户名、邮箱，要求认证
+		webhooks := api.Group("/webhooks", requireAuth)
+		{
+			webhooks.POST("/", idempotent, h.Webhook.Create)
+			webhooks.GET("/", h.Webhook.List)
+			webhooks.GET("/:id", h.Webhook.GetByID)
+			webhooks.PUT("/:id", h.Webhook.Update)
+			webhooks.DELETE("/:id", h.Webhook.Delete)
+			webhooks.GET("/:id/deliveries", h.Webhook.ListDeliveries)
+			webhooks.POST("/:id/deliveries/:delivery_id/redeliver", idempotent, h.Webhook.Redeliver)
+		}
+
+		// 管理接口
+		if h.Scheduler != nil {
+			admin := api.Group("/admin", requireAuth)
+			{
+				admin.GET("/scheduler", h.Scheduler.Status)
+				admin.GET("/scheduler/runs", h.Scheduler.Runs)
+			}
+		}
+
+		// 认证相关路由，/api/v1/login 为兼容旧客户端保留
 		auth := api.Group("/auth")
 		{
 			auth.POST("/login", h.User.Login)
 		}
+		api.POST("/login", h.User.Login)
+
+		// 示例接口
+		api.GET("/ping", Ping)
+		api.GET("/version", middleware.HTTPCache(middleware.CachePolicy{
+			Public: true,
+			MaxAge: 5 * time.Minute,
+			Store:  h.ResponseCache,
+		}), Version(h.App))
+	}
+}
+
+// APIKeys 将认证配置转换为中间件使用的API密钥，操作者或密钥为空时返回错误
+func APIKeys(cfg config.AuthConfig) ([]middleware.APIKey, error) {
+	keys := make([]middleware.APIKey, 0, len(cfg.APIKeys))
+	for i, key := range cfg.APIKeys {
+		if key.Actor == "" || key.Key == "" {
+			return nil, fmt.Errorf("auth.api_keys[%d]: actor and key are required", i)
+		}
+		keys = append(keys, middleware.APIKey{Actor: key.Actor, Key: key.Key.Value()})
 	}
+	return keys, nil
+}
+
+// NoRoute 未匹配路由的处理器：请求了不支持的接口版本时返回406，否则返回404
+func NoRoute(c *gin.Context) {
+	if version, ok := apiversion.UnsupportedVersion(c.Request); ok {
+		response.Error(c, response.CodeNotAcceptable, "Unsupported API version: "+version)
+		return
+	}
+	response.NotFound(c, "Route not found")
+}
+
+// StreamingRoutes 分批写出响应的路由，响应校验等需要缓冲完整响应的中间件应跳过
+var StreamingRoutes = []string{
+	"GET /api/v1/users/export",
+}
+
+// UserCachePolicy 用户详情缓存策略：私有缓存，每次使用前通过ETag/Last-Modified重新验证
+var UserCachePolicy = middleware.CachePolicy{
+	NoCache: true,
+	Vary:    []string{"Accept", "Authorization"},
 }
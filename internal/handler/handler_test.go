@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/internal/service"
+	"go-api-scaffold/pkg/apiversion"
+	"go-api-scaffold/pkg/cache"
+	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/response"
+)
+
+// TestProtectedRoutesRequireAuth 需要认证的接口未认证时返回401，不会执行到处理器
+func TestProtectedRoutesRequireAuth(t *testing.T) {
+	router := newFullRouter(t)
+	for _, route := range protectedRoutes {
+		method, path, _ := strings.Cut(route, " ")
+		req := httptest.NewRequest(method, path, strings.NewReader("{}"))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("%s status = %d, want 401", route, w.Code)
+		}
+	}
+}
+
+// protectedRoutes 要求认证的接口
+var protectedRoutes = []string{
+	"GET /api/v1/audit-logs",
+	"GET /api/v1/admin/scheduler",
+	"GET /api/v1/admin/scheduler/runs",
+	"POST /api/v1/webhooks/",
+	"GET /api/v1/webhooks/",
+	"GET /api/v1/webhooks/1",
+	"PUT /api/v1/webhooks/1",
+	"DELETE /api/v1/webhooks/1",
+	"GET /api/v1/webhooks/1/deliveries",
+	"POST /api/v1/webhooks/1/deliveries/1/redeliver",
+	"POST /api/v1/users/import",
+	"GET /api/v1/users/export",
+	"POST /api/v1/users/batch",
+}
+
+// TestStreamingRoutesRegistered 流式路由列表与注册的路由一致，路径变更后不会悄悄失效
+func TestStreamingRoutesRegistered(t *testing.T) {
+	registered := make(map[string]bool)
+	for _, route := range newFullRouter(t).Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+	for _, route := range StreamingRoutes {
+		if !registered[route] {
+			t.Errorf("streaming route %s is not registered", route)
+		}
+	}
+}
+
+// TestErrorFallbacksUseRenderer 不支持的版本和未匹配的路由按配置的错误格式返回
+func TestErrorFallbacksUseRenderer(t *testing.T) {
+	versions, err := VersionOptions(config.APIConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := apiversion.Negotiate(newFullRouter(t), versions)
+
+	tests := []struct {
+		name       string
+		format     response.ErrorFormat
+		path       string
+		accept     string
+		wantStatus int
+		wantType   string
+	}{
+		{"unsupported version envelope", response.ErrorFormatEnvelope, "/api/users/", "application/vnd.go-api-scaffold+json; version=9", http.StatusNotAcceptable, "application/json"},
+		{"unsupported version problem", response.ErrorFormatProblem, "/api/users/", "application/vnd.go-api-scaffold+json; version=9", http.StatusNotAcceptable, "application/problem+json"},
+		{"unknown route envelope", response.ErrorFormatEnvelope, "/api/v1/nope", "", http.StatusNotFound, "application/json"},
+		{"unknown route problem", response.ErrorFormatProblem, "/nope", "", http.StatusNotFound, "application/problem+json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := response.SetErrorOptions(response.ErrorOptions{Format: tt.format}); err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = response.SetErrorOptions(response.ErrorOptions{}) })
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.wantType) {
+				t.Fatalf("Content-Type = %q, want %s", got, tt.wantType)
+			}
+			var body map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("body is not JSON: %s", w.Body)
+			}
+			key := "code"
+			if tt.format == response.ErrorFormatProblem {
+				key = "status"
+			}
+			if body[key] != float64(tt.wantStatus) {
+				t.Fatalf("body[%s] = %v, want %d", key, body[key], tt.wantStatus)
+			}
+		})
+	}
+}
+
+// TestVersionCacheVariesByAccept 共享缓存按Accept区分，XML响应不会返回给请求JSON的客户端
+func TestVersionCacheVariesByAccept(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handlers := New(&service.Service{}, logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"}))
+	handlers.ResponseCache = cache.NewMemoryStore(100)
+	router := gin.New()
+	handlers.RegisterRoutes(router)
+
+	get := func(accept string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/version", nil)
+		req.Header.Set("Accept", accept)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	steps := []struct {
+		accept     string
+		wantType   string
+		wantXCache string
+	}{
+		{"application/xml", "application/xml", "MISS"},
+		{"application/json", "application/json", "MISS"},
+		{"application/xml", "application/xml", "HIT"},
+		{"application/json", "application/json", "HIT"},
+	}
+	for _, step := range steps {
+		w := get(step.accept)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Accept %s: status = %d, want 200", step.accept, w.Code)
+		}
+		if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, step.wantType) {
+			t.Errorf("Accept %s: Content-Type = %q, want %s", step.accept, got, step.wantType)
+		}
+		if got := w.Header().Get("X-Cache"); got != step.wantXCache {
+			t.Errorf("Accept %s: X-Cache = %q, want %s", step.accept, got, step.wantXCache)
+		}
+		if vary := strings.Join(w.Header().Values("Vary"), ","); !strings.Contains(vary, "Accept") {
+			t.Errorf("Accept %s: Vary = %q, want Accept", step.accept, vary)
+		}
+	}
+}
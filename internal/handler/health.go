@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"context"
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -9,16 +12,45 @@ import (
 	"go-api-scaffold/pkg/response"
 )
 
+// ReadinessCheck 就绪检查项，返回错误表示依赖不可用
+type ReadinessCheck func(ctx context.Context) error
+
+// readinessCheckTimeout 单个就绪检查项的超时时间
+const readinessCheckTimeout = 2 * time.Second
+
 // HealthHandler 健康检查处理器
 type HealthHandler struct {
 	logger logger.Logger
+
+	mu     sync.RWMutex
+	names  []string
+	checks map[string]ReadinessCheck
+
+	shuttingDown atomic.Bool
 }
 
 // NewHealthHandler 创建健康检查处理器实例
 func NewHealthHandler(logger logger.Logger) *HealthHandler {
 	return &HealthHandler{
 		logger: logger,
+		checks: make(map[string]ReadinessCheck),
+	}
+}
+
+// AddReadinessCheck 注册就绪检查项，同名检查项会被覆盖
+func (h *HealthHandler) AddReadinessCheck(name string, check ReadinessCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, exists := h.checks[name]; !exists {
+		h.names = append(h.names, name)
 	}
+	h.checks[name] = check
+}
+
+// SetShuttingDown 进入关闭流程，此后就绪检查始终失败，使负载均衡不再分发新流量
+func (h *HealthHandler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
 }
 
 // HealthResponse 健康检查响应
@@ -37,8 +69,9 @@ var startTime = time.Now()
 // @Tags 健康检查
 // @Accept json
 // @Produce json
-// @Success 200 {object} HealthResponse "健康状态信息"
-// @Router /health/check [get]
+// @Success 200 {object} response.Response{data=HealthResponse} "健康状态信息"
+// @Router /health/ [get]
+// @Router /api/v1/health/check [get]
 func (h *HealthHandler) Check(c *gin.Context) {
 	uptime := time.Since(startTime)
 
@@ -59,12 +92,54 @@ func (h *HealthHandler) Check(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Success 200 {object} map[string]interface{} "就绪状态"
+// @Failure 503 {object} map[string]interface{} "未就绪"
 // @Router /health/ready [get]
+// @Router /api/v1/health/ready [get]
 func (h *HealthHandler) Ready(c *gin.Context) {
-	// 这里可以添加依赖服务的检查，如数据库连接等
-	// 目前简单返回就绪状态
+	if h.shuttingDown.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":    "shutting down",
+			"timestamp": time.Now(),
+		})
+		return
+	}
+
+	h.mu.RLock()
+	names := append([]string(nil), h.names...)
+	checks := make(map[string]ReadinessCheck, len(h.checks))
+	for name, check := range h.checks {
+		checks[name] = check
+	}
+	h.mu.RUnlock()
+
+	// 逐项检查依赖服务，如数据库连接等
+	ready := true
+	results := make(map[string]string, len(names))
+	for _, name := range names {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessCheckTimeout)
+		err := checks[name](ctx)
+		cancel()
+
+		if err != nil {
+			ready = false
+			results[name] = err.Error()
+			continue
+		}
+		results[name] = "ok"
+	}
+
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":    "not ready",
+			"checks":    results,
+			"timestamp": time.Now(),
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"status":    "ready",
+		"checks":    results,
 		"timestamp": time.Now(),
 	})
 }
@@ -77,6 +152,7 @@ func (h *HealthHandler) Ready(c *gin.Context) {
 // @Produce json
 // @Success 200 {object} map[string]interface{} "存活状态"
 // @Router /health/live [get]
+// @Router /api/v1/health/live [get]
 func (h *HealthHandler) Live(c *gin.Context) {
 	// 存活检查，确认服务正在运行
 	c.JSON(http.StatusOK, gin.H{
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/pkg/response"
+)
+
+// Ping Ping测试接口
+// @Summary Ping
+// @Tags 示例
+// @Produce json
+// @Success 200 {object} map[string]string "pong"
+// @Router /api/v1/ping [get]
+func Ping(c *gin.Context) {
+	response.Render(c, http.StatusOK, gin.H{
+		"message": "pong",
+		"time":    time.Now().Format(time.RFC3339),
+	})
+}
+
+// Version 获取版本信息
+// @Summary 版本信息
+// @Tags 示例
+// @Produce json
+// @Success 200 {object} map[string]string "应用名称、版本及运行环境"
+// @Router /api/v1/version [get]
+func Version(app config.AppConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.Render(c, http.StatusOK, gin.H{
+			"name":    app.Name,
+			"version": app.Version,
+			"env":     app.Environment,
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/response"
+	"go-api-scaffold/pkg/scheduler"
+)
+
+// SchedulerHandler 定时任务管理处理器
+type SchedulerHandler struct {
+	scheduler *scheduler.Scheduler
+	logger    logger.Logger
+}
+
+// NewSchedulerHandler 创建定时任务管理处理器实例
+func NewSchedulerHandler(scheduler *scheduler.Scheduler, logger logger.Logger) *SchedulerHandler {
+	return &SchedulerHandler{
+		scheduler: scheduler,
+		logger:    logger,
+	}
+}
+
+// Status 查询调度器状态、各任务下次执行时间及最近一次执行结果
+// @Summary 定时任务调度器状态
+// @Tags 管理
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=scheduler.Status} "调度器状态"
+// @Failure 401 {object} response.Response "未认证或API密钥无效"
+// @Router /api/v1/admin/scheduler [get]
+func (h *SchedulerHandler) Status(c *gin.Context) {
+	status, err := h.scheduler.Status(c.Request.Context())
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to get scheduler status")
+		response.ServerError(c, "Failed to get scheduler status")
+		return
+	}
+
+	response.Success(c, status)
+}
+
+// Runs 查询执行历史，支持按task、status过滤
+// @Summary 定时任务执行历史
+// @Tags 管理
+// @Produce json
+// @Param task query string false "任务名"
+// @Param status query string false "执行状态"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量，最大100" default(20)
+// @Security BearerAuth
+// @Success 200 {object} response.PageResponse{data=[]scheduler.Run} "执行记录"
+// @Failure 401 {object} response.Response "未认证或API密钥无效"
+// @Router /api/v1/admin/scheduler/runs [get]
+func (h *SchedulerHandler) Runs(c *gin.Context) {
+	filter := scheduler.RunFilter{
+		Task:   c.Query("task"),
+		Status: c.Query("status"),
+	}
+
+	// 获取分页参数
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page <= 0 {
+		page = 1
+	}
+
+	// 限制分页大小
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	runs, total, err := h.scheduler.Runs(c.Request.Context(), filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		h.logger.WithContext(c.Request.Context()).WithError(err).Error("Failed to get scheduler runs")
+		response.ServerError(c, "Failed to get scheduler runs")
+		return
+	}
+
+	response.SuccessPage(c, runs, response.PageMeta{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	})
+}
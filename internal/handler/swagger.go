@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+	"github.com/swaggo/swag"
+
+	// 注册由swag生成的接口文档
+	_ "go-api-scaffold/docs"
+)
+
+// SwaggerUI 接口文档页面，挂载在 /swagger/*any
+func SwaggerUI() gin.HandlerFunc {
+	return ginSwagger.WrapHandler(swaggerFiles.Handler)
+}
+
+// OpenAPISpec 返回生成的接口文档（Swagger 2.0 JSON）
+func OpenAPISpec() ([]byte, error) {
+	doc, err := swag.ReadDoc()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read swagger doc: %w", err)
+	}
+	return []byte(doc), nil
+}
+
+// undocumentedRoutes 有意不写入接口文档的路由
+var undocumentedRoutes = map[string]bool{
+	"GET /debug/vars":   true, // expvar运行指标，非业务接口
+	"GET /swagger/*any": true, // 文档页面本身
+}
+
+// MissingFromSpec 返回已注册但未出现在生成文档中的路由，格式为"METHOD /path"
+// 文档过期（新增路由后未重新执行 make swagger）时由 make swagger-check 报错
+func MissingFromSpec(routes gin.RoutesInfo) ([]string, error) {
+	doc, err := OpenAPISpec()
+	if err != nil {
+		return nil, err
+	}
+
+	var spec struct {
+		BasePath string                                `json:"basePath"`
+		Paths    map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(doc, &spec); err != nil {
+		return nil, fmt.Errorf("failed to parse swagger doc: %w", err)
+	}
+
+	documented := make(map[string]bool)
+	basePath := strings.TrimSuffix(spec.BasePath, "/")
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			documented[strings.ToUpper(method)+" "+basePath+path] = true
+		}
+	}
+
+	var missing []string
+	for _, route := range routes {
+		key := route.Method + " " + route.Path
+		if undocumentedRoutes[key] {
+			continue
+		}
+		if !documented[route.Method+" "+swaggerPath(route.Path)] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// swaggerPath 将gin路由参数 :id 转换为文档中的 {id}
+func swaggerPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
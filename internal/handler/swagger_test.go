@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/internal/service"
+	"go-api-scaffold/pkg/logger"
+)
+
+// newFullRouter 注册完整路由表的路由，可选路由全部打开
+// 仅注册路由不处理请求，处理器依赖可以为空
+func newFullRouter(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	log := logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"})
+
+	handlers := New(&service.Service{}, log)
+	handlers.Scheduler = NewSchedulerHandler(nil, log)
+	handlers.EnableSwagger = true
+	handlers.EnableDebugVars = true
+
+	router := gin.New()
+	handlers.RegisterRoutes(router)
+	return router
+}
+
+// TestRoutesDocumented 注册的路由都应写入接口文档，失败时执行 make swagger 重新生成
+func TestRoutesDocumented(t *testing.T) {
+	missing, err := MissingFromSpec(newFullRouter(t).Routes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, route := range missing {
+		t.Errorf("route %s is missing from docs/swagger.yaml, add swag annotations and run `make swagger`", route)
+	}
+}
+
+// TestDocumentedRoutesRegistered 文档中的接口都应有对应路由，避免文档描述已不存在的路径
+func TestDocumentedRoutesRegistered(t *testing.T) {
+	doc, err := OpenAPISpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec struct {
+		BasePath string                                `json:"basePath"`
+		Paths    map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(doc, &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	registered := make(map[string]bool)
+	for _, route := range newFullRouter(t).Routes() {
+		registered[route.Method+" "+swaggerPath(route.Path)] = true
+	}
+	basePath := strings.TrimSuffix(spec.BasePath, "/")
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			key := strings.ToUpper(method) + " " + basePath + path
+			if !registered[key] {
+				t.Errorf("documented operation %s has no registered route", key)
+			}
+		}
+	}
+}
+
+func TestSwaggerPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/health/", "/health/"},
+		{"/api/v1/users/:id", "/api/v1/users/{id}"},
+		{"/api/v1/webhooks/:id/deliveries/:delivery_id/redeliver", "/api/v1/webhooks/{id}/deliveries/{delivery_id}/redeliver"},
+		{"/swagger/*any", "/swagger/*any"},
+	}
+	for _, tt := range tests {
+		if got := swaggerPath(tt.path); got != tt.want {
+			t.Errorf("swaggerPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -9,6 +11,7 @@ import (
 	"go-api-scaffold/internal/model"
 	"go-api-scaffold/internal/service"
 	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/request"
 	"go-api-scaffold/pkg/response"
 )
 
@@ -29,33 +32,59 @@ func NewUserHandler(service service.UserService, logger logger.Logger) *UserHand
 }
 
 // Create 创建用户
+// @Summary 创建用户
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Param request body model.UserCreateRequest true "用户信息"
+// @Param Idempotency-Key header string false "幂等键，重试时使用同一键，服务端重放首次的响应"
+// @Success 200 {object} response.Response{data=model.UserResponse} "创建成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 409 {object} response.Response "用户名或邮箱已存在，或同一Idempotency-Key的请求仍在处理"
+// @Failure 413 {object} response.Response "携带Idempotency-Key的请求体超过idempotency.max_body_size"
+// @Failure 422 {object} response.Response "Idempotency-Key已用于不同的请求体"
+// @Router /api/v1/users/ [post]
 func (h *UserHandler) Create(c *gin.Context) {
 	var req model.UserCreateRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Invalid request body: " + err.Error())
+	if err := request.Bind(c, &req); err != nil {
+		h.log(c).WithError(err).Error("Invalid request body")
 		response.BadRequest(c, "Invalid request body")
 		return
 	}
 
 	// 验证请求参数
 	if err := h.validator.Struct(&req); err != nil {
-		h.logger.Error("Validation failed: " + err.Error())
+		h.log(c).WithError(err).Error("Validation failed")
 		response.BadRequest(c, "Validation failed: "+err.Error())
 		return
 	}
 
 	// 创建用户
-	user, err := h.service.Create(&req)
+	user, err := h.service.Create(c.Request.Context(), &req)
 	if err != nil {
-		h.logger.Error("Failed to create user: " + err.Error())
-		response.BadRequest(c, err.Error())
+		h.log(c).WithError(err).Error("Failed to create user")
+		if isConflict(err) {
+			response.Conflict(c, err.Error())
+		} else {
+			response.BadRequest(c, err.Error())
+		}
 		return
 	}
 
-	response.SuccessWithMessage(c, "User created successfully", user)
+	response.SuccessWithMessage(c, "User created successfully", userViews.Map(c, user))
 }
 
 // GetByID 根据ID获取用户
+// @Summary 获取用户详情
+// @Description 响应携带ETag与Last-Modified，支持If-None-Match/If-Modified-Since条件请求
+// @Tags 用户
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=model.UserResponse} "用户信息"
+// @Success 304 "未修改"
+// @Failure 400 {object} response.Response "ID格式错误"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Router /api/v1/users/{id} [get]
 func (h *UserHandler) GetByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -64,10 +93,10 @@ func (h *UserHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	user, err := h.service.GetByID(uint(id))
+	user, err := h.service.GetByID(c.Request.Context(), uint(id))
 	if err != nil {
-		h.logger.Error("Failed to get user: " + err.Error())
-		if err.Error() == "user not found" {
+		h.log(c).WithError(err).Error("Failed to get user")
+		if errors.Is(err, service.ErrUserNotFound) {
 			response.NotFound(c, "User not found")
 		} else {
 			response.ServerError(c, "Failed to get user")
@@ -75,10 +104,23 @@ func (h *UserHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, user)
+	// 供HTTP缓存中间件处理If-Modified-Since
+	c.Header("Last-Modified", user.UpdatedAt.UTC().Format(http.TimeFormat))
+	response.Success(c, userViews.Map(c, user))
 }
 
 // Update 更新用户
+// @Summary 更新用户
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param request body model.UserUpdateRequest true "需要更新的字段"
+// @Success 200 {object} response.Response{data=model.UserResponse} "更新成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 409 {object} response.Response "用户名或邮箱已存在"
+// @Router /api/v1/users/{id} [put]
 func (h *UserHandler) Update(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -88,35 +130,45 @@ func (h *UserHandler) Update(c *gin.Context) {
 	}
 
 	var req model.UserUpdateRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Invalid request body: " + err.Error())
+	if err := request.Bind(c, &req); err != nil {
+		h.log(c).WithError(err).Error("Invalid request body")
 		response.BadRequest(c, "Invalid request body")
 		return
 	}
 
 	// 验证请求参数
 	if err := h.validator.Struct(&req); err != nil {
-		h.logger.Error("Validation failed: " + err.Error())
+		h.log(c).WithError(err).Error("Validation failed")
 		response.BadRequest(c, "Validation failed: "+err.Error())
 		return
 	}
 
 	// 更新用户
-	user, err := h.service.Update(uint(id), &req)
+	user, err := h.service.Update(c.Request.Context(), uint(id), &req)
 	if err != nil {
-		h.logger.Error("Failed to update user: " + err.Error())
-		if err.Error() == "user not found" {
+		h.log(c).WithError(err).Error("Failed to update user")
+		if errors.Is(err, service.ErrUserNotFound) {
 			response.NotFound(c, "User not found")
+		} else if isConflict(err) {
+			response.Conflict(c, err.Error())
 		} else {
 			response.BadRequest(c, err.Error())
 		}
 		return
 	}
 
-	response.SuccessWithMessage(c, "User updated successfully", user)
+	response.SuccessWithMessage(c, "User updated successfully", userViews.Map(c, user))
 }
 
 // Delete 删除用户
+// @Summary 删除用户
+// @Tags 用户
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response "删除成功"
+// @Failure 400 {object} response.Response "ID格式错误"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Router /api/v1/users/{id} [delete]
 func (h *UserHandler) Delete(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
@@ -125,9 +177,9 @@ func (h *UserHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.Delete(uint(id)); err != nil {
-		h.logger.Error("Failed to delete user: " + err.Error())
-		if err.Error() == "user not found" {
+	if err := h.service.Delete(c.Request.Context(), uint(id)); err != nil {
+		h.log(c).WithError(err).Error("Failed to delete user")
+		if errors.Is(err, service.ErrUserNotFound) {
 			response.NotFound(c, "User not found")
 		} else {
 			response.ServerError(c, "Failed to delete user")
@@ -139,6 +191,14 @@ func (h *UserHandler) Delete(c *gin.Context) {
 }
 
 // List 获取用户列表
+// @Summary 获取用户列表
+// @Tags 用户
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量，最大100" default(10)
+// @Success 200 {object} response.PageResponse{data=[]model.UserResponse} "用户列表"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /api/v1/users/ [get]
 func (h *UserHandler) List(c *gin.Context) {
 	// 获取分页参数
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -149,43 +209,62 @@ func (h *UserHandler) List(c *gin.Context) {
 		pageSize = 100
 	}
 
-	users, meta, err := h.service.List(page, pageSize)
+	users, meta, err := h.service.List(c.Request.Context(), page, pageSize)
 	if err != nil {
-		h.logger.Error("Failed to get user list: " + err.Error())
+		h.log(c).WithError(err).Error("Failed to get user list")
 		response.ServerError(c, "Failed to get user list")
 		return
 	}
 
-	response.SuccessPage(c, users, *meta)
+	response.SuccessPage(c, userViews.MapAll(c, users), *meta)
 }
 
 // Login 用户登录
+// @Summary 用户登录
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body model.UserLoginRequest true "登录凭证"
+// @Success 200 {object} response.Response{data=model.UserResponse} "登录成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "用户名或密码错误"
+// @Router /api/v1/auth/login [post]
+// @Router /api/v1/login [post]
 func (h *UserHandler) Login(c *gin.Context) {
-	var req struct {
-		Username string `json:"username" validate:"required"`
-		Password string `json:"password" validate:"required"`
-	}
+	var req model.UserLoginRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Invalid request body: " + err.Error())
+	if err := request.Bind(c, &req); err != nil {
+		h.log(c).WithError(err).Error("Invalid request body")
 		response.BadRequest(c, "Invalid request body")
 		return
 	}
 
 	// 验证请求参数
 	if err := h.validator.Struct(&req); err != nil {
-		h.logger.Error("Validation failed: " + err.Error())
+		h.log(c).WithError(err).Error("Validation failed")
 		response.BadRequest(c, "Username and password are required")
 		return
 	}
 
 	// 用户登录
-	user, err := h.service.Login(req.Username, req.Password)
+	user, err := h.service.Login(c.Request.Context(), req.Username, req.Password)
 	if err != nil {
-		h.logger.Error("Login failed: " + err.Error())
+		h.log(c).WithError(err).Error("Login failed")
 		response.Unauthorized(c, err.Error())
 		return
 	}
 
-	response.SuccessWithMessage(c, "Login successful", user)
+	response.SuccessWithMessage(c, "Login successful", userViews.Map(c, user))
+}
+
+// log 返回携带请求上下文字段的日志实例
+func (h *UserHandler) log(c *gin.Context) logger.Logger {
+	return h.logger.WithContext(c.Request.Context())
+}
+
+// isConflict 判断是否为唯一性冲突错误
+func isConflict(err error) bool {
+	return errors.Is(err, service.ErrUsernameExists) ||
+		errors.Is(err, service.ErrEmailExists) ||
+		errors.Is(err, service.ErrUserExists)
 }
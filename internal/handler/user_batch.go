@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/internal/service"
+	"go-api-scaffold/pkg/request"
+	"go-api-scaffold/pkg/response"
+)
+
+// Batch 批量操作用户
+// @Summary 批量操作用户
+// @Description 对多个用户执行同一操作：set_status（需提供status）、delete（软删除）或restore（恢复软删除），单次最多100个ID。
+// @Description mode=atomic（默认）时在同一事务中执行，任一失败全部回滚，成功项标记为rolled_back；
+// @Description mode=best_effort时逐个执行，单个失败不影响其他。各项的结果和错误码在items中逐个返回
+// @Tags 用户
+// @Accept json
+// @Produce json
+// @Param request body model.UserBatchRequest true "操作类型、用户ID列表和执行模式"
+// @Param Idempotency-Key header string false "幂等键，重试时使用同一键，服务端重放首次的响应"
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.UserBatchResult} "各用户的操作结果"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未认证或API密钥无效"
+// @Failure 409 {object} response.Response "同一Idempotency-Key的请求仍在处理"
+// @Failure 413 {object} response.Response "携带Idempotency-Key的请求体超过idempotency.max_body_size"
+// @Failure 422 {object} response.Response "Idempotency-Key已用于不同的请求体"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /api/v1/users/batch [post]
+func (h *UserHandler) Batch(c *gin.Context) {
+	var req model.UserBatchRequest
+	if err := request.Bind(c, &req); err != nil {
+		h.log(c).WithError(err).Error("Invalid request body")
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	// 验证请求参数
+	if err := h.validator.Struct(&req); err != nil {
+		h.log(c).WithError(err).Error("Validation failed")
+		response.BadRequest(c, "Validation failed: "+err.Error())
+		return
+	}
+
+	result, err := h.service.Batch(c.Request.Context(), &req)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to run batch operation")
+		response.ServerError(c, "Failed to run batch operation")
+		return
+	}
+
+	for i := range result.Items {
+		item := &result.Items[i]
+		if item.Err != nil {
+			item.Code, item.Error = h.batchItemError(c, item)
+		}
+	}
+
+	message := "Batch operation completed"
+	switch {
+	case result.RolledBack:
+		message = "Batch operation rolled back"
+	case result.Failed > 0:
+		message = "Batch operation partially completed"
+	}
+	response.SuccessWithMessage(c, message, result)
+}
+
+// batchItemError 将单项错误映射为HTTP状态码和错误信息，未知错误只记录日志，不返回给客户端
+func (h *UserHandler) batchItemError(c *gin.Context, item *model.UserBatchItem) (int, string) {
+	switch err := item.Err; {
+	case errors.Is(err, service.ErrUserNotFound):
+		return http.StatusNotFound, "User not found"
+	case errors.Is(err, service.ErrUserNotDeleted):
+		return http.StatusConflict, "User is not deleted"
+	case isConflict(err):
+		return http.StatusConflict, err.Error()
+	default:
+		h.log(c).WithError(err).WithField("user_id", item.ID).Error("Batch item failed")
+		return http.StatusInternalServerError, "Internal error"
+	}
+}
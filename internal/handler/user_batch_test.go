@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/internal/middleware"
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/internal/service"
+	"go-api-scaffold/pkg/logger"
+)
+
+// stubBatchService 返回固定批量操作结果的用户服务桩
+type stubBatchService struct {
+	service.UserService
+	result *model.UserBatchResult
+}
+
+func (s *stubBatchService) Batch(context.Context, *model.UserBatchRequest) (*model.UserBatchResult, error) {
+	return s.result, nil
+}
+
+func newBatchRouter(result *model.UserBatchResult) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	log := logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"})
+	handlers := New(&service.Service{}, log)
+	handlers.User = NewUserHandler(&stubBatchService{result: result}, log)
+	handlers.APIKeys = []middleware.APIKey{{Actor: "admin", Key: "secret"}}
+	router := gin.New()
+	handlers.RegisterRoutes(router)
+	return router
+}
+
+// postBatch 以认证身份调用批量接口
+func postBatch(t *testing.T, router *gin.Engine, body string) (string, model.UserBatchResult) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Message string                `json:"message"`
+		Data    model.UserBatchResult `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp.Message, resp.Data
+}
+
+func TestBatchItemErrors(t *testing.T) {
+	router := newBatchRouter(&model.UserBatchResult{
+		Operation: model.UserBatchDelete,
+		Mode:      model.UserBatchBestEffort,
+		Succeeded: 1,
+		Failed:    4,
+		Items: []model.UserBatchItem{
+			{ID: 1, Status: model.UserBatchSucceeded},
+			{ID: 2, Status: model.UserBatchFailed, Err: service.ErrUserNotFound},
+			{ID: 3, Status: model.UserBatchFailed, Err: service.ErrUserNotDeleted},
+			{ID: 4, Status: model.UserBatchFailed, Err: service.ErrEmailExists},
+			{ID: 5, Status: model.UserBatchFailed, Err: errors.New("Error 1205 (HY000): Lock wait timeout exceeded")},
+		},
+	})
+
+	message, result := postBatch(t, router, `{"operation":"delete","ids":[1,2,3,4,5],"mode":"best_effort"}`)
+	if message != "Batch operation partially completed" {
+		t.Errorf("message = %q", message)
+	}
+
+	want := []struct {
+		code  int
+		error string
+	}{
+		{0, ""},
+		{http.StatusNotFound, "User not found"},
+		{http.StatusConflict, "User is not deleted"},
+		{http.StatusConflict, service.ErrEmailExists.Error()},
+		// 数据库错误不返回给客户端
+		{http.StatusInternalServerError, "Internal error"},
+	}
+	for i, item := range result.Items {
+		if item.Code != want[i].code || item.Error != want[i].error {
+			t.Errorf("item %d = %d %q, want %d %q", item.ID, item.Code, item.Error, want[i].code, want[i].error)
+		}
+	}
+}
+
+func TestBatchRolledBack(t *testing.T) {
+	router := newBatchRouter(&model.UserBatchResult{
+		Operation:  model.UserBatchDelete,
+		Mode:       model.UserBatchAtomic,
+		RolledBack: true,
+		Failed:     1,
+		Items: []model.UserBatchItem{
+			{ID: 1, Status: model.UserBatchRolledBack},
+			{ID: 2, Status: model.UserBatchFailed, Err: service.ErrUserNotFound},
+		},
+	})
+
+	message, result := postBatch(t, router, `{"operation":"delete","ids":[1,2]}`)
+	if message != "Batch operation rolled back" {
+		t.Errorf("message = %q", message)
+	}
+	if !result.RolledBack || result.Items[0].Status != model.UserBatchRolledBack || result.Items[0].Code != 0 {
+		t.Errorf("result = %+v, want rolled back item without error", result)
+	}
+	if result.Items[1].Code != http.StatusNotFound {
+		t.Errorf("item 2 code = %d, want 404", result.Items[1].Code)
+	}
+}
@@ -0,0 +1,465 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/pkg/response"
+)
+
+// 批量导入导出的格式
+const (
+	bulkFormatCSV    = "csv"
+	bulkFormatNDJSON = "ndjson"
+	bulkFormatJSON   = "json"
+)
+
+// bulkMediaTypes 各格式对应的媒体类型
+var bulkMediaTypes = map[string]string{
+	"text/csv":             bulkFormatCSV,
+	"application/csv":      bulkFormatCSV,
+	"application/x-ndjson": bulkFormatNDJSON,
+	"application/ndjson":   bulkFormatNDJSON,
+	"application/jsonl":    bulkFormatNDJSON,
+	"application/json":     bulkFormatJSON,
+}
+
+const (
+	// maxImportBytes 导入文件的最大字节数
+	maxImportBytes = 10 << 20
+	// maxImportRows 单次导入的最大行数
+	maxImportRows = 1000
+	// exportFlushRows 导出时每输出多少行刷新一次
+	exportFlushRows = 200
+	// exportWriteTimeout 导出时每次刷新后延长的写超时，大表导出不受服务器WriteTimeout限制
+	exportWriteTimeout = 30 * time.Second
+)
+
+// requiredImportColumns CSV导入的必需列，nickname、phone可选
+var requiredImportColumns = []string{"username", "email", "password"}
+
+// exportColumns CSV导出的列
+var exportColumns = []string{"id", "username", "email", "nickname", "avatar", "phone", "status", "last_login", "created_at", "updated_at"}
+
+// Import 批量导入用户
+// @Summary 批量导入用户
+// @Description 请求体为CSV（首行为表头：username,email,password,nickname,phone）、NDJSON（每行一个用户）或JSON数组，
+// @Description 每行按创建用户的规则校验，单行失败不影响其他行；dry_run=true时只校验（含用户名、邮箱是否已存在）不写入。
+// @Description 单次最多1000行
+// @Tags 用户
+// @Accept text/csv,application/x-ndjson,json
+// @Produce json
+// @Param dry_run query bool false "试运行，只校验不写入" default(false)
+// @Param request body []object true "待导入的用户，字段同创建用户；各行在处理器中逐行校验"
+// @Param Idempotency-Key header string false "幂等键，重试时使用同一键，服务端重放首次的响应"
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.UserImportResult} "各行的导入结果"
+// @Failure 400 {object} response.Response "文件格式错误或超过行数限制"
+// @Failure 401 {object} response.Response "未认证或API密钥无效"
+// @Failure 409 {object} response.Response "同一Idempotency-Key的请求仍在处理"
+// @Failure 413 {object} response.Response "携带Idempotency-Key的请求体超过idempotency.max_body_size"
+// @Failure 415 {object} response.Response "不支持的文件格式"
+// @Failure 422 {object} response.Response "Idempotency-Key已用于不同的请求体"
+// @Router /api/v1/users/import [post]
+func (h *UserHandler) Import(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		response.BadRequest(c, "Invalid dry_run parameter")
+		return
+	}
+
+	format, ok := importFormat(c.GetHeader("Content-Type"))
+	if !ok {
+		response.Error(c, response.CodeUnsupportedMediaType,
+			"Unsupported import content type, supported: text/csv, application/x-ndjson, application/json")
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+	var records []model.UserImportRecord
+	switch format {
+	case bulkFormatCSV:
+		records, err = parseImportCSV(body)
+	case bulkFormatNDJSON:
+		records, err = parseImportNDJSON(body)
+	default:
+		records, err = parseImportJSON(body)
+	}
+	if err != nil {
+		h.log(c).WithError(err).Warn("Invalid import file")
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.BadRequest(c, fmt.Sprintf("Import file must not exceed %d bytes", maxImportBytes))
+		} else {
+			response.BadRequest(c, "Invalid import file: "+err.Error())
+		}
+		return
+	}
+	if len(records) == 0 {
+		response.BadRequest(c, "Import file contains no users")
+		return
+	}
+	if len(records) > maxImportRows {
+		response.BadRequest(c, fmt.Sprintf("Import file must not contain more than %d users", maxImportRows))
+		return
+	}
+
+	// 按创建用户的规则逐行校验
+	for i := range records {
+		if len(records[i].Errors) == 0 {
+			records[i].Errors = validationMessages(h.validator.Struct(&records[i].Request))
+		}
+	}
+
+	result, err := h.service.Import(c.Request.Context(), records, dryRun)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to import users")
+		response.ServerError(c, "Failed to import users")
+		return
+	}
+
+	message := "Import completed"
+	if dryRun {
+		message = "Dry run completed"
+	}
+	response.SuccessWithMessage(c, message, result)
+}
+
+// Export 导出全部用户
+// @Summary 导出用户
+// @Description 按ID顺序流式输出全部用户，格式由format参数或Accept头（text/csv、application/x-ndjson、application/json）决定，默认CSV
+// @Tags 用户
+// @Produce text/csv,application/x-ndjson,json
+// @Param format query string false "导出格式，优先于Accept头" Enums(csv, ndjson, json)
+// @Security BearerAuth
+// @Success 200 {array} model.UserResponse "用户列表"
+// @Failure 400 {object} response.Response "导出格式无效"
+// @Failure 401 {object} response.Response "未认证或API密钥无效"
+// @Failure 406 {object} response.Response "Accept头中没有支持的格式"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /api/v1/users/export [get]
+func (h *UserHandler) Export(c *gin.Context) {
+	c.Header("Vary", "Accept")
+	format, ok := exportFormat(c)
+	if !ok {
+		if c.Query("format") != "" {
+			response.BadRequest(c, "Invalid format parameter, supported: csv, ndjson, json")
+		} else {
+			response.Error(c, response.CodeNotAcceptable,
+				"No acceptable export format, supported: text/csv, application/x-ndjson, application/json")
+		}
+		return
+	}
+
+	w := newExportWriter(c, format)
+	err := h.service.Export(c.Request.Context(), w.write)
+	if err == nil {
+		err = w.close()
+	}
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to export users")
+		if !w.started {
+			response.ServerError(c, "Failed to export users")
+		}
+	}
+}
+
+// importFormat 按Content-Type选择导入格式，未指定时为JSON
+func importFormat(contentType string) (string, bool) {
+	if contentType == "" {
+		return bulkFormatJSON, true
+	}
+	typ, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "", false
+	}
+	format, ok := bulkMediaTypes[typ]
+	return format, ok
+}
+
+// exportFormat 按format参数或Accept头选择导出格式，都未指定时为CSV
+func exportFormat(c *gin.Context) (string, bool) {
+	if format := c.Query("format"); format != "" {
+		switch format {
+		case bulkFormatCSV, bulkFormatNDJSON, bulkFormatJSON:
+			return format, true
+		}
+		return "", false
+	}
+
+	accept := c.GetHeader("Accept")
+	if accept == "" {
+		return bulkFormatCSV, true
+	}
+	for _, part := range strings.Split(accept, ",") {
+		typ, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if format, ok := bulkMediaTypes[typ]; ok {
+			return format, true
+		}
+		if typ == "*/*" || typ == "text/*" {
+			return bulkFormatCSV, true
+		}
+	}
+	return "", false
+}
+
+// exportWriter 流式输出导出内容，第一行写出前不发送响应头，便于出错时返回错误响应
+type exportWriter struct {
+	c       *gin.Context
+	format  string
+	csv     *csv.Writer
+	rows    int
+	started bool
+}
+
+// newExportWriter 创建导出输出
+func newExportWriter(c *gin.Context, format string) *exportWriter {
+	return &exportWriter{c: c, format: format}
+}
+
+// start 输出响应头，CSV输出表头，JSON输出数组开始
+func (w *exportWriter) start() error {
+	w.started = true
+	contentType := map[string]string{
+		bulkFormatCSV:    "text/csv; charset=utf-8",
+		bulkFormatNDJSON: "application/x-ndjson",
+		bulkFormatJSON:   "application/json; charset=utf-8",
+	}[w.format]
+	w.c.Header("Content-Type", contentType)
+	w.c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="users.%s"`, w.format))
+	w.c.Status(http.StatusOK)
+	w.extendDeadline()
+
+	switch w.format {
+	case bulkFormatCSV:
+		w.csv = csv.NewWriter(w.c.Writer)
+		return w.csv.Write(exportColumns)
+	case bulkFormatJSON:
+		_, err := w.c.Writer.WriteString("[")
+		return err
+	}
+	return nil
+}
+
+// write 输出一个用户
+func (w *exportWriter) write(user *model.UserResponse) error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	switch w.format {
+	case bulkFormatCSV:
+		err = w.csv.Write(userCSVRecord(user))
+	default:
+		var data []byte
+		if data, err = json.Marshal(user); err != nil {
+			return err
+		}
+		if w.format == bulkFormatJSON && w.rows > 0 {
+			data = append([]byte(","), data...)
+		} else if w.format == bulkFormatNDJSON {
+			data = append(data, '\n')
+		}
+		_, err = w.c.Writer.Write(data)
+	}
+	if err != nil {
+		return err
+	}
+
+	w.rows++
+	if w.rows%exportFlushRows == 0 {
+		return w.flush()
+	}
+	return nil
+}
+
+// close 结束输出，没有用户时也输出CSV表头或空数组
+func (w *exportWriter) close() error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+	if w.format == bulkFormatJSON {
+		if _, err := w.c.Writer.WriteString("]"); err != nil {
+			return err
+		}
+	}
+	return w.flush()
+}
+
+// flush 将已输出的内容发送给客户端并延长写超时
+func (w *exportWriter) flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	w.c.Writer.Flush()
+	w.extendDeadline()
+	return nil
+}
+
+// extendDeadline 延长写超时，底层连接不支持时忽略
+func (w *exportWriter) extendDeadline() {
+	_ = http.NewResponseController(w.c.Writer).SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+}
+
+// userCSVRecord 用户的CSV行，时间使用RFC 3339格式，从未登录时last_login为空
+func userCSVRecord(user *model.UserResponse) []string {
+	lastLogin := ""
+	if !user.LastLogin.IsZero() {
+		lastLogin = user.LastLogin.Format(time.RFC3339)
+	}
+	return []string{
+		strconv.FormatUint(uint64(user.ID), 10),
+		csvSafe(user.Username),
+		csvSafe(user.Email),
+		csvSafe(user.Nickname),
+		csvSafe(user.Avatar),
+		csvSafe(user.Phone),
+		strconv.Itoa(user.Status),
+		lastLogin,
+		user.CreatedAt.Format(time.RFC3339),
+		user.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// csvSafe 以 = + - @ 或制表符、回车开头的值前加单引号，避免在电子表格中打开时被当作公式执行
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// parseImportCSV 解析CSV，首行为表头，列顺序不限，未知列忽略
+func parseImportCSV(r io.Reader) ([]model.UserImportRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	for _, name := range requiredImportColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	var records []model.UserImportRecord
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		// 列数不一致时仍返回该行，其他解析错误无法继续
+		if err != nil && !errors.Is(err, csv.ErrFieldCount) {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		record := model.UserImportRecord{Row: line}
+		if err != nil {
+			record.Errors = []string{fmt.Sprintf("expected %d fields, got %d", len(header), len(fields))}
+		}
+
+		value := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(fields) {
+				return strings.TrimSpace(fields[i])
+			}
+			return ""
+		}
+		record.Request = model.UserCreateRequest{
+			Username: value("username"),
+			Email:    value("email"),
+			Password: value("password"),
+			Nickname: value("nickname"),
+			Phone:    value("phone"),
+		}
+		records = append(records, record)
+	}
+}
+
+// parseImportNDJSON 解析NDJSON，空行忽略，单行JSON错误只影响该行
+func parseImportNDJSON(r io.Reader) ([]model.UserImportRecord, error) {
+	reader := bufio.NewReader(r)
+	var records []model.UserImportRecord
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		if data = bytes.TrimSpace(data); len(data) > 0 {
+			record := model.UserImportRecord{Row: line}
+			if jsonErr := json.Unmarshal(data, &record.Request); jsonErr != nil {
+				record.Errors = []string{"invalid JSON: " + jsonErr.Error()}
+			}
+			records = append(records, record)
+		}
+		if err == io.EOF {
+			return records, nil
+		}
+	}
+}
+
+// parseImportJSON 解析JSON数组，Row为数组中的序号（从1开始）
+func parseImportJSON(r io.Reader) ([]model.UserImportRecord, error) {
+	var items []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&items); err != nil {
+		return nil, err
+	}
+	records := make([]model.UserImportRecord, len(items))
+	for i, item := range items {
+		records[i].Row = i + 1
+		if err := json.Unmarshal(item, &records[i].Request); err != nil {
+			records[i].Errors = []string{"invalid JSON: " + err.Error()}
+		}
+	}
+	return records, nil
+}
+
+// validationMessages 将校验错误转换为逐字段的错误信息
+func validationMessages(err error) []string {
+	if err == nil {
+		return nil
+	}
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return []string{err.Error()}
+	}
+	messages := make([]string, len(errs))
+	for i, fe := range errs {
+		messages[i] = fmt.Sprintf("%s failed on the '%s' rule", strings.ToLower(fe.Field()), fe.Tag())
+	}
+	return messages
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go-api-scaffold/internal/model"
+)
+
+func TestCSVSafe(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"alice", "alice"},
+		{"=HYPERLINK(\"http://evil\")", "'=HYPERLINK(\"http://evil\")"},
+		{"+1 555 0100", "'+1 555 0100"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"a=1", "a=1"},
+		{"张三", "张三"},
+	}
+	for _, tt := range tests {
+		if got := csvSafe(tt.value); got != tt.want {
+			t.Errorf("csvSafe(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestUserCSVRecordEscapesFormulas(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	record := userCSVRecord(&model.UserResponse{
+		ID:        7,
+		Username:  "bob",
+		Email:     "bob@example.com",
+		Nickname:  "=cmd|' /C calc'!A0",
+		Phone:     "+8613800000000",
+		Status:    1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if record[3] != "'=cmd|' /C calc'!A0" {
+		t.Errorf("nickname = %q, want escaped", record[3])
+	}
+	if record[5] != "'+8613800000000" {
+		t.Errorf("phone = %q, want escaped", record[5])
+	}
+	if record[0] != "7" || record[1] != "bob" || record[7] != "" {
+		t.Errorf("record = %q", record)
+	}
+}
+
+func TestParseImportCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []model.UserImportRecord
+		wantErr bool
+	}{
+		{name: "empty", input: ""},
+		{
+			name:  "columns in any order with BOM and unknown column",
+			input: "\ufeffEmail, username,password,extra\nalice@example.com,alice,secret123,x\n",
+			want: []model.UserImportRecord{{Row: 2, Request: model.UserCreateRequest{
+				Username: "alice", Email: "alice@example.com", Password: "secret123",
+			}}},
+		},
+		{
+			name:  "optional columns",
+			input: "username,email,password,nickname,phone\nbob,bob@example.com,secret123,Bob,13800000000\n",
+			want: []model.UserImportRecord{{Row: 2, Request: model.UserCreateRequest{
+				Username: "bob", Email: "bob@example.com", Password: "secret123", Nickname: "Bob", Phone: "13800000000",
+			}}},
+		},
+		{
+			name:  "field count mismatch only fails the row",
+			input: "username,email,password\nalice,alice@example.com\nbob,bob@example.com,secret123\n",
+			want: []model.UserImportRecord{
+				{Row: 2, Request: model.UserCreateRequest{Username: "alice", Email: "alice@example.com"},
+					Errors: []string{"expected 3 fields, got 2"}},
+				{Row: 3, Request: model.UserCreateRequest{Username: "bob", Email: "bob@example.com", Password: "secret123"}},
+			},
+		},
+		{name: "missing required column", input: "username,email\nalice,alice@example.com\n", wantErr: true},
+		{name: "malformed quoting", input: "username,email,password\n\"alice,a@example.com,x\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseImportCSV(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseImportCSV() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseImportCSV() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseImportNDJSON(t *testing.T) {
+	input := `{"username":"alice","email":"alice@example.com","password":"secret123"}
+
+not json
+{"username":"bob","email":"bob@example.com","password":"secret123"}`
+	got, err := parseImportNDJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("parseImportNDJSON() error = %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("parseImportNDJSON() returned %d records, want 3", len(got))
+	}
+
+	// 空行跳过但计入行号，错误行不影响后续行
+	rows := []int{got[0].Row, got[1].Row, got[2].Row}
+	if !reflect.DeepEqual(rows, []int{1, 3, 4}) {
+		t.Errorf("rows = %v, want [1 3 4]", rows)
+	}
+	if got[0].Request.Username != "alice" || len(got[0].Errors) != 0 {
+		t.Errorf("record 1 = %+v", got[0])
+	}
+	if len(got[1].Errors) != 1 || !strings.HasPrefix(got[1].Errors[0], "invalid JSON: ") {
+		t.Errorf("record 3 errors = %v, want invalid JSON", got[1].Errors)
+	}
+	if got[2].Request.Username != "bob" || len(got[2].Errors) != 0 {
+		t.Errorf("record 4 = %+v", got[2])
+	}
+
+	if got, err := parseImportNDJSON(strings.NewReader("")); err != nil || len(got) != 0 {
+		t.Errorf("parseImportNDJSON(\"\") = %v, %v, want no records", got, err)
+	}
+}
+
+func TestParseImportJSON(t *testing.T) {
+	got, err := parseImportJSON(strings.NewReader(`[{"username":"alice"},"oops"]`))
+	if err != nil {
+		t.Fatalf("parseImportJSON() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Row != 1 || got[1].Row != 2 {
+		t.Fatalf("parseImportJSON() = %+v", got)
+	}
+	if got[0].Request.Username != "alice" || len(got[0].Errors) != 0 {
+		t.Errorf("record 1 = %+v", got[0])
+	}
+	if len(got[1].Errors) != 1 || !strings.HasPrefix(got[1].Errors[0], "invalid JSON: ") {
+		t.Errorf("record 2 errors = %v, want invalid JSON", got[1].Errors)
+	}
+
+	if _, err := parseImportJSON(strings.NewReader(`{"username":"alice"}`)); err == nil {
+		t.Error("parseImportJSON(object) error = nil, want error")
+	}
+}
@@ -0,0 +1,85 @@
+package handler
+
+// v2用户接口与v1共用 UserHandler 的处理方法，仅响应结构不同（model.UserResponseV2），
+// swag按函数生成文档，因此在这里单独声明v2的接口文档
+
+// createUserV2 创建用户（v2）
+// @Summary 创建用户
+// @Tags 用户 v2
+// @Accept json
+// @Produce json
+// @Param request body model.UserCreateRequest true "用户信息"
+// @Param Idempotency-Key header string false "幂等键，重试时使用同一键，服务端重放首次的响应"
+// @Success 200 {object} response.Response{data=model.UserResponseV2} "创建成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 409 {object} response.Response "用户名或邮箱已存在，或同一Idempotency-Key的请求仍在处理"
+// @Failure 413 {object} response.Response "携带Idempotency-Key的请求体超过idempotency.max_body_size"
+// @Failure 422 {object} response.Response "Idempotency-Key已用于不同的请求体"
+// @Router /api/v2/users/ [post]
+func createUserV2() {}
+
+// getUserV2 获取用户详情（v2）
+// @Summary 获取用户详情
+// @Description 响应携带ETag与Last-Modified，支持If-None-Match/If-Modified-Since条件请求
+// @Tags 用户 v2
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=model.UserResponseV2} "用户信息"
+// @Success 304 "未修改"
+// @Failure 400 {object} response.Response "ID格式错误"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Router /api/v2/users/{id} [get]
+func getUserV2() {}
+
+// updateUserV2 更新用户（v2）
+// @Summary 更新用户
+// @Tags 用户 v2
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param request body model.UserUpdateRequest true "需要更新的字段"
+// @Success 200 {object} response.Response{data=model.UserResponseV2} "更新成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 409 {object} response.Response "用户名或邮箱已存在"
+// @Router /api/v2/users/{id} [put]
+func updateUserV2() {}
+
+// deleteUserV2 删除用户（v2）
+// @Summary 删除用户
+// @Tags 用户 v2
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response "删除成功"
+// @Failure 400 {object} response.Response "ID格式错误"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Router /api/v2/users/{id} [delete]
+func deleteUserV2() {}
+
+// listUsersV2 获取用户列表（v2）
+// @Summary 获取用户列表
+// @Tags 用户 v2
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量，最大100" default(10)
+// @Success 200 {object} response.PageResponse{data=[]model.UserResponseV2} "用户列表"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /api/v2/users/ [get]
+func listUsersV2() {}
+
+// batchUsersV2 批量操作用户（v2）
+// @Summary 批量操作用户
+// @Description 与v1相同，对多个用户执行set_status、delete或restore，mode为atomic（默认）或best_effort
+// @Tags 用户 v2
+// @Accept json
+// @Produce json
+// @Param request body model.UserBatchRequest true "操作类型、用户ID列表和执行模式"
+// @Param Idempotency-Key header string false "幂等键，重试时使用同一键，服务端重放首次的响应"
+// @Success 200 {object} response.Response{data=model.UserBatchResult} "各用户的操作结果"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 409 {object} response.Response "同一Idempotency-Key的请求仍在处理"
+// @Failure 413 {object} response.Response "携带Idempotency-Key的请求体超过idempotency.max_body_size"
+// @Failure 422 {object} response.Response "Idempotency-Key已用于不同的请求体"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /api/v2/users/batch [post]
+func batchUsersV2() {}
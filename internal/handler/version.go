@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/pkg/apiversion"
+)
+
+// SupportedVersions 服务端实现的接口版本
+var SupportedVersions = []int{1, 2}
+
+// VersionOptions 根据配置生成接口版本选项
+func VersionOptions(cfg config.APIConfig) (apiversion.Options, error) {
+	opts := apiversion.Options{
+		MediaType: cfg.MediaType,
+		Default:   cfg.DefaultVersion,
+	}
+	for _, number := range SupportedVersions {
+		v := cfg.Version(number)
+		opts.Versions = append(opts.Versions, apiversion.Version{
+			Number:     number,
+			Deprecated: v.Deprecated,
+			Sunset:     v.Sunset,
+			Link:       v.Link,
+		})
+	}
+	if err := opts.Validate(); err != nil {
+		return apiversion.Options{}, err
+	}
+	return opts, nil
+}
+
+// userViews 用户响应的版本转换，v1直接返回 model.UserResponse
+var userViews = apiversion.NewMapper[*model.UserResponse]().
+	Register(2, func(user *model.UserResponse) interface{} {
+		return model.NewUserResponseV2(user)
+	})
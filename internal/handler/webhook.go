@@ -0,0 +1,303 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/internal/service"
+	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/request"
+	"go-api-scaffold/pkg/response"
+)
+
+// WebhookHandler Webhook订阅处理器
+type WebhookHandler struct {
+	service   service.WebhookService
+	logger    logger.Logger
+	validator *validator.Validate
+}
+
+// NewWebhookHandler 创建Webhook订阅处理器实例
+func NewWebhookHandler(service service.WebhookService, logger logger.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		service:   service,
+		logger:    logger,
+		validator: validator.New(),
+	}
+}
+
+// Create 创建订阅
+// @Summary 创建Webhook订阅
+// @Description 未提供secret时自动生成，secret仅在创建时返回一次
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param request body model.WebhookCreateRequest true "订阅信息"
+// @Param Idempotency-Key header string false "幂等键，重试时使用同一键，服务端重放首次的响应"
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.WebhookResponse} "创建成功"
+// @Failure 400 {object} response.Response "参数错误、事件名无效或地址不允许（须为https且不能指向内网地址）"
+// @Failure 401 {object} response.Response "未认证或API密钥无效"
+// @Failure 409 {object} response.Response "同一Idempotency-Key的请求仍在处理"
+// @Failure 413 {object} response.Response "携带Idempotency-Key的请求体超过idempotency.max_body_size"
+// @Failure 422 {object} response.Response "Idempotency-Key已用于不同的请求体"
+// @Router /api/v1/webhooks/ [post]
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req model.WebhookCreateRequest
+	if err := request.Bind(c, &req); err != nil {
+		h.log(c).WithError(err).Error("Invalid request body")
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	// 验证请求参数
+	if err := h.validator.Struct(&req); err != nil {
+		h.log(c).WithError(err).Error("Validation failed")
+		response.BadRequest(c, "Validation failed: "+err.Error())
+		return
+	}
+
+	webhook, err := h.service.Create(c.Request.Context(), &req)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to create webhook")
+		h.fail(c, err, "Failed to create webhook")
+		return
+	}
+
+	response.SuccessWithMessage(c, "Webhook created successfully", webhook)
+}
+
+// GetByID 根据ID获取订阅
+// @Summary 获取Webhook订阅
+// @Tags Webhook
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.WebhookResponse} "订阅信息"
+// @Failure 401 {object} response.Response "未认证或API密钥无效"
+// @Failure 404 {object} response.Response "订阅不存在"
+// @Router /api/v1/webhooks/{id} [get]
+func (h *WebhookHandler) GetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid webhook ID")
+		return
+	}
+
+	webhook, err := h.service.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to get webhook")
+		h.fail(c, err, "Failed to get webhook")
+		return
+	}
+
+	response.Success(c, webhook)
+}
+
+// Update 更新订阅
+// @Summary 更新Webhook订阅
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Param request body model.WebhookUpdateRequest true "需要更新的字段"
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.WebhookResponse} "更新成功"
+// @Failure 400 {object} response.Response "参数错误、事件名无效或地址不允许（须为https且不能指向内网地址）"
+// @Failure 401 {object} response.Response "未认证或API密钥无效"
+// @Failure 404 {object} response.Response "订阅不存在"
+// @Router /api/v1/webhooks/{id} [put]
+func (h *WebhookHandler) Update(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid webhook ID")
+		return
+	}
+
+	var req model.WebhookUpdateRequest
+	if err := request.Bind(c, &req); err != nil {
+		h.log(c).WithError(err).Error("Invalid request body")
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	// 验证请求参数
+	if err := h.validator.Struct(&req); err != nil {
+		h.log(c).WithError(err).Error("Validation failed")
+		response.BadRequest(c, "Validation failed: "+err.Error())
+		return
+	}
+
+	webhook, err := h.service.Update(c.Request.Context(), uint(id), &req)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to update webhook")
+		h.fail(c, err, "Failed to update webhook")
+		return
+	}
+
+	response.SuccessWithMessage(c, "Webhook updated successfully", webhook)
+}
+
+// Delete 删除订阅
+// @Summary 删除Webhook订阅
+// @Tags Webhook
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Security BearerAuth
+// @Success 200 {object} response.Response "删除成功"
+// @Failure 401 {object} response.Response "未认证或API密钥无效"
+// @Failure 404 {object} response.Response "订阅不存在"
+// @Router /api/v1/webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid webhook ID")
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), uint(id)); err != nil {
+		h.log(c).WithError(err).Error("Failed to delete webhook")
+		h.fail(c, err, "Failed to delete webhook")
+		return
+	}
+
+	response.SuccessWithMessage(c, "Webhook deleted successfully", nil)
+}
+
+// List 获取订阅列表
+// @Summary 获取Webhook订阅列表
+// @Tags Webhook
+// @Produce json
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量，最大100" default(20)
+// @Security BearerAuth
+// @Success 200 {object} response.PageResponse{data=[]model.WebhookResponse} "订阅列表"
+// @Failure 401 {object} response.Response "未认证或API密钥无效"
+// @Router /api/v1/webhooks/ [get]
+func (h *WebhookHandler) List(c *gin.Context) {
+	// 获取分页参数
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	// 限制分页大小
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	webhooks, meta, err := h.service.List(c.Request.Context(), page, pageSize)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to get webhook list")
+		response.ServerError(c, "Failed to get webhook list")
+		return
+	}
+
+	response.SuccessPage(c, webhooks, *meta)
+}
+
+// ListDeliveries 获取投递历史，支持按status过滤
+// @Summary 获取Webhook投递历史
+// @Tags Webhook
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Param status query string false "投递状态" Enums(pending, succeeded, dead)
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量，最大100" default(20)
+// @Security BearerAuth
+// @Success 200 {object} response.PageResponse{data=[]model.WebhookDelivery} "投递记录"
+// @Failure 400 {object} response.Response "状态无效"
+// @Failure 401 {object} response.Response "未认证或API密钥无效"
+// @Failure 404 {object} response.Response "订阅不存在"
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid webhook ID")
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", model.WebhookDeliveryPending, model.WebhookDeliverySucceeded, model.WebhookDeliveryDead:
+	default:
+		response.BadRequest(c, "Invalid delivery status")
+		return
+	}
+
+	// 获取分页参数
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	// 限制分页大小
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	deliveries, meta, err := h.service.ListDeliveries(c.Request.Context(), uint(id), status, page, pageSize)
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to get webhook deliveries")
+		h.fail(c, err, "Failed to get webhook deliveries")
+		return
+	}
+
+	response.SuccessPage(c, deliveries, *meta)
+}
+
+// Redeliver 手动重新投递
+// @Summary 重新投递Webhook
+// @Tags Webhook
+// @Produce json
+// @Param id path int true "订阅ID"
+// @Param delivery_id path int true "投递记录ID"
+// @Param Idempotency-Key header string false "幂等键，重试时使用同一键，服务端重放首次的响应"
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=model.WebhookDelivery} "已重新排队"
+// @Failure 401 {object} response.Response "未认证或API密钥无效"
+// @Failure 404 {object} response.Response "订阅或投递记录不存在"
+// @Failure 409 {object} response.Response "同一Idempotency-Key的请求仍在处理"
+// @Failure 413 {object} response.Response "携带Idempotency-Key的请求体超过idempotency.max_body_size"
+// @Failure 422 {object} response.Response "Idempotency-Key已用于不同的请求体"
+// @Router /api/v1/webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid webhook ID")
+		return
+	}
+	deliveryID, err := strconv.ParseUint(c.Param("delivery_id"), 10, 32)
+	if err != nil {
+		response.BadRequest(c, "Invalid delivery ID")
+		return
+	}
+
+	delivery, err := h.service.Redeliver(c.Request.Context(), uint(id), uint(deliveryID))
+	if err != nil {
+		h.log(c).WithError(err).Error("Failed to redeliver webhook")
+		h.fail(c, err, "Failed to redeliver webhook")
+		return
+	}
+
+	response.SuccessWithMessage(c, "Webhook delivery scheduled", delivery)
+}
+
+// fail 将业务错误映射为HTTP响应
+func (h *WebhookHandler) fail(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, service.ErrWebhookNotFound):
+		response.NotFound(c, "Webhook not found")
+	case errors.Is(err, service.ErrDeliveryNotFound):
+		response.NotFound(c, "Webhook delivery not found")
+	case errors.Is(err, service.ErrInvalidWebhookEvent), errors.Is(err, service.ErrInvalidWebhookURL):
+		response.BadRequest(c, err.Error())
+	default:
+		response.ServerError(c, message)
+	}
+}
+
+// log 返回携带请求上下文字段的日志实例
+func (h *WebhookHandler) log(c *gin.Context) logger.Logger {
+	return h.logger.WithContext(c.Request.Context())
+}
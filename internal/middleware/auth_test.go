@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api-scaffold/pkg/logger"
+)
+
+func TestAuthenticate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	keys := []APIKey{{Actor: "ops", Key: "ops-secret"}, {Actor: "ci", Key: "ci-secret"}}
+	r.Use(Authenticate(keys))
+	actor := func(c *gin.Context) {
+		c.String(http.StatusOK, logger.UserIDFromContext(c.Request.Context()))
+	}
+	r.GET("/public", actor)
+	r.GET("/private", RequireAuth(), actor)
+
+	tests := []struct {
+		name          string
+		path          string
+		authorization string
+		wantStatus    int
+		wantActor     string
+	}{
+		{"anonymous public", "/public", "", http.StatusOK, ""},
+		{"anonymous private", "/private", "", http.StatusUnauthorized, ""},
+		{"valid key", "/private", "Bearer ci-secret", http.StatusOK, "ci"},
+		{"valid key on public route", "/public", "Bearer ops-secret", http.StatusOK, "ops"},
+		{"invalid key", "/public", "Bearer wrong", http.StatusUnauthorized, ""},
+		{"key prefix", "/private", "Bearer ops", http.StatusUnauthorized, ""},
+		{"not bearer", "/private", "Basic b3BzOm9wcy1zZWNyZXQ=", http.StatusUnauthorized, ""},
+		{"empty bearer", "/private", "Bearer ", http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if w.Code == http.StatusUnauthorized {
+				if w.Header().Get("WWW-Authenticate") == "" {
+					t.Fatal("WWW-Authenticate header missing on 401")
+				}
+				return
+			}
+			if got := w.Body.String(); got != tt.wantActor {
+				t.Fatalf("actor = %q, want %q", got, tt.wantActor)
+			}
+		})
+	}
+}
+
+func TestRequireAuthWithoutKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/private", Authenticate(nil), RequireAuth(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/private", nil)
+	req.Header.Set("Authorization", "Bearer anything")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", w.Code)
+	}
+}
@@ -15,8 +15,8 @@ func CORS() gin.HandlerFunc {
 		// 设置允许的请求头
 		c.Header("Access-Control-Allow-Origin", origin)
 		c.Header("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin,X-Requested-With,Content-Type,Accept,Authorization,Cache-Control,X-File-Name")
-		c.Header("Access-Control-Expose-Headers", "Content-Length,Access-Control-Allow-Origin,Access-Control-Allow-Headers,Content-Type")
+		c.Header("Access-Control-Allow-Headers", "Origin,X-Requested-With,X-Request-ID,Content-Type,Accept,Authorization,Cache-Control,X-File-Name,Idempotency-Key")
+		c.Header("Access-Control-Expose-Headers", "Content-Length,Access-Control-Allow-Origin,Access-Control-Allow-Headers,Content-Type,X-Request-ID,API-Version,Deprecation,Sunset,Link,Idempotent-Replayed,Traceparent")
 		c.Header("Access-Control-Allow-Credentials", "true")
 
 		// 处理预检请求
@@ -0,0 +1,243 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go-api-scaffold/pkg/cache"
+)
+
+// CachePolicy 路由级HTTP缓存策略，在注册路由时声明
+type CachePolicy struct {
+	MaxAge  time.Duration // Cache-Control max-age
+	Public  bool          // public允许共享缓存（CDN、网关）存储，否则为private
+	NoCache bool          // 要求客户端每次使用前重新验证
+	Vary    []string      // 影响响应内容的请求头
+	Store   cache.Store   // 非nil且Public时在服务端共享缓存中保存完整响应
+}
+
+// cachedResponse 共享缓存中保存的响应
+type cachedResponse struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// cachedHeaders 需要随响应一起缓存的响应头
+var cachedHeaders = []string{"Content-Type", "Content-Language", "Last-Modified"}
+
+// HTTPCache HTTP响应缓存中间件
+// 为GET/HEAD的200响应计算弱ETag并设置Cache-Control，处理If-None-Match/If-Modified-Since条件请求
+// 响应格式按Accept协商，因此Accept始终作为Vary请求头参与共享缓存键
+func HTTPCache(policy CachePolicy) gin.HandlerFunc {
+	cacheControl := policy.cacheControl()
+	vary := policy.vary()
+	shared := policy.Store != nil && policy.Public
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		key := ""
+		if shared {
+			key = responseCacheKey(c.Request, vary)
+			if data, ok, err := policy.Store.Get(c.Request.Context(), key); err == nil && ok {
+				var resp cachedResponse
+				if json.Unmarshal(data, &resp) == nil {
+					c.Header("X-Cache", "HIT")
+					writeCachedResponse(c, &resp, cacheControl, vary)
+					c.Abort()
+					return
+				}
+			}
+		}
+
+		// 缓冲响应以便计算ETag
+		original := c.Writer
+		buffered := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = buffered
+		c.Next()
+		c.Writer = original
+
+		if buffered.status != http.StatusOK {
+			original.WriteHeader(buffered.status)
+			original.WriteHeaderNow()
+			_, _ = original.Write(buffered.body.Bytes())
+			return
+		}
+
+		resp := &cachedResponse{
+			Status: buffered.status,
+			Header: http.Header{},
+			Body:   buffered.body.Bytes(),
+		}
+		for _, name := range cachedHeaders {
+			if v := original.Header().Get(name); v != "" {
+				resp.Header.Set(name, v)
+			}
+		}
+		resp.Header.Set("ETag", weakETag(resp.Body))
+
+		if shared && policy.MaxAge > 0 {
+			if data, err := json.Marshal(resp); err == nil {
+				_ = policy.Store.Set(c.Request.Context(), key, data, policy.MaxAge)
+			}
+			c.Header("X-Cache", "MISS")
+		}
+
+		writeCachedResponse(c, resp, cacheControl, vary)
+	}
+}
+
+// cacheControl 生成Cache-Control头
+func (p CachePolicy) cacheControl() string {
+	directives := []string{"private"}
+	if p.Public {
+		directives[0] = "public"
+	}
+	if p.NoCache {
+		directives = append(directives, "no-cache")
+	}
+	directives = append(directives, "max-age="+strconv.Itoa(int(p.MaxAge/time.Second)))
+	return strings.Join(directives, ", ")
+}
+
+// vary 策略声明的Vary请求头，未声明Accept时补上
+func (p CachePolicy) vary() []string {
+	for _, name := range p.Vary {
+		if strings.EqualFold(name, "Accept") {
+			return p.Vary
+		}
+	}
+	return append([]string{"Accept"}, p.Vary...)
+}
+
+// writeCachedResponse 输出响应，满足条件请求时返回304
+func writeCachedResponse(c *gin.Context, resp *cachedResponse, cacheControl string, vary []string) {
+	header := c.Writer.Header()
+	for name, values := range resp.Header {
+		header[name] = values
+	}
+	header.Set("Cache-Control", cacheControl)
+	for _, name := range vary {
+		addVary(header, name)
+	}
+
+	if notModified(c.Request, resp.Header) {
+		// 304响应不应携带实体相关头
+		header.Del("Content-Type")
+		header.Del("Content-Length")
+		c.Writer.WriteHeader(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+
+	c.Writer.WriteHeader(resp.Status)
+	if c.Request.Method == http.MethodHead {
+		c.Writer.WriteHeaderNow()
+		return
+	}
+	_, _ = c.Writer.Write(resp.Body)
+}
+
+// notModified 判断条件请求是否命中，If-None-Match优先于If-Modified-Since
+func notModified(r *http.Request, header http.Header) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		etag := strings.TrimPrefix(header.Get("ETag"), "W/")
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	ims := r.Header.Get("If-Modified-Since")
+	lastModified := header.Get("Last-Modified")
+	if ims == "" || lastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(ims)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.Truncate(time.Second).After(since)
+}
+
+// weakETag 根据响应体计算弱ETag
+func weakETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// responseCacheKey 共享缓存键：路径、排序后的查询参数及Vary请求头
+func responseCacheKey(r *http.Request, vary []string) string {
+	var b strings.Builder
+	b.WriteString(r.URL.Path)
+	b.WriteString("?")
+	b.WriteString(r.URL.Query().Encode())
+	for _, name := range vary {
+		b.WriteString("|")
+		b.WriteString(strings.ToLower(name))
+		b.WriteString("=")
+		b.WriteString(r.Header.Get(name))
+	}
+	return b.String()
+}
+
+// bufferedWriter 缓冲响应体和状态码，由中间件统一输出
+type bufferedWriter struct {
+	gin.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader 记录状态码
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+// WriteHeaderNow 延迟到中间件输出
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+// Write 写入缓冲区
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+// WriteString 写入缓冲区
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+// Flush 缓冲期间不向客户端输出，避免提前写出状态码和响应头
+func (w *bufferedWriter) Flush() {}
+
+// Status 返回记录的状态码
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+// Size 返回已缓冲的字节数
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+// Written 是否已写入内容
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0
+}
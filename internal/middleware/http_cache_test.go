@@ -0,0 +1,238 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api-scaffold/pkg/cache"
+)
+
+// lastModified 测试资源的最后修改时间
+var lastModified = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+// newCacheRouter 注册带缓存策略的测试路由，calls统计处理器执行次数
+func newCacheRouter(policy CachePolicy, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	item := func(c *gin.Context) {
+		*calls++
+		c.Header("Last-Modified", lastModified.Format(http.TimeFormat))
+		c.Header("X-Handler", "item")
+		c.JSON(http.StatusOK, gin.H{"q": c.Request.URL.RawQuery, "lang": c.GetHeader("Accept-Language")})
+	}
+	r.Match([]string{http.MethodGet, http.MethodHead}, "/items", HTTPCache(policy), item)
+	r.GET("/missing", HTTPCache(policy), func(c *gin.Context) {
+		*calls++
+		c.JSON(http.StatusNotFound, gin.H{"message": "not found"})
+	})
+	return r
+}
+
+func doCache(r *gin.Engine, method, target string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestHTTPCacheETag(t *testing.T) {
+	var calls int
+	r := newCacheRouter(CachePolicy{MaxAge: time.Minute, NoCache: true, Vary: []string{"Accept-Language"}}, &calls)
+
+	first := doCache(r, http.MethodGet, "/items", nil)
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || !strings.HasPrefix(etag, `W/"`) {
+		t.Fatalf("status = %d, ETag = %q, want 200 with weak ETag", first.Code, etag)
+	}
+	if got := first.Header().Get("Cache-Control"); got != "private, no-cache, max-age=60" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+	if got := first.Header().Values("Vary"); strings.Join(got, ",") != "Accept,Accept-Language" {
+		t.Errorf("Vary = %v, want Accept and Accept-Language", got)
+	}
+
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		want        int
+	}{
+		{"weak match", etag, http.StatusNotModified},
+		{"strong form matches weakly", strings.TrimPrefix(etag, "W/"), http.StatusNotModified},
+		{"one of several", `"other", ` + etag, http.StatusNotModified},
+		{"wildcard", "*", http.StatusNotModified},
+		{"mismatch", `W/"other"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doCache(r, http.MethodGet, "/items", map[string]string{"If-None-Match": tt.ifNoneMatch})
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if w.Code != http.StatusNotModified {
+				return
+			}
+			// 304保留校验器和缓存头，去掉实体头和响应体
+			if w.Body.Len() != 0 || w.Header().Get("Content-Type") != "" || w.Header().Get("Content-Length") != "" {
+				t.Errorf("304 body = %q, Content-Type = %q, want empty entity", w.Body.String(), w.Header().Get("Content-Type"))
+			}
+			if w.Header().Get("ETag") != etag || w.Header().Get("Cache-Control") == "" {
+				t.Errorf("304 headers = %v, want ETag and Cache-Control", w.Header())
+			}
+		})
+	}
+}
+
+func TestHTTPCacheIfModifiedSince(t *testing.T) {
+	var calls int
+	r := newCacheRouter(CachePolicy{MaxAge: time.Minute}, &calls)
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   int
+	}{
+		{"not modified", map[string]string{"If-Modified-Since": lastModified.Format(http.TimeFormat)}, http.StatusNotModified},
+		{"later", map[string]string{"If-Modified-Since": lastModified.Add(time.Hour).Format(http.TimeFormat)}, http.StatusNotModified},
+		{"modified", map[string]string{"If-Modified-Since": lastModified.Add(-time.Hour).Format(http.TimeFormat)}, http.StatusOK},
+		{"invalid date", map[string]string{"If-Modified-Since": "yesterday"}, http.StatusOK},
+		// If-None-Match优先，不匹配时忽略If-Modified-Since
+		{"If-None-Match wins", map[string]string{
+			"If-None-Match":     `W/"other"`,
+			"If-Modified-Since": lastModified.Format(http.TimeFormat),
+		}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := doCache(r, http.MethodGet, "/items", tt.header)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d", w.Code, tt.want)
+			}
+			if got := w.Header().Get("Last-Modified"); got != lastModified.Format(http.TimeFormat) {
+				t.Errorf("Last-Modified = %q", got)
+			}
+		})
+	}
+}
+
+func TestHTTPCacheNonOK(t *testing.T) {
+	var calls int
+	r := newCacheRouter(CachePolicy{MaxAge: time.Minute, Public: true, Store: cache.NewMemoryStore(10)}, &calls)
+
+	for i := 0; i < 2; i++ {
+		w := doCache(r, http.MethodGet, "/missing", map[string]string{"If-None-Match": "*"})
+		if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "not found") {
+			t.Fatalf("status = %d, body = %q, want 404 passed through", w.Code, w.Body.String())
+		}
+		if w.Header().Get("ETag") != "" || w.Header().Get("Cache-Control") != "" || w.Header().Get("X-Cache") != "" {
+			t.Errorf("headers = %v, want no caching headers on 404", w.Header())
+		}
+	}
+	if calls != 2 {
+		t.Errorf("handler calls = %d, want 404 never served from cache", calls)
+	}
+}
+
+func TestHTTPCacheHead(t *testing.T) {
+	var calls int
+	r := newCacheRouter(CachePolicy{MaxAge: time.Minute}, &calls)
+
+	get := doCache(r, http.MethodGet, "/items", nil)
+	head := doCache(r, http.MethodHead, "/items", nil)
+	if head.Code != http.StatusOK || head.Body.Len() != 0 {
+		t.Fatalf("HEAD status = %d, body = %q, want 200 without body", head.Code, head.Body.String())
+	}
+	if head.Header().Get("ETag") != get.Header().Get("ETag") || head.Header().Get("Content-Type") == "" {
+		t.Errorf("HEAD headers = %v, want the same ETag and entity headers as GET", head.Header())
+	}
+
+	w := doCache(r, http.MethodHead, "/items", map[string]string{"If-None-Match": get.Header().Get("ETag")})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("conditional HEAD status = %d, want 304", w.Code)
+	}
+}
+
+func TestHTTPCacheShared(t *testing.T) {
+	var calls int
+	r := newCacheRouter(CachePolicy{
+		MaxAge: time.Minute,
+		Public: true,
+		Vary:   []string{"Accept-Language"},
+		Store:  cache.NewMemoryStore(10),
+	}, &calls)
+
+	steps := []struct {
+		target string
+		lang   string
+		want   string
+		calls  int
+	}{
+		{"/items?b=2&a=1", "en", "MISS", 1},
+		// 查询参数排序后参与缓存键
+		{"/items?a=1&b=2", "en", "HIT", 1},
+		{"/items?a=2", "en", "MISS", 2},
+		// Vary请求头不同视为不同的响应
+		{"/items?a=1&b=2", "zh", "MISS", 3},
+		{"/items?b=2&a=1", "zh", "HIT", 3},
+		{"/items?b=2&a=1", "en", "HIT", 3},
+	}
+	var first *httptest.ResponseRecorder
+	for i, step := range steps {
+		w := doCache(r, http.MethodGet, step.target, map[string]string{"Accept-Language": step.lang})
+		if w.Code != http.StatusOK {
+			t.Fatalf("step %d: status = %d", i, w.Code)
+		}
+		if got := w.Header().Get("X-Cache"); got != step.want || calls != step.calls {
+			t.Errorf("step %d %s %s: X-Cache = %s, handler calls = %d, want %s and %d",
+				i, step.target, step.lang, got, calls, step.want, step.calls)
+		}
+		if i == 0 {
+			first = w
+		}
+	}
+
+	// 命中时恢复缓存的响应体和实体头，处理器设置的其他头不缓存
+	hit := doCache(r, http.MethodGet, "/items?a=1&b=2", map[string]string{"Accept-Language": "en"})
+	if hit.Body.String() != first.Body.String() || hit.Header().Get("ETag") != first.Header().Get("ETag") {
+		t.Errorf("HIT body = %q, want %q", hit.Body.String(), first.Body.String())
+	}
+	if hit.Header().Get("Content-Type") != first.Header().Get("Content-Type") || hit.Header().Get("Last-Modified") == "" {
+		t.Errorf("HIT headers = %v, want cached entity headers", hit.Header())
+	}
+	if hit.Header().Get("X-Handler") != "" {
+		t.Error("uncached handler header replayed on HIT")
+	}
+	if got := hit.Header().Get("Cache-Control"); got != "public, max-age=60" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+
+	// 共享缓存命中同样处理条件请求
+	w := doCache(r, http.MethodGet, "/items?a=1&b=2", map[string]string{
+		"Accept-Language": "en",
+		"If-None-Match":   first.Header().Get("ETag"),
+	})
+	if w.Code != http.StatusNotModified || w.Header().Get("X-Cache") != "HIT" {
+		t.Errorf("status = %d, X-Cache = %s, want 304 HIT", w.Code, w.Header().Get("X-Cache"))
+	}
+}
+
+func TestHTTPCachePrivateNotShared(t *testing.T) {
+	var calls int
+	r := newCacheRouter(CachePolicy{MaxAge: time.Minute, Store: cache.NewMemoryStore(10)}, &calls)
+
+	for i := 0; i < 2; i++ {
+		if w := doCache(r, http.MethodGet, "/items", nil); w.Header().Get("X-Cache") != "" {
+			t.Errorf("X-Cache = %q, want private responses kept out of the shared cache", w.Header().Get("X-Cache"))
+		}
+	}
+	if calls != 2 {
+		t.Errorf("handler calls = %d, want 2", calls)
+	}
+}
@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api-scaffold/pkg/idempotency"
+	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/response"
+)
+
+// 幂等键相关请求头
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	HeaderReplayed       = "Idempotent-Replayed"
+)
+
+const (
+	// maxIdempotencyKeyLength 幂等键最大长度
+	maxIdempotencyKeyLength = 255
+	// defaultIdempotencyBodyBytes 未配置时请求体的最大字节数
+	defaultIdempotencyBodyBytes = 10 << 20
+)
+
+// replayedHeaders 需要随响应一起保存并在重放时输出的响应头
+var replayedHeaders = []string{"Content-Type", "Content-Language", "Location"}
+
+// IdempotencyOptions 幂等键中间件选项，Store为nil时不启用
+type IdempotencyOptions struct {
+	Store   idempotency.Store
+	TTL     time.Duration // 响应保存时长
+	LockTTL time.Duration // 处理中锁的最长时间
+	// MaxBodyBytes 请求体最大字节数，请求体需读入内存计算指纹，超过时返回413；为0时使用10MB
+	MaxBodyBytes int64
+}
+
+// Idempotency 幂等键中间件，用于POST等非幂等接口
+// 请求携带Idempotency-Key时按用户（匿名请求不区分调用方）、路由和键保存响应，重试时重放保存的响应并设置Idempotent-Replayed头；
+// 同一键的请求体不一致时返回422，前一个请求仍在处理时返回409。5xx响应不保存，客户端可以使用同一键重试
+func Idempotency(opts IdempotencyOptions, log logger.Logger) gin.HandlerFunc {
+	if opts.Store == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = defaultIdempotencyBodyBytes
+	}
+	log = log.WithField("component", "idempotency")
+
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderIdempotencyKey)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			response.BadRequest(c, "Idempotency-Key must not exceed 255 characters")
+			c.Abort()
+			return
+		}
+
+		body, err := readBody(c, opts.MaxBodyBytes)
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				response.RequestTooLarge(c, fmt.Sprintf("Request body must not exceed %d bytes", opts.MaxBodyBytes))
+			} else {
+				response.BadRequest(c, "Failed to read request body")
+			}
+			c.Abort()
+			return
+		}
+
+		ctx := c.Request.Context()
+		rec := &idempotency.Record{
+			Key:         idempotency.Key(idempotencyScope(c), c.Request.Method+" "+c.FullPath(), key),
+			Fingerprint: idempotency.Fingerprint(body),
+			Token:       idempotency.NewToken(),
+		}
+		existing, reserved, err := opts.Store.Reserve(ctx, rec, opts.LockTTL)
+		if err != nil {
+			log.WithContext(ctx).WithField(logger.FieldError, err.Error()).Error("Failed to reserve idempotency key")
+			response.ServerError(c, "Failed to process Idempotency-Key")
+			c.Abort()
+			return
+		}
+		if !reserved {
+			switch {
+			case existing.Fingerprint != rec.Fingerprint:
+				response.UnprocessableEntity(c, "Idempotency-Key has already been used with a different request body")
+			case !existing.Completed:
+				c.Header("Retry-After", "1")
+				response.Conflict(c, "A request with this Idempotency-Key is still being processed")
+			default:
+				replay(c, existing)
+			}
+			c.Abort()
+			return
+		}
+
+		// 处理器panic或返回5xx时释放锁，允许重试
+		completed := false
+		defer func() {
+			if !completed {
+				if err := opts.Store.Release(ctx, rec); err != nil {
+					log.WithContext(ctx).WithField(logger.FieldError, err.Error()).Warn("Failed to release idempotency key")
+				}
+			}
+		}()
+
+		original := c.Writer
+		buffered := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = buffered
+		c.Next()
+		c.Writer = original
+
+		if buffered.status < http.StatusInternalServerError {
+			rec.Status = buffered.status
+			rec.Header = http.Header{}
+			for _, name := range replayedHeaders {
+				if v := original.Header().Get(name); v != "" {
+					rec.Header.Set(name, v)
+				}
+			}
+			rec.Body = buffered.body.Bytes()
+			err := opts.Store.Complete(ctx, rec, opts.TTL)
+			completed = err == nil
+			if err != nil {
+				entry := log.WithContext(ctx).WithField(logger.FieldError, err.Error())
+				if errors.Is(err, idempotency.ErrLockLost) {
+					entry.Warn("Request took longer than the idempotency lock")
+				} else {
+					entry.Error("Failed to save idempotent response")
+				}
+			}
+		}
+
+		original.WriteHeader(buffered.status)
+		original.WriteHeaderNow()
+		_, _ = original.Write(buffered.body.Bytes())
+	}
+}
+
+// replay 输出保存的响应
+func replay(c *gin.Context, rec *idempotency.Record) {
+	header := c.Writer.Header()
+	for name, values := range rec.Header {
+		header[name] = values
+	}
+	header.Set(HeaderReplayed, "true")
+	c.Writer.WriteHeader(rec.Status)
+	c.Writer.WriteHeaderNow()
+	_, _ = c.Writer.Write(rec.Body)
+}
+
+// idempotencyScope 幂等键的隔离范围：认证请求为当前用户，不同用户使用相同的键不会读到对方的响应
+// 匿名请求只按接口和键隔离，不绑定客户端IP，否则经NAT、代理或移动网络重试时IP变化会导致重复执行；
+// 因此匿名客户端应使用UUID等不可猜测的键
+func idempotencyScope(c *gin.Context) string {
+	if id := logger.UserIDFromContext(c.Request.Context()); id != "" {
+		return "user:" + id
+	}
+	return "anon"
+}
+
+// readBody 读取不超过limit字节的请求体并重置，处理器仍可正常读取
+func readBody(c *gin.Context, limit int64) ([]byte, error) {
+	req := c.Request
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, req.Body, limit))
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/pkg/idempotency"
+	"go-api-scaffold/pkg/logger"
+)
+
+// newIdempotentRouter 创建挂载幂等中间件的路由，before在幂等中间件之前执行，返回处理器执行次数
+func newIdempotentRouter(opts IdempotencyOptions, before ...gin.HandlerFunc) (*gin.Engine, *atomic.Int32) {
+	gin.SetMode(gin.TestMode)
+	log := logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"})
+
+	var calls atomic.Int32
+	r := gin.New()
+	handlers := append(before, Idempotency(opts, log), func(c *gin.Context) {
+		n := calls.Add(1)
+		c.JSON(http.StatusCreated, gin.H{"call": n})
+	})
+	r.POST("/items", handlers...)
+	return r, &calls
+}
+
+// postItem 发送携带幂等键的请求
+func postItem(r http.Handler, key, body string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(HeaderIdempotencyKey, key)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	req.RemoteAddr = "192.0.2.1:1234"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	r, calls := newIdempotentRouter(IdempotencyOptions{
+		Store:   idempotency.NewMemoryStore(),
+		TTL:     time.Hour,
+		LockTTL: time.Minute,
+	})
+
+	first := postItem(r, "key-1", `{"name":"a"}`)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first status = %d", first.Code)
+	}
+
+	replayed := postItem(r, "key-1", `{"name":"a"}`)
+	if replayed.Code != http.StatusCreated || replayed.Body.String() != first.Body.String() {
+		t.Fatalf("replay = %d %s, want %d %s", replayed.Code, replayed.Body, first.Code, first.Body)
+	}
+	if replayed.Header().Get(HeaderReplayed) != "true" {
+		t.Fatalf("%s header missing on replay", HeaderReplayed)
+	}
+
+	if w := postItem(r, "key-1", `{"name":"b"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("different body status = %d, want 422", w.Code)
+	}
+	if w := postItem(r, "", `{"name":"a"}`); w.Code != http.StatusCreated {
+		t.Fatalf("without key status = %d", w.Code)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("handler called %d times, want 2", got)
+	}
+}
+
+func TestIdempotencyBodyLimit(t *testing.T) {
+	r, calls := newIdempotentRouter(IdempotencyOptions{
+		Store:        idempotency.NewMemoryStore(),
+		TTL:          time.Hour,
+		LockTTL:      time.Minute,
+		MaxBodyBytes: 16,
+	})
+
+	if w := postItem(r, "key-1", `{"name":"this body is too long"}`); w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("oversized body status = %d, want 413", w.Code)
+	}
+	if w := postItem(r, "key-2", `{"name":"ok"}`); w.Code != http.StatusCreated {
+		t.Fatalf("small body status = %d, want 201", w.Code)
+	}
+	// 未携带幂等键的请求不经过缓冲，由处理器自行限制
+	if w := postItem(r, "", `{"name":"this body is too long"}`); w.Code != http.StatusCreated {
+		t.Fatalf("without key status = %d, want 201", w.Code)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Fatalf("handler called %d times, want 2", got)
+	}
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	r, calls := newIdempotentRouter(IdempotencyOptions{
+		Store:   idempotency.NewMemoryStore(),
+		TTL:     time.Hour,
+		LockTTL: time.Minute,
+	}, Authenticate([]APIKey{{Actor: "ops", Key: "ops-secret"}, {Actor: "ci", Key: "ci-secret"}}))
+
+	if w := postItem(r, "shared", `{"name":"a"}`); w.Code != http.StatusCreated {
+		t.Fatalf("anonymous status = %d", w.Code)
+	}
+	// 匿名请求只按接口和键隔离，重试时客户端IP变化（NAT、移动网络）仍返回首次的响应
+	w := postItem(r, "shared", `{"name":"a"}`, "X-Forwarded-For", "198.51.100.7")
+	if w.Code != http.StatusCreated || w.Header().Get(HeaderReplayed) != "true" {
+		t.Fatalf("anonymous retry from another IP status = %d replayed = %q, want replay", w.Code, w.Header().Get(HeaderReplayed))
+	}
+
+	// 认证用户之间以及与匿名请求互相隔离，相同的键不会读到对方的响应
+	for _, key := range []string{"ops-secret", "ci-secret"} {
+		w := postItem(r, "shared", `{"name":"b"}`, "Authorization", "Bearer "+key)
+		if w.Code != http.StatusCreated || w.Header().Get(HeaderReplayed) != "" {
+			t.Fatalf("user %s status = %d replayed = %q", key, w.Code, w.Header().Get(HeaderReplayed))
+		}
+	}
+	if w := postItem(r, "shared", `{"name":"b"}`, "Authorization", "Bearer ops-secret"); w.Header().Get(HeaderReplayed) != "true" {
+		t.Fatal("authenticated retry was not replayed")
+	}
+	if got := calls.Load(); got != 3 {
+		t.Fatalf("handler called %d times, want 3", got)
+	}
+}
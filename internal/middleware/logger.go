@@ -11,7 +11,7 @@ import (
 func Logger(log logger.Logger) gin.HandlerFunc {
 	return gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
 		// 记录请求日志
-		log.WithFields(map[string]interface{}{
+		log.WithContext(param.Request.Context()).WithFields(map[string]interface{}{
 			"timestamp":  param.TimeStamp.Format(time.RFC3339),
 			"status":     param.StatusCode,
 			"latency":    param.Latency,
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api-scaffold/pkg/response"
+)
+
+// ContentNegotiation 内容协商中间件
+// 在处理器执行前按Accept头确定响应格式，没有可接受的格式时返回406；
+// 请求体的Content-Type不受支持时返回415，避免请求被处理后才发现无法响应
+func ContentNegotiation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		addVary(c.Writer.Header(), "Accept")
+
+		if _, ok := response.Negotiate(c); !ok {
+			response.NotAcceptable(c)
+			c.Abort()
+			return
+		}
+
+		if hasBody(c.Request) {
+			if contentType := c.GetHeader("Content-Type"); contentType != "" {
+				if _, ok := response.ParseFormat(contentType); !ok {
+					response.UnsupportedMediaType(c)
+					c.Abort()
+					return
+				}
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// hasBody 请求是否携带请求体
+func hasBody(req *http.Request) bool {
+	return req.ContentLength > 0 || len(req.TransferEncoding) > 0
+}
+
+// addVary 追加Vary请求头，已存在时不重复添加
+func addVary(header http.Header, name string) {
+	for _, value := range header.Values("Vary") {
+		for _, field := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(field), name) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", name)
+}
@@ -0,0 +1,232 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+
+	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/response"
+)
+
+// OpenAPIOptions 接口文档校验选项
+type OpenAPIOptions struct {
+	ValidateRequests  bool // 校验路径参数、查询参数和JSON请求体，不符合时返回400
+	ValidateResponses bool // 校验JSON响应并记录不符合文档的情况，需缓冲完整响应，仅建议在开发环境开启
+	// StreamingRoutes 流式输出的路由（"METHOD /path"），不缓冲、不校验响应
+	StreamingRoutes []string
+}
+
+// specPathParam 文档路径参数 {id}
+var specPathParam = regexp.MustCompile(`\{([^}/]+)\}`)
+
+// OpenAPIValidator 基于接口文档（swag生成的Swagger 2.0）的运行时校验中间件
+// 按gin匹配到的路由模板查找文档中的操作，文档中没有的路由直接放行
+func OpenAPIValidator(spec []byte, opts OpenAPIOptions, log logger.Logger) (gin.HandlerFunc, error) {
+	doc, basePath, err := loadOpenAPI(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	// 以"METHOD gin路由模板"为键索引文档中的操作
+	routes := make(map[string]*routers.Route)
+	for path, item := range doc.Paths.Map() {
+		ginPath := specPathParam.ReplaceAllString(basePath+path, ":$1")
+		for method, operation := range item.Operations() {
+			routes[method+" "+ginPath] = &routers.Route{
+				Spec:      doc,
+				Path:      path,
+				PathItem:  item,
+				Method:    method,
+				Operation: operation,
+			}
+		}
+	}
+
+	filterOptions := &openapi3filter.Options{
+		MultiError:          true,
+		SkipSettingDefaults: true, // 默认值由处理器自行处理，不改写请求
+		AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+	}
+	// 文档只描述JSON结构，XML等其他格式的请求体、响应体不做校验
+	nonJSONRequest := *filterOptions
+	nonJSONRequest.ExcludeRequestBody = true
+	nonJSONResponse := *filterOptions
+	nonJSONResponse.ExcludeResponseBody = true
+	log = log.WithField("component", "openapi_validator")
+	streaming := make(map[string]bool, len(opts.StreamingRoutes))
+	for _, route := range opts.StreamingRoutes {
+		streaming[route] = true
+	}
+
+	return func(c *gin.Context) {
+		route, ok := routes[c.Request.Method+" "+c.FullPath()]
+		if !ok {
+			c.Next()
+			return
+		}
+
+		pathParams := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			pathParams[param.Key] = param.Value
+		}
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    filterOptions,
+		}
+		if !isJSON(c.GetHeader("Content-Type")) {
+			input.Options = &nonJSONRequest
+		}
+
+		if opts.ValidateRequests {
+			// 校验请求体后会重置Body，处理器仍可正常读取
+			if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+				response.ValidationError(c, "Request validation failed", requestFieldErrors(err))
+				c.Abort()
+				return
+			}
+		}
+
+		if !opts.ValidateResponses || streaming[c.Request.Method+" "+c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		// 缓冲响应以便校验，校验结果只记录日志，不影响返回给客户端的内容
+		original := c.Writer
+		buffered := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = buffered
+		c.Next()
+		c.Writer = original
+
+		body := buffered.body.Bytes()
+		responseInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 buffered.status,
+			Header:                 original.Header(),
+			Options:                filterOptions,
+		}
+		if !isJSON(original.Header().Get("Content-Type")) {
+			responseInput.Options = &nonJSONResponse
+		}
+		responseInput.SetBodyBytes(body)
+		if err := openapi3filter.ValidateResponse(c.Request.Context(), responseInput); err != nil {
+			log.WithContext(c.Request.Context()).WithFields(map[string]interface{}{
+				"method": c.Request.Method,
+				"route":  c.FullPath(),
+				"status": buffered.status,
+				"error":  err.Error(),
+			}).Warn("Response does not match API docs")
+		}
+
+		original.WriteHeader(buffered.status)
+		original.WriteHeaderNow()
+		if len(body) > 0 && c.Request.Method != http.MethodHead {
+			_, _ = original.Write(body)
+		}
+	}, nil
+}
+
+// isJSON 内容是否为JSON，未声明类型时视为JSON
+// 问题详情（application/problem+json）的结构与文档中的统一响应不同，不做校验
+func isJSON(contentType string) bool {
+	if contentType == "" {
+		return true
+	}
+	typ, _, err := mime.ParseMediaType(contentType)
+	if err != nil || typ == response.MIMEProblemJSON {
+		return false
+	}
+	format, ok := response.ParseFormat(typ)
+	return ok && format == response.FormatJSON
+}
+
+// loadOpenAPI 将Swagger 2.0文档转换为OpenAPI 3并解析引用，返回文档与basePath
+func loadOpenAPI(spec []byte) (*openapi3.T, string, error) {
+	var doc2 openapi2.T
+	if err := json.Unmarshal(spec, &doc2); err != nil {
+		return nil, "", fmt.Errorf("failed to parse api docs: %w", err)
+	}
+	doc, err := openapi2conv.ToV3(&doc2)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to convert api docs to openapi 3: %w", err)
+	}
+	if err := openapi3.NewLoader().ResolveRefsIn(doc, nil); err != nil {
+		return nil, "", fmt.Errorf("failed to resolve api docs references: %w", err)
+	}
+	return doc, strings.TrimSuffix(doc2.BasePath, "/"), nil
+}
+
+// requestFieldErrors 将校验错误展开为字段级错误列表
+func requestFieldErrors(err error) []response.FieldError {
+	var errs openapi3.MultiError
+	if !errors.As(err, &errs) {
+		errs = openapi3.MultiError{err}
+	}
+
+	var fields []response.FieldError
+	for _, e := range errs {
+		var reqErr *openapi3filter.RequestError
+		if !errors.As(e, &reqErr) {
+			fields = append(fields, response.FieldError{In: "request", Message: e.Error()})
+			continue
+		}
+
+		base := response.FieldError{In: "body", Message: reqErr.Reason}
+		if reqErr.Parameter != nil {
+			base.In = reqErr.Parameter.In
+			base.Field = reqErr.Parameter.Name
+		}
+
+		schemaErrs := schemaErrors(reqErr.Err)
+		if len(schemaErrs) == 0 {
+			if base.Message == "" && reqErr.Err != nil {
+				base.Message = reqErr.Err.Error()
+			}
+			fields = append(fields, base)
+			continue
+		}
+		for _, schemaErr := range schemaErrs {
+			field := base
+			if pointer := schemaErr.JSONPointer(); len(pointer) > 0 && reqErr.Parameter == nil {
+				field.Field = strings.Join(pointer, ".")
+			}
+			field.Message = schemaErr.Reason
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// schemaErrors 提取嵌套的schema错误，MultiError模式下一个参数可能对应多个错误
+func schemaErrors(err error) []*openapi3.SchemaError {
+	if err == nil {
+		return nil
+	}
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		var result []*openapi3.SchemaError
+		for _, e := range multi {
+			result = append(result, schemaErrors(e)...)
+		}
+		return result
+	}
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		return []*openapi3.SchemaError{schemaErr}
+	}
+	return nil
+}
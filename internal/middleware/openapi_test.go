@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/pkg/logger"
+)
+
+const streamSpec = `{
+  "swagger": "2.0",
+  "info": {"title": "test", "version": "1.0"},
+  "basePath": "/",
+  "paths": {
+    "/export": {
+      "get": {
+        "produces": ["text/csv"],
+        "responses": {"200": {"description": "ok", "schema": {"type": "string"}}}
+      }
+    }
+  }
+}`
+
+// newStreamRouter 挂载响应校验的路由，处理器分两次写出并刷新
+func newStreamRouter(t *testing.T, streaming []string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	log := logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"})
+	validator, err := OpenAPIValidator([]byte(streamSpec), OpenAPIOptions{
+		ValidateResponses: true,
+		StreamingRoutes:   streaming,
+	}, log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.Use(validator)
+	r.GET("/export", func(c *gin.Context) {
+		c.Header("Content-Type", "text/csv")
+		c.Status(http.StatusOK)
+		_, _ = c.Writer.WriteString("id,username\n")
+		c.Writer.Flush()
+		_, _ = c.Writer.WriteString("1,alice\n")
+		c.Writer.Flush()
+	})
+	return r
+}
+
+func TestOpenAPIValidatorStreamingRoutes(t *testing.T) {
+	tests := []struct {
+		name        string
+		streaming   []string
+		wantFlushed bool
+	}{
+		{"streaming route is not buffered", []string{"GET /export"}, true},
+		{"buffered route flushes only at the end", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			newStreamRouter(t, tt.streaming).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export", nil))
+
+			if w.Code != http.StatusOK || w.Body.String() != "id,username\n1,alice\n" {
+				t.Fatalf("response = %d %q", w.Code, w.Body.String())
+			}
+			if w.Flushed != tt.wantFlushed {
+				t.Fatalf("flushed = %v, want %v", w.Flushed, tt.wantFlushed)
+			}
+		})
+	}
+}
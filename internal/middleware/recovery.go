@@ -13,7 +13,7 @@ import (
 func Recovery(log logger.Logger) gin.HandlerFunc {
 	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
 		if err, ok := recovered.(string); ok {
-			log.WithFields(map[string]interface{}{
+			log.WithContext(c.Request.Context()).WithFields(map[string]interface{}{
 				"error":      err,
 				"stack":      string(debug.Stack()),
 				"method":     c.Request.Method,
@@ -24,7 +24,7 @@ func Recovery(log logger.Logger) gin.HandlerFunc {
 		}
 
 		if err, ok := recovered.(error); ok {
-			log.WithFields(map[string]interface{}{
+			log.WithContext(c.Request.Context()).WithFields(map[string]interface{}{
 				"error":      err.Error(),
 				"stack":      string(debug.Stack()),
 				"method":     c.Request.Method,
@@ -34,7 +34,7 @@ func Recovery(log logger.Logger) gin.HandlerFunc {
 			}).Error("Panic recovered")
 		}
 
-		log.WithFields(map[string]interface{}{
+		log.WithContext(c.Request.Context()).WithFields(map[string]interface{}{
 			"error":      fmt.Sprintf("%v", recovered),
 			"stack":      string(debug.Stack()),
 			"method":     c.Request.Method,
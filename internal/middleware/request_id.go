@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+	"go-api-scaffold/pkg/logger"
+)
+
+// HeaderRequestID 请求ID头
+const HeaderRequestID = "X-Request-ID"
+
+// RequestID 请求ID中间件
+// 优先沿用上游传入的X-Request-ID，否则生成新的ID，并与客户端信息一起写入请求上下文供日志、审计使用
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(HeaderRequestID)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = newRequestID()
+		}
+
+		c.Set(logger.FieldRequestID, requestID)
+		ctx := logger.ContextWithRequestID(c.Request.Context(), requestID)
+		ctx = logger.ContextWithClientInfo(ctx, logger.ClientInfo{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(HeaderRequestID, requestID)
+
+		c.Next()
+	}
+}
+
+// newRequestID 生成16字节随机请求ID
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/tracing"
+)
+
+// Tracing 分布式追踪中间件
+// 从W3C traceparent头提取上游链路，为每个请求创建服务端span，后续服务层、数据库span都挂在其下
+// 服务端span的traceparent写回响应头，便于客户端按链路查询
+func Tracing() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		propagator := otel.GetTextMapPropagator()
+		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		// 使用路由模板命名，避免路径参数导致span名称基数爆炸
+		route := c.FullPath()
+		name := c.Request.Method + " " + route
+		if route == "" {
+			name = c.Request.Method
+		}
+
+		attrs := []attribute.KeyValue{
+			semconv.HTTPRequestMethodKey.String(c.Request.Method),
+			semconv.URLPath(c.Request.URL.Path),
+			semconv.ClientAddress(c.ClientIP()),
+			semconv.UserAgentOriginal(c.Request.UserAgent()),
+		}
+		if route != "" {
+			attrs = append(attrs, semconv.HTTPRoute(route))
+		}
+		if requestID := logger.RequestIDFromContext(ctx); requestID != "" {
+			attrs = append(attrs, attribute.String("http.request.id", requestID))
+		}
+
+		ctx, span := tracing.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attrs...),
+		)
+		defer span.End()
+		propagator.Inject(ctx, propagation.HeaderCarrier(c.Writer.Header()))
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		// 服务端span仅5xx视为错误，4xx属于客户端问题
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		if len(c.Errors) > 0 {
+			span.RecordError(c.Errors.Last())
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/pkg/tracing"
+)
+
+// newMemoryTracer 注册memory导出器的全局追踪提供者
+func newMemoryTracer(t *testing.T) *tracing.Provider {
+	t.Helper()
+	tp, err := tracing.New(config.TracingConfig{Enabled: true, Exporter: "memory"}, config.AppConfig{Name: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = tp.Shutdown(t.Context()) })
+	return tp
+}
+
+func TestTracing(t *testing.T) {
+	tp := newMemoryTracer(t)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Tracing())
+
+	var handlerSpan trace.SpanContext
+	r.GET("/users/:id", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+	r.GET("/fail", func(c *gin.Context) {
+		c.Status(http.StatusBadGateway)
+	})
+
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		parent  = "00f067aa0ba902b7"
+	)
+	req := httptest.NewRequest(http.MethodGet, "/users/42", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+parent+"-01")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	spans := tp.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("spans = %d, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "GET /users/:id" || span.SpanKind != trace.SpanKindServer {
+		t.Errorf("span = %q kind %s, want server span named after the route", span.Name, span.SpanKind)
+	}
+	// 上游traceparent作为父span
+	if span.SpanContext.TraceID().String() != traceID || span.Parent.SpanID().String() != parent || !span.Parent.IsRemote() {
+		t.Errorf("trace = %s parent = %s, want remote parent %s in trace %s",
+			span.SpanContext.TraceID(), span.Parent.SpanID(), parent, traceID)
+	}
+	if handlerSpan.SpanID() != span.SpanContext.SpanID() {
+		t.Error("request context does not carry the server span")
+	}
+	attrs := map[string]string{}
+	for _, kv := range span.Attributes {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs["http.route"] != "/users/:id" || attrs["url.path"] != "/users/42" || attrs["http.response.status_code"] != "200" {
+		t.Errorf("attributes = %v", attrs)
+	}
+
+	// 响应头携带服务端span的traceparent
+	want := "00-" + traceID + "-" + span.SpanContext.SpanID().String() + "-01"
+	if got := w.Header().Get("traceparent"); got != want {
+		t.Errorf("response traceparent = %q, want %q", got, want)
+	}
+
+	tp.Reset()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	spans = tp.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("spans = %d, want 1", len(spans))
+	}
+	// 无上游链路时开启新的trace，5xx标记为错误
+	if spans[0].Parent.IsValid() || spans[0].Status.Code != codes.Error {
+		t.Errorf("parent = %v, status = %v, want root span with error status", spans[0].Parent, spans[0].Status)
+	}
+}
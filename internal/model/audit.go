@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+)
+
+// 审计动作
+const (
+	AuditActionCreate      = "create"
+	AuditActionUpdate      = "update"
+	AuditActionDelete      = "delete"
+	AuditActionRestore     = "restore"
+	AuditActionLogin       = "login"
+	AuditActionLoginFailed = "login_failed"
+)
+
+// AuditLog 审计日志模型，只追加不修改
+type AuditLog struct {
+	ID           uint      `json:"id" gorm:"primarykey"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`
+	ActorID      string    `json:"actor_id" gorm:"size:64;index"`
+	Action       string    `json:"action" gorm:"size:32;index"`
+	ResourceType string    `json:"resource_type" gorm:"size:64;index:idx_audit_logs_resource"`
+	ResourceID   string    `json:"resource_id" gorm:"size:64;index:idx_audit_logs_resource"`
+	Changes      string    `json:"changes" gorm:"type:text"`
+	IP           string    `json:"ip" gorm:"size:64"`
+	UserAgent    string    `json:"user_agent" gorm:"size:255"`
+	RequestID    string    `json:"request_id" gorm:"size:64"`
+}
+
+// TableName 表名
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// AuditLogFilter 审计日志查询条件
+type AuditLogFilter struct {
+	ActorID      string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	From         *time.Time
+	To           *time.Time
+}
+
+// AuditChange 字段变更
+type AuditChange struct {
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+)
+
+// outbox消息状态
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusPublished = "published"
+	OutboxStatusFailed    = "failed"
+)
+
+// OutboxMessage 事务性outbox消息，与业务变更在同一事务中写入，由中继异步投递
+type OutboxMessage struct {
+	ID            uint       `json:"id" gorm:"primarykey"`
+	CreatedAt     time.Time  `json:"created_at"`
+	EventID       string     `json:"event_id" gorm:"size:64;uniqueIndex"`
+	EventName     string     `json:"event_name" gorm:"size:128;index"`
+	Payload       string     `json:"payload" gorm:"type:text"`
+	Status        string     `json:"status" gorm:"size:16;index:idx_outbox_messages_pending"`
+	Attempts      int        `json:"attempts"`
+	LastError     string     `json:"last_error" gorm:"size:1024"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index:idx_outbox_messages_pending"`
+	PublishedAt   *time.Time `json:"published_at"`
+}
+
+// TableName 表名
+func (OutboxMessage) TableName() string {
+	return "outbox_messages"
+}
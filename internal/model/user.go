@@ -24,19 +24,25 @@ func (User) TableName() string {
 
 // UserCreateRequest 创建用户请求
 type UserCreateRequest struct {
-	Username string `json:"username" validate:"required,min=3,max=50"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
-	Nickname string `json:"nickname" validate:"max=50"`
-	Phone    string `json:"phone" validate:"max=20"`
+	Username string `json:"username" xml:"username" validate:"required,min=3,max=50"`
+	Email    string `json:"email" xml:"email" validate:"required,email"`
+	Password string `json:"password" xml:"password" validate:"required,min=6"`
+	Nickname string `json:"nickname" xml:"nickname" validate:"max=50"`
+	Phone    string `json:"phone" xml:"phone" validate:"max=20"`
 }
 
 // UserUpdateRequest 更新用户请求
 type UserUpdateRequest struct {
-	Nickname string `json:"nickname" validate:"max=50"`
-	Avatar   string `json:"avatar" validate:"max=255"`
-	Phone    string `json:"phone" validate:"max=20"`
-	Status   *int   `json:"status" validate:"omitempty,oneof=0 1"`
+	Nickname string `json:"nickname" xml:"nickname" validate:"max=50"`
+	Avatar   string `json:"avatar" xml:"avatar" validate:"max=255"`
+	Phone    string `json:"phone" xml:"phone" validate:"max=20"`
+	Status   *int   `json:"status,omitempty" xml:"status" validate:"omitempty,oneof=0 1"`
+}
+
+// UserLoginRequest 登录请求，username可以是用户名或邮箱
+type UserLoginRequest struct {
+	Username string `json:"username" xml:"username" validate:"required"`
+	Password string `json:"password" xml:"password" validate:"required"`
 }
 
 // UserResponse 用户响应
@@ -52,3 +58,133 @@ type UserResponse struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+// UserResponseV2 用户响应（v2）
+// 与v1相比：资料字段归入profile，状态使用字符串，从未登录时省略last_login
+type UserResponseV2 struct {
+	ID        uint          `json:"id"`
+	Username  string        `json:"username"`
+	Email     string        `json:"email"`
+	Profile   UserProfileV2 `json:"profile"`
+	Status    string        `json:"status" enums:"active,disabled"`
+	LastLogin *time.Time    `json:"last_login,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// UserProfileV2 用户资料（v2）
+type UserProfileV2 struct {
+	Nickname string `json:"nickname"`
+	Avatar   string `json:"avatar"`
+	Phone    string `json:"phone"`
+}
+
+// v2用户状态
+const (
+	UserStatusActive   = "active"
+	UserStatusDisabled = "disabled"
+)
+
+// NewUserResponseV2 由v1响应转换为v2响应
+func NewUserResponseV2(user *UserResponse) *UserResponseV2 {
+	resp := &UserResponseV2{
+		ID:       user.ID,
+		Username: user.Username,
+		Email:    user.Email,
+		Profile: UserProfileV2{
+			Nickname: user.Nickname,
+			Avatar:   user.Avatar,
+			Phone:    user.Phone,
+		},
+		Status:    UserStatusDisabled,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+	if user.Status == 1 {
+		resp.Status = UserStatusActive
+	}
+	if !user.LastLogin.IsZero() {
+		lastLogin := user.LastLogin
+		resp.LastLogin = &lastLogin
+	}
+	return resp
+}
+
+// 批量导入的行状态
+const (
+	UserImportCreated = "created" // 已创建
+	UserImportValid   = "valid"   // 试运行时校验通过
+	UserImportFailed  = "failed"  // 解析、校验或创建失败
+)
+
+// UserImportRecord 待导入的一行，Row为文件中的行号；Errors非空表示解析或校验已失败
+type UserImportRecord struct {
+	Row     int
+	Request UserCreateRequest
+	Errors  []string
+}
+
+// UserImportResult 批量导入结果
+type UserImportResult struct {
+	DryRun    bool            `json:"dry_run"`
+	Total     int             `json:"total"`
+	Succeeded int             `json:"succeeded"`
+	Failed    int             `json:"failed"`
+	Rows      []UserImportRow `json:"rows"`
+}
+
+// UserImportRow 单行导入结果
+type UserImportRow struct {
+	Row      int      `json:"row"` // 文件中的行号，CSV表头为第1行
+	Username string   `json:"username,omitempty"`
+	Status   string   `json:"status" enums:"created,valid,failed"`
+	ID       uint     `json:"id,omitempty"` // 创建成功时的用户ID
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// 批量操作类型
+const (
+	UserBatchSetStatus = "set_status" // 设置状态
+	UserBatchDelete    = "delete"     // 软删除
+	UserBatchRestore   = "restore"    // 恢复软删除
+)
+
+// 批量操作执行模式
+const (
+	UserBatchAtomic     = "atomic"      // 单一事务，任一失败全部回滚
+	UserBatchBestEffort = "best_effort" // 逐个执行，单个失败不影响其他
+)
+
+// 批量操作单项状态
+const (
+	UserBatchSucceeded  = "succeeded"
+	UserBatchFailed     = "failed"
+	UserBatchRolledBack = "rolled_back" // 原子模式下执行成功但因其他项失败被回滚
+)
+
+// UserBatchRequest 批量操作请求，Mode为空时使用atomic
+type UserBatchRequest struct {
+	Operation string `json:"operation" xml:"operation" validate:"required,oneof=set_status delete restore" enums:"set_status,delete,restore"`
+	IDs       []uint `json:"ids" xml:"ids>id" validate:"required,min=1,max=100,unique,dive,min=1"`
+	Status    *int   `json:"status,omitempty" xml:"status" validate:"required_if=Operation set_status,omitempty,oneof=0 1"`
+	Mode      string `json:"mode,omitempty" xml:"mode" validate:"omitempty,oneof=atomic best_effort" enums:"atomic,best_effort"`
+}
+
+// UserBatchResult 批量操作结果
+type UserBatchResult struct {
+	Operation  string          `json:"operation"`
+	Mode       string          `json:"mode"`
+	RolledBack bool            `json:"rolled_back"` // 原子模式下是否已整体回滚
+	Succeeded  int             `json:"succeeded"`
+	Failed     int             `json:"failed"`
+	Items      []UserBatchItem `json:"items"`
+}
+
+// UserBatchItem 单个用户的操作结果，Err由处理器映射为Code和Error
+type UserBatchItem struct {
+	ID     uint   `json:"id"`
+	Status string `json:"status" enums:"succeeded,failed,rolled_back"`
+	Code   int    `json:"code,omitempty"` // 失败时对应的HTTP状态码
+	Error  string `json:"error,omitempty"`
+	Err    error  `json:"-"`
+}
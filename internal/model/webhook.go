@@ -0,0 +1,103 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Webhook投递状态
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookEventAll 订阅全部事件
+const WebhookEventAll = "*"
+
+// WebhookSubscription Webhook订阅
+type WebhookSubscription struct {
+	BaseModel
+	URL         string `json:"url" gorm:"not null;size:512"`
+	Secret      string `json:"-" gorm:"not null;size:128"`
+	Events      string `json:"-" gorm:"size:1024;comment:逗号分隔的事件名，*表示全部"`
+	Description string `json:"description" gorm:"size:255"`
+	Active      bool   `json:"active"`
+}
+
+// TableName 表名
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// EventList 订阅的事件列表
+func (s *WebhookSubscription) EventList() []string {
+	if s.Events == "" {
+		return []string{WebhookEventAll}
+	}
+	return strings.Split(s.Events, ",")
+}
+
+// Matches 判断订阅是否关注指定事件
+func (s *WebhookSubscription) Matches(event string) bool {
+	for _, name := range s.EventList() {
+		if name == WebhookEventAll || name == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery Webhook投递记录，每个事件对每个订阅生成一条
+type WebhookDelivery struct {
+	ID             uint                 `json:"id" gorm:"primarykey"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+	SubscriptionID uint                 `json:"subscription_id" gorm:"index"`
+	Subscription   *WebhookSubscription `json:"-"`
+	EventID        string               `json:"event_id" gorm:"size:64;index"`
+	EventName      string               `json:"event_name" gorm:"size:128"`
+	Payload        string               `json:"payload" gorm:"type:text"`
+	Status         string               `json:"status" gorm:"size:16;index:idx_webhook_deliveries_due"`
+	Attempts       int                  `json:"attempts"`
+	NextAttemptAt  time.Time            `json:"next_attempt_at" gorm:"index:idx_webhook_deliveries_due"`
+	LastAttemptAt  *time.Time           `json:"last_attempt_at"`
+	ResponseStatus int                  `json:"response_status"`
+	LastError      string               `json:"last_error" gorm:"size:1024"`
+	DeliveredAt    *time.Time           `json:"delivered_at"`
+}
+
+// TableName 表名
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// WebhookCreateRequest 创建Webhook订阅请求
+type WebhookCreateRequest struct {
+	URL         string   `json:"url" xml:"url" validate:"required,url,max=512"`
+	Events      []string `json:"events" xml:"events>item" validate:"required,min=1,dive,required,max=128"`
+	Secret      string   `json:"secret" xml:"secret" validate:"omitempty,min=16,max=128"` // 为空时自动生成
+	Description string   `json:"description" xml:"description" validate:"max=255"`
+	Active      *bool    `json:"active,omitempty" xml:"active"`
+}
+
+// WebhookUpdateRequest 更新Webhook订阅请求，未提供的字段保持不变
+type WebhookUpdateRequest struct {
+	URL         string   `json:"url" xml:"url" validate:"omitempty,url,max=512"`
+	Events      []string `json:"events" xml:"events>item" validate:"omitempty,dive,required,max=128"`
+	Secret      string   `json:"secret" xml:"secret" validate:"omitempty,min=16,max=128"`
+	Description *string  `json:"description,omitempty" xml:"description" validate:"omitempty,max=255"`
+	Active      *bool    `json:"active,omitempty" xml:"active"`
+}
+
+// WebhookResponse Webhook订阅响应
+type WebhookResponse struct {
+	ID          uint      `json:"id"`
+	URL         string    `json:"url"`
+	Events      []string  `json:"events"`
+	Description string    `json:"description"`
+	Active      bool      `json:"active"`
+	Secret      string    `json:"secret,omitempty"` // 仅创建时返回
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"go-api-scaffold/internal/model"
+	"gorm.io/gorm"
+)
+
+// AuditRepository 审计日志仓储接口
+type AuditRepository interface {
+	Create(ctx context.Context, log *model.AuditLog) error
+	List(ctx context.Context, filter model.AuditLogFilter, offset, limit int) ([]*model.AuditLog, int64, error)
+}
+
+// auditRepository 审计日志仓储实现
+type auditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository 创建审计日志仓储实例
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// Create 写入审计日志，处于事务中时随业务变更一起提交
+func (r *auditRepository) Create(ctx context.Context, log *model.AuditLog) error {
+	return conn(ctx, r.db).Create(log).Error
+}
+
+// List 按条件查询审计日志，按时间倒序
+func (r *auditRepository) List(ctx context.Context, filter model.AuditLogFilter, offset, limit int) ([]*model.AuditLog, int64, error) {
+	var logs []*model.AuditLog
+	var total int64
+
+	query := conn(ctx, r.db).Model(&model.AuditLog{})
+	if filter.ActorID != "" {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		query = query.Where("resource_id = ?", filter.ResourceID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	// 获取总数
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// 获取列表
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&logs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// ErrDuplicateKey 唯一约束冲突
+var ErrDuplicateKey = errors.New("duplicate key")
+
+// ConflictError 唯一约束冲突错误，Column为推断出的冲突列
+type ConflictError struct {
+	Column string
+	Err    error
+}
+
+// Error 实现error接口
+func (e *ConflictError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("duplicate key: %v", e.Err)
+	}
+	return fmt.Sprintf("duplicate key on %s: %v", e.Column, e.Err)
+}
+
+// Is 使errors.Is(err, ErrDuplicateKey)成立
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrDuplicateKey
+}
+
+// Unwrap 返回原始驱动错误
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	// MySQL: Duplicate entry 'x' for key 'users.idx_users_username'
+	mysqlKeyPattern = regexp.MustCompile(`for key '([^']+)'`)
+	// Postgres: duplicate key value violates unique constraint "idx_users_email"
+	postgresKeyPattern = regexp.MustCompile(`unique constraint "([^"]+)"`)
+	// SQLite: UNIQUE constraint failed: users.username
+	sqliteKeyPattern = regexp.MustCompile(`UNIQUE constraint failed: ([\w.]+)`)
+)
+
+// sqlStateError PostgreSQL驱动错误（pgconn.PgError等）暴露的SQLSTATE接口
+type sqlStateError interface {
+	SQLState() string
+}
+
+// translateError 将驱动的唯一约束错误转换为ConflictError，其他错误原样返回
+// columns为可能冲突的唯一列，用于从约束名中识别具体冲突列
+func translateError(err error, columns ...string) error {
+	if err == nil {
+		return nil
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+		return &ConflictError{Column: matchColumn(mysqlKeyPattern, mysqlErr.Message, columns), Err: err}
+	}
+
+	var pgErr sqlStateError
+	if errors.As(err, &pgErr) && pgErr.SQLState() == "23505" {
+		return &ConflictError{Column: matchColumn(postgresKeyPattern, err.Error(), columns), Err: err}
+	}
+
+	if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+		return &ConflictError{Column: matchColumn(sqliteKeyPattern, err.Error(), columns), Err: err}
+	}
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return &ConflictError{Err: err}
+	}
+
+	return err
+}
+
+// matchColumn 从约束名中识别冲突列
+// 兼容 users.username、idx_users_username、users_email_key 等常见命名
+func matchColumn(pattern *regexp.Regexp, msg string, columns []string) string {
+	m := pattern.FindStringSubmatch(msg)
+	if m == nil {
+		return ""
+	}
+
+	key := strings.TrimSuffix(m[1], "_key")
+	for _, col := range columns {
+		if key == col || strings.HasSuffix(key, "."+col) || strings.HasSuffix(key, "_"+col) {
+			return col
+		}
+	}
+	return ""
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// pgError 模拟pgconn.PgError
+type pgError struct {
+	code    string
+	message string
+}
+
+func (e *pgError) Error() string    { return e.message }
+func (e *pgError) SQLState() string { return e.code }
+
+func TestTranslateError(t *testing.T) {
+	columns := []string{"username", "email"}
+	other := errors.New("connection refused")
+
+	tests := []struct {
+		name       string
+		err        error
+		wantDup    bool
+		wantColumn string
+	}{
+		{"nil", nil, false, ""},
+		{"other error", other, false, ""},
+		{"mysql table.index", &mysql.MySQLError{Number: 1062,
+			Message: "Duplicate entry 'alice' for key 'users.idx_users_username'"}, true, "username"},
+		{"mysql index without table", &mysql.MySQLError{Number: 1062,
+			Message: "Duplicate entry 'a@example.com' for key 'idx_users_email'"}, true, "email"},
+		{"mysql unknown key", &mysql.MySQLError{Number: 1062,
+			Message: "Duplicate entry '1' for key 'PRIMARY'"}, true, ""},
+		{"mysql other number", &mysql.MySQLError{Number: 1045, Message: "Access denied"}, false, ""},
+		{"mysql wrapped", fmt.Errorf("insert user: %w", &mysql.MySQLError{Number: 1062,
+			Message: "Duplicate entry 'alice' for key 'users.idx_users_username'"}), true, "username"},
+		{"postgres idx name", &pgError{code: "23505",
+			message: `ERROR: duplicate key value violates unique constraint "idx_users_email" (SQLSTATE 23505)`}, true, "email"},
+		{"postgres default name", &pgError{code: "23505",
+			message: `ERROR: duplicate key value violates unique constraint "users_username_key" (SQLSTATE 23505)`}, true, "username"},
+		{"postgres other state", &pgError{code: "23503", message: "foreign key violation"}, false, ""},
+		{"sqlite", errors.New("UNIQUE constraint failed: users.email"), true, "email"},
+		{"sqlite unknown column", errors.New("UNIQUE constraint failed: users.phone"), true, ""},
+		{"gorm translated", gorm.ErrDuplicatedKey, true, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := translateError(tt.err, columns...)
+			if got := errors.Is(err, ErrDuplicateKey); got != tt.wantDup {
+				t.Fatalf("errors.Is(%v, ErrDuplicateKey) = %v, want %v", err, got, tt.wantDup)
+			}
+			if !tt.wantDup {
+				if err != tt.err {
+					t.Errorf("translateError() = %v, want the original error", err)
+				}
+				return
+			}
+			var conflict *ConflictError
+			if !errors.As(err, &conflict) {
+				t.Fatalf("translateError() = %T, want *ConflictError", err)
+			}
+			if conflict.Column != tt.wantColumn {
+				t.Errorf("column = %q, want %q", conflict.Column, tt.wantColumn)
+			}
+			// 保留原始驱动错误
+			if !errors.Is(err, tt.err) {
+				t.Errorf("translateError() does not wrap %v", tt.err)
+			}
+		})
+	}
+}
+
+func TestMatchColumn(t *testing.T) {
+	columns := []string{"name", "username"}
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{"for key 'users.username'", "username"},
+		{"for key 'idx_users_username'", "username"},
+		{"for key 'users_username_key'", "username"},
+		// 按完整列名匹配，username不会被识别为name
+		{"for key 'users.name'", "name"},
+		{"for key 'idx_users_nickname'", ""},
+		{"no key in message", ""},
+	}
+	for _, tt := range tests {
+		if got := matchColumn(mysqlKeyPattern, tt.msg, columns); got != tt.want {
+			t.Errorf("matchColumn(%q) = %q, want %q", tt.msg, got, tt.want)
+		}
+	}
+}
+
+// TestTranslateSQLiteDriverError 使用真实驱动返回的错误
+func TestTranslateSQLiteDriverError(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "errors.db")), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	type account struct {
+		ID       uint
+		Username string `gorm:"uniqueIndex"`
+	}
+	if err := db.AutoMigrate(&account{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&account{Username: "alice"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	err = translateError(db.Create(&account{Username: "alice"}).Error, "username")
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) || conflict.Column != "username" {
+		t.Errorf("translateError() = %v, want conflict on username", err)
+	}
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"go-api-scaffold/internal/model"
+)
+
+// OutboxRepository outbox消息仓储接口
+type OutboxRepository interface {
+	Create(ctx context.Context, msgs ...*model.OutboxMessage) error
+	// ClaimPending 领取到期的待投递消息并将下次投递时间推迟lease，避免多实例重复领取
+	ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*model.OutboxMessage, error)
+	MarkPublished(ctx context.Context, id uint, at time.Time) error
+	MarkFailed(ctx context.Context, msg *model.OutboxMessage) error
+}
+
+// outboxRepository outbox消息仓储实现
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository 创建outbox消息仓储实例
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// Create 写入outbox消息，处于事务中时随业务变更一起提交
+func (r *outboxRepository) Create(ctx context.Context, msgs ...*model.OutboxMessage) error {
+	if len(msgs) == 0 {
+		return nil
+	}
+	return conn(ctx, r.db).Create(msgs).Error
+}
+
+// ClaimPending 按写入顺序领取待投递消息，行锁只在领取的短事务中持有
+func (r *outboxRepository) ClaimPending(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*model.OutboxMessage, error) {
+	var msgs []*model.OutboxMessage
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.OutboxStatusPending, now).
+			Order("id").
+			Limit(limit).
+			Find(&msgs).Error
+		if err != nil || len(msgs) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(msgs))
+		for i, msg := range msgs {
+			ids[i] = msg.ID
+			msg.NextAttemptAt = now.Add(lease)
+		}
+		return tx.Model(&model.OutboxMessage{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return msgs, nil
+}
+
+// MarkPublished 标记消息已投递
+func (r *outboxRepository) MarkPublished(ctx context.Context, id uint, at time.Time) error {
+	return conn(ctx, r.db).Model(&model.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       model.OutboxStatusPublished,
+		"published_at": at,
+		"last_error":   "",
+	}).Error
+}
+
+// MarkFailed 记录投递失败，状态、重试次数和下次投递时间由调用方设置
+func (r *outboxRepository) MarkFailed(ctx context.Context, msg *model.OutboxMessage) error {
+	return conn(ctx, r.db).Model(&model.OutboxMessage{}).Where("id = ?", msg.ID).Updates(map[string]interface{}{
+		"status":          msg.Status,
+		"attempts":        msg.Attempts,
+		"last_error":      msg.LastError,
+		"next_attempt_at": msg.NextAttemptAt,
+	}).Error
+}
@@ -1,17 +1,50 @@
 package repository
 
 import (
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/pkg/cache"
+	"go-api-scaffold/pkg/logger"
 )
 
 // Repository 仓储接口集合
 type Repository struct {
-	User UserRepository
+	User    UserRepository
+	Audit   AuditRepository
+	Outbox  OutboxRepository
+	Webhook WebhookRepository
+	Tx      TxManager
 }
 
 // New 创建仓储实例
 func New(db *gorm.DB) *Repository {
 	return &Repository{
-		User: NewUserRepository(db),
+		User:    NewUserRepository(db),
+		Audit:   NewAuditRepository(db),
+		Outbox:  NewOutboxRepository(db),
+		Webhook: NewWebhookRepository(db),
+		Tx:      NewTxManager(db),
+	}
+}
+
+// EnableCache 按配置为各资源启用旁路缓存，client仅在使用redis存储时需要
+func (r *Repository) EnableCache(cfg config.CacheConfig, client *redis.Client, logger logger.Logger) error {
+	if rc := cfg.Resource("users"); rc.Enabled {
+		store, err := cache.NewStore(cfg, client, "users")
+		if err != nil {
+			return err
+		}
+
+		stats := &cache.Stats{}
+		stats.Publish("users")
+
+		r.User = NewCachedUserRepository(r.User, store, UserCacheOptions{
+			TTL:         rc.TTL,
+			NegativeTTL: rc.NegativeTTL,
+			Stats:       stats,
+		}, logger)
 	}
+	return nil
 }
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txKey 上下文中事务状态的键
+type txKey struct{}
+
+// txState 事务状态，嵌套事务共享同一状态
+type txState struct {
+	db          *gorm.DB
+	afterCommit []func(ctx context.Context)
+}
+
+// TxManager 事务管理器
+// fn中使用传入的ctx调用仓储方法即可自动加入同一事务
+type TxManager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// txManager 基于GORM的事务管理器实现
+type txManager struct {
+	db *gorm.DB
+}
+
+// NewTxManager 创建事务管理器实例
+func NewTxManager(db *gorm.DB) TxManager {
+	return &txManager{db: db}
+}
+
+// WithinTransaction 在事务中执行fn，fn返回错误或panic时回滚
+// 已处于事务中时使用保存点实现嵌套事务
+func (m *txManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if outer, ok := ctx.Value(txKey{}).(*txState); ok {
+		// 嵌套事务的回调在保存点释放后并入外层，回滚时丢弃
+		inner := &txState{}
+		err := outer.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			inner.db = tx
+			return fn(context.WithValue(ctx, txKey{}, inner))
+		})
+		if err == nil {
+			outer.afterCommit = append(outer.afterCommit, inner.afterCommit...)
+		}
+		return err
+	}
+
+	state := &txState{}
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		state.db = tx
+		return fn(context.WithValue(ctx, txKey{}, state))
+	})
+	if err != nil {
+		return err
+	}
+
+	// 最外层事务提交成功后执行回调
+	for _, hook := range state.afterCommit {
+		hook(ctx)
+	}
+	return nil
+}
+
+// AfterCommit 注册事务提交后执行的回调，不在事务中时立即执行
+// 适用于缓存失效、事件发布等不能早于提交发生的操作
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		state.afterCommit = append(state.afterCommit, fn)
+		return
+	}
+	fn(ctx)
+}
+
+// InTransaction 判断上下文是否处于事务中
+func InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*txState)
+	return ok
+}
+
+// Conn 获取当前上下文应使用的数据库连接，存在事务时返回事务连接
+// 供仓储层之外需要加入同一事务的组件使用，如任务队列在业务事务中入队
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	return conn(ctx, db)
+}
+
+// conn 获取当前上下文应使用的数据库连接，存在事务时返回事务连接
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		return state.db.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -1,21 +1,35 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"go-api-scaffold/internal/model"
 	"gorm.io/gorm"
 )
 
 // UserRepository 用户仓储接口
 type UserRepository interface {
-	Create(user *model.User) error
-	GetByID(id uint) (*model.User, error)
-	GetByUsername(username string) (*model.User, error)
-	GetByEmail(email string) (*model.User, error)
-	Update(user *model.User) error
-	Delete(id uint) error
-	List(offset, limit int) ([]*model.User, int64, error)
+	Create(ctx context.Context, user *model.User) error
+	GetByID(ctx context.Context, id uint) (*model.User, error)
+	GetByUsername(ctx context.Context, username string) (*model.User, error)
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	Update(ctx context.Context, user *model.User) error
+	// TouchLastLogin 只更新最后登录时间，不覆盖其他字段
+	TouchLastLogin(ctx context.Context, id uint, at time.Time) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, offset, limit int) ([]*model.User, int64, error)
+	// PurgeDeleted 物理删除before之前软删除的用户，每次最多limit条，返回删除数量
+	PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error)
+	// Restore 恢复软删除的用户，用户不存在或未删除时返回gorm.ErrRecordNotFound
+	Restore(ctx context.Context, id uint) error
+	// Each 按ID顺序分批遍历全部用户，每批最多batchSize条，fn返回错误时停止
+	Each(ctx context.Context, batchSize int, fn func(users []*model.User) error) error
 }
 
+// userUniqueColumns 用户表唯一列
+var userUniqueColumns = []string{"username", "email"}
+
 // userRepository 用户仓储实现
 type userRepository struct {
 	db *gorm.DB
@@ -27,14 +41,14 @@ func NewUserRepository(db *gorm.DB) UserRepository {
 }
 
 // Create 创建用户
-func (r *userRepository) Create(user *model.User) error {
-	return r.db.Create(user).Error
+func (r *userRepository) Create(ctx context.Context, user *model.User) error {
+	return translateError(conn(ctx, r.db).Create(user).Error, userUniqueColumns...)
 }
 
 // GetByID 根据ID获取用户
-func (r *userRepository) GetByID(id uint) (*model.User, error) {
+func (r *userRepository) GetByID(ctx context.Context, id uint) (*model.User, error) {
 	var user model.User
-	err := r.db.First(&user, id).Error
+	err := conn(ctx, r.db).First(&user, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -42,9 +56,9 @@ func (r *userRepository) GetByID(id uint) (*model.User, error) {
 }
 
 // GetByUsername 根据用户名获取用户
-func (r *userRepository) GetByUsername(username string) (*model.User, error) {
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
 	var user model.User
-	err := r.db.Where("username = ?", username).First(&user).Error
+	err := conn(ctx, r.db).Where("username = ?", username).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
@@ -52,9 +66,9 @@ func (r *userRepository) GetByUsername(username string) (*model.User, error) {
 }
 
 // GetByEmail 根据邮箱获取用户
-func (r *userRepository) GetByEmail(email string) (*model.User, error) {
+func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	err := r.db.Where("email = ?", email).First(&user).Error
+	err := conn(ctx, r.db).Where("email = ?", email).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
@@ -62,30 +76,88 @@ func (r *userRepository) GetByEmail(email string) (*model.User, error) {
 }
 
 // Update 更新用户
-func (r *userRepository) Update(user *model.User) error {
-	return r.db.Save(user).Error
+func (r *userRepository) Update(ctx context.Context, user *model.User) error {
+	return translateError(conn(ctx, r.db).Save(user).Error, userUniqueColumns...)
+}
+
+// TouchLastLogin 更新最后登录时间，同时更新updated_at以便条件请求感知变化
+func (r *userRepository) TouchLastLogin(ctx context.Context, id uint, at time.Time) error {
+	return conn(ctx, r.db).Model(&model.User{}).Where("id = ?", id).UpdateColumns(map[string]interface{}{
+		"last_login": at,
+		"updated_at": at,
+	}).Error
 }
 
 // Delete 删除用户
-func (r *userRepository) Delete(id uint) error {
-	return r.db.Delete(&model.User{}, id).Error
+func (r *userRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&model.User{}, id).Error
 }
 
 // List 获取用户列表
-func (r *userRepository) List(offset, limit int) ([]*model.User, int64, error) {
+func (r *userRepository) List(ctx context.Context, offset, limit int) ([]*model.User, int64, error) {
 	var users []*model.User
 	var total int64
 
 	// 获取总数
-	if err := r.db.Model(&model.User{}).Count(&total).Error; err != nil {
+	if err := conn(ctx, r.db).Model(&model.User{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// 获取列表
-	err := r.db.Offset(offset).Limit(limit).Find(&users).Error
+	err := conn(ctx, r.db).Offset(offset).Limit(limit).Find(&users).Error
 	if err != nil {
 		return nil, 0, err
 	}
 
 	return users, total, nil
 }
+
+// PurgeDeleted 物理删除已软删除的用户
+func (r *userRepository) PurgeDeleted(ctx context.Context, before time.Time, limit int) (int64, error) {
+	var ids []uint
+	err := conn(ctx, r.db).Unscoped().Model(&model.User{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", before).
+		Order("id").Limit(limit).Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	result := conn(ctx, r.db).Unscoped().Where("id IN ?", ids).Delete(&model.User{})
+	return result.RowsAffected, result.Error
+}
+
+// Each 按ID分批遍历用户，使用ID游标而不是OFFSET，深度翻页不会变慢
+func (r *userRepository) Each(ctx context.Context, batchSize int, fn func(users []*model.User) error) error {
+	var lastID uint
+	for {
+		var users []*model.User
+		err := conn(ctx, r.db).Where("id > ?", lastID).Order("id").Limit(batchSize).Find(&users).Error
+		if err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return nil
+		}
+		if err := fn(users); err != nil {
+			return err
+		}
+		if len(users) < batchSize {
+			return nil
+		}
+		lastID = users[len(users)-1].ID
+	}
+}
+
+// Restore 恢复软删除的用户
+func (r *userRepository) Restore(ctx context.Context, id uint) error {
+	result := conn(ctx, r.db).Unscoped().Model(&model.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
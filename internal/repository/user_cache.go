@@ -0,0 +1,259 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"strconv"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/pkg/cache"
+	"go-api-scaffold/pkg/database"
+	"go-api-scaffold/pkg/logger"
+)
+
+// notFoundMarker "用户不存在"的缓存标记
+var notFoundMarker = []byte{0}
+
+// UserCacheOptions 用户缓存选项
+type UserCacheOptions struct {
+	TTL         time.Duration
+	NegativeTTL time.Duration
+	Stats       *cache.Stats
+}
+
+// cachedUserRepository 用户仓储的旁路缓存装饰器
+// 读操作优先查缓存，并发未命中通过singleflight合并为一次查询；写操作在事务提交后失效缓存
+type cachedUserRepository struct {
+	UserRepository
+	store  cache.Store
+	opts   UserCacheOptions
+	group  singleflight.Group
+	logger logger.Logger
+}
+
+// NewCachedUserRepository 创建带缓存的用户仓储
+func NewCachedUserRepository(inner UserRepository, store cache.Store, opts UserCacheOptions, logger logger.Logger) UserRepository {
+	if opts.Stats == nil {
+		opts.Stats = &cache.Stats{}
+	}
+	return &cachedUserRepository{
+		UserRepository: inner,
+		store:          store,
+		opts:           opts,
+		logger:         logger.WithField("component", "user_cache"),
+	}
+}
+
+// GetByID 根据ID获取用户
+func (r *cachedUserRepository) GetByID(ctx context.Context, id uint) (*model.User, error) {
+	return r.load(ctx, userIDKey(id), func(ctx context.Context) (*model.User, error) {
+		return r.UserRepository.GetByID(ctx, id)
+	})
+}
+
+// GetByUsername 根据用户名获取用户
+func (r *cachedUserRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	return r.load(ctx, userUsernameKey(username), func(ctx context.Context) (*model.User, error) {
+		return r.UserRepository.GetByUsername(ctx, username)
+	})
+}
+
+// GetByEmail 根据邮箱获取用户
+func (r *cachedUserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	return r.load(ctx, userEmailKey(email), func(ctx context.Context) (*model.User, error) {
+		return r.UserRepository.GetByEmail(ctx, email)
+	})
+}
+
+// Create 创建用户，清除可能存在的"不存在"缓存
+func (r *cachedUserRepository) Create(ctx context.Context, user *model.User) error {
+	if err := r.UserRepository.Create(ctx, user); err != nil {
+		return err
+	}
+	r.invalidate(ctx, user)
+	return nil
+}
+
+// Update 更新用户
+func (r *cachedUserRepository) Update(ctx context.Context, user *model.User) error {
+	if err := r.UserRepository.Update(ctx, user); err != nil {
+		return err
+	}
+	r.invalidate(ctx, user)
+	return nil
+}
+
+// TouchLastLogin 更新最后登录时间，只失效ID缓存键
+// 每次登录都会调用，失效全部键会让按用户名、邮箱的缓存形同虚设；这两个键缓存的最后登录时间允许滞后
+func (r *cachedUserRepository) TouchLastLogin(ctx context.Context, id uint, at time.Time) error {
+	if err := r.UserRepository.TouchLastLogin(ctx, id, at); err != nil {
+		return err
+	}
+	r.invalidateKeys(ctx, userIDKey(id))
+	return nil
+}
+
+// Delete 删除用户
+func (r *cachedUserRepository) Delete(ctx context.Context, id uint) error {
+	// 删除前读取用户，以便失效用户名、邮箱对应的缓存
+	user, err := r.UserRepository.GetByID(ctx, id)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if err := r.UserRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	if user == nil {
+		user = &model.User{BaseModel: model.BaseModel{ID: id}}
+	}
+	r.invalidate(ctx, user)
+	return nil
+}
+
+// load 旁路缓存读取
+func (r *cachedUserRepository) load(ctx context.Context, key string, fetch func(ctx context.Context) (*model.User, error)) (*model.User, error) {
+	// 事务内或要求读主库时绕过缓存，避免读到过期数据或缓存未提交数据
+	if InTransaction(ctx) || database.PrimaryRequested(ctx) {
+		return fetch(ctx)
+	}
+
+	if user, found, ok := r.get(ctx, key); ok {
+		if !found {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return user, nil
+	}
+
+	// 合并并发未命中，同一键只查询一次数据库
+	v, err, _ := r.group.Do(key, func() (interface{}, error) {
+		user, err := fetch(ctx)
+		switch {
+		case err == nil:
+			r.set(ctx, key, user)
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			r.setNotFound(ctx, key)
+		}
+		return user, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 返回副本，避免调用方修改共享对象
+	user := *v.(*model.User)
+	return &user, nil
+}
+
+// get 读取缓存，ok表示命中（含"不存在"标记），found表示用户存在
+func (r *cachedUserRepository) get(ctx context.Context, key string) (user *model.User, found bool, ok bool) {
+	data, hit, err := r.store.Get(ctx, key)
+	if err != nil {
+		r.opts.Stats.Error()
+		r.logger.WithContext(ctx).WithError(err).Warn("Failed to read user cache")
+		return nil, false, false
+	}
+	if !hit {
+		r.opts.Stats.Miss()
+		return nil, false, false
+	}
+	if bytes.Equal(data, notFoundMarker) {
+		r.opts.Stats.NegativeHit()
+		return nil, false, true
+	}
+
+	user = &model.User{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(user); err != nil {
+		r.opts.Stats.Error()
+		r.logger.WithContext(ctx).WithError(err).Warn("Failed to decode cached user")
+		return nil, false, false
+	}
+	r.opts.Stats.Hit()
+	return user, true, true
+}
+
+// set 写入用户缓存，使用gob编码以保留json中忽略的字段（如密码哈希）
+func (r *cachedUserRepository) set(ctx context.Context, key string, user *model.User) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(user); err != nil {
+		r.opts.Stats.Error()
+		r.logger.WithContext(ctx).WithError(err).Warn("Failed to encode user cache")
+		return
+	}
+	if err := r.store.Set(ctx, key, buf.Bytes(), r.opts.TTL); err != nil {
+		r.opts.Stats.Error()
+		r.logger.WithContext(ctx).WithError(err).Warn("Failed to write user cache")
+	}
+}
+
+// setNotFound 写入"不存在"标记
+func (r *cachedUserRepository) setNotFound(ctx context.Context, key string) {
+	if r.opts.NegativeTTL <= 0 {
+		return
+	}
+	if err := r.store.Set(ctx, key, notFoundMarker, r.opts.NegativeTTL); err != nil {
+		r.opts.Stats.Error()
+		r.logger.WithContext(ctx).WithError(err).Warn("Failed to write user cache")
+	}
+}
+
+// Restore 恢复用户，失效此前缓存的"不存在"结果
+func (r *cachedUserRepository) Restore(ctx context.Context, id uint) error {
+	if err := r.UserRepository.Restore(ctx, id); err != nil {
+		return err
+	}
+
+	user, err := r.UserRepository.GetByID(ctx, id)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if user == nil {
+		user = &model.User{BaseModel: model.BaseModel{ID: id}}
+	}
+	r.invalidate(ctx, user)
+	return nil
+}
+
+// invalidate 失效用户相关的全部缓存键
+func (r *cachedUserRepository) invalidate(ctx context.Context, user *model.User) {
+	keys := []string{userIDKey(user.ID)}
+	if user.Username != "" {
+		keys = append(keys, userUsernameKey(user.Username))
+	}
+	if user.Email != "" {
+		keys = append(keys, userEmailKey(user.Email))
+	}
+	r.invalidateKeys(ctx, keys...)
+}
+
+// invalidateKeys 失效指定缓存键，处于事务中时延迟到提交后执行
+func (r *cachedUserRepository) invalidateKeys(ctx context.Context, keys ...string) {
+	AfterCommit(ctx, func(ctx context.Context) {
+		if err := r.store.Delete(ctx, keys...); err != nil {
+			r.opts.Stats.Error()
+			r.logger.WithContext(ctx).WithError(err).Error("Failed to invalidate user cache")
+		}
+	})
+}
+
+// userIDKey 用户ID缓存键
+func userIDKey(id uint) string {
+	return "id:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// userUsernameKey 用户名缓存键
+func userUsernameKey(username string) string {
+	return "username:" + username
+}
+
+// userEmailKey 邮箱缓存键
+func userEmailKey(email string) string {
+	return "email:" + email
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/pkg/cache"
+	"go-api-scaffold/pkg/logger"
+)
+
+// stubUserRepository 统计读取次数的用户仓储桩
+type stubUserRepository struct {
+	UserRepository
+	user  model.User
+	reads int
+}
+
+func (r *stubUserRepository) GetByID(_ context.Context, id uint) (*model.User, error) {
+	r.reads++
+	if id != r.user.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	user := r.user
+	return &user, nil
+}
+
+func (r *stubUserRepository) GetByUsername(_ context.Context, username string) (*model.User, error) {
+	r.reads++
+	if username != r.user.Username {
+		return nil, gorm.ErrRecordNotFound
+	}
+	user := r.user
+	return &user, nil
+}
+
+func (r *stubUserRepository) Update(_ context.Context, user *model.User) error {
+	r.user = *user
+	return nil
+}
+
+func (r *stubUserRepository) TouchLastLogin(_ context.Context, _ uint, at time.Time) error {
+	r.user.LastLogin = &at
+	return nil
+}
+
+func newCachedStub() (*stubUserRepository, *cache.MemoryStore, UserRepository) {
+	stub := &stubUserRepository{user: model.User{BaseModel: model.BaseModel{ID: 1}, Username: "alice", Email: "alice@example.com"}}
+	store := cache.NewMemoryStore(100)
+	log := logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"})
+	repo := NewCachedUserRepository(stub, store, UserCacheOptions{TTL: time.Minute, NegativeTTL: time.Minute}, log)
+	return stub, store, repo
+}
+
+// cached 缓存键是否存在
+func cached(t *testing.T, store cache.Store, key string) bool {
+	t.Helper()
+	_, ok, err := store.Get(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ok
+}
+
+func TestCachedUserRepositoryReads(t *testing.T) {
+	stub, _, repo := newCachedStub()
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := repo.GetByID(ctx, 1); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.GetByID(ctx, 2); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("GetByID(missing) error = %v", err)
+		}
+	}
+	if stub.reads != 2 {
+		t.Fatalf("inner reads = %d, want 2 (hit and negative cache)", stub.reads)
+	}
+}
+
+func TestCachedUserRepositoryTouchLastLogin(t *testing.T) {
+	_, store, repo := newCachedStub()
+	ctx := context.Background()
+	_, _ = repo.GetByID(ctx, 1)
+	_, _ = repo.GetByUsername(ctx, "alice")
+
+	if err := repo.TouchLastLogin(ctx, 1, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if cached(t, store, userIDKey(1)) {
+		t.Fatal("id key still cached after TouchLastLogin")
+	}
+	if !cached(t, store, userUsernameKey("alice")) {
+		t.Fatal("username key invalidated by TouchLastLogin")
+	}
+
+	user, err := repo.GetByID(ctx, 1)
+	if err != nil || user.LastLogin == nil {
+		t.Fatalf("GetByID() after login = %+v, %v", user, err)
+	}
+}
+
+func TestCachedUserRepositoryUpdateInvalidatesAllKeys(t *testing.T) {
+	_, store, repo := newCachedStub()
+	ctx := context.Background()
+	user, _ := repo.GetByID(ctx, 1)
+	_, _ = repo.GetByUsername(ctx, "alice")
+
+	user.Nickname = "Alice"
+	if err := repo.Update(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{userIDKey(1), userUsernameKey("alice"), userEmailKey("alice@example.com")} {
+		if cached(t, store, key) {
+			t.Fatalf("%s still cached after Update", key)
+		}
+	}
+}
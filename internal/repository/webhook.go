@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"go-api-scaffold/internal/model"
+)
+
+// WebhookRepository Webhook订阅及投递记录仓储接口
+type WebhookRepository interface {
+	Create(ctx context.Context, sub *model.WebhookSubscription) error
+	GetByID(ctx context.Context, id uint) (*model.WebhookSubscription, error)
+	Update(ctx context.Context, sub *model.WebhookSubscription) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, offset, limit int) ([]*model.WebhookSubscription, int64, error)
+	ListActive(ctx context.Context) ([]*model.WebhookSubscription, error)
+
+	CreateDeliveries(ctx context.Context, deliveries ...*model.WebhookDelivery) error
+	GetDelivery(ctx context.Context, subscriptionID, id uint) (*model.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error
+	ListDeliveries(ctx context.Context, subscriptionID uint, status string, offset, limit int) ([]*model.WebhookDelivery, int64, error)
+	// ClaimDueDeliveries 领取到期的待投递记录并将下次投递时间推迟lease，避免多实例重复领取
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*model.WebhookDelivery, error)
+}
+
+// webhookRepository Webhook仓储实现
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository 创建Webhook仓储实例
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+// Create 创建订阅
+func (r *webhookRepository) Create(ctx context.Context, sub *model.WebhookSubscription) error {
+	return conn(ctx, r.db).Create(sub).Error
+}
+
+// GetByID 根据ID获取订阅
+func (r *webhookRepository) GetByID(ctx context.Context, id uint) (*model.WebhookSubscription, error) {
+	var sub model.WebhookSubscription
+	err := conn(ctx, r.db).First(&sub, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// Update 更新订阅
+func (r *webhookRepository) Update(ctx context.Context, sub *model.WebhookSubscription) error {
+	return conn(ctx, r.db).Save(sub).Error
+}
+
+// Delete 删除订阅（软删除），历史投递记录保留
+func (r *webhookRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&model.WebhookSubscription{}, id).Error
+}
+
+// List 获取订阅列表
+func (r *webhookRepository) List(ctx context.Context, offset, limit int) ([]*model.WebhookSubscription, int64, error) {
+	var subs []*model.WebhookSubscription
+	var total int64
+
+	query := conn(ctx, r.db).Model(&model.WebhookSubscription{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("id").Offset(offset).Limit(limit).Find(&subs).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return subs, total, nil
+}
+
+// ListActive 获取全部启用的订阅
+func (r *webhookRepository) ListActive(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	var subs []*model.WebhookSubscription
+	err := conn(ctx, r.db).Where("active = ?", true).Order("id").Find(&subs).Error
+	return subs, err
+}
+
+// CreateDeliveries 批量写入投递记录，处于事务中时随事务提交
+func (r *webhookRepository) CreateDeliveries(ctx context.Context, deliveries ...*model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return conn(ctx, r.db).Omit(clause.Associations).Create(deliveries).Error
+}
+
+// GetDelivery 获取订阅下的投递记录
+func (r *webhookRepository) GetDelivery(ctx context.Context, subscriptionID, id uint) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	err := conn(ctx, r.db).Where("subscription_id = ?", subscriptionID).First(&delivery, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// UpdateDelivery 更新投递状态
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return conn(ctx, r.db).Omit(clause.Associations).Save(delivery).Error
+}
+
+// ListDeliveries 按时间倒序获取订阅的投递记录，status为空时不过滤
+func (r *webhookRepository) ListDeliveries(ctx context.Context, subscriptionID uint, status string, offset, limit int) ([]*model.WebhookDelivery, int64, error) {
+	var deliveries []*model.WebhookDelivery
+	var total int64
+
+	query := conn(ctx, r.db).Model(&model.WebhookDelivery{}).Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("id DESC").Offset(offset).Limit(limit).Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
+
+// ClaimDueDeliveries 领取到期投递记录，同时预加载订阅（已删除的订阅不会加载）
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.WebhookDeliveryPending, now).
+			Order("next_attempt_at, id").
+			Limit(limit).
+			Find(&deliveries).Error
+		if err != nil || len(deliveries) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i, d := range deliveries {
+			ids[i] = d.ID
+			d.NextAttemptAt = now.Add(lease)
+		}
+		return tx.Model(&model.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil || len(deliveries) == 0 {
+		return nil, err
+	}
+
+	// 订阅在事务外加载，避免锁定订阅表
+	subIDs := make([]uint, 0, len(deliveries))
+	for _, d := range deliveries {
+		subIDs = append(subIDs, d.SubscriptionID)
+	}
+	var subs []*model.WebhookSubscription
+	if err := conn(ctx, r.db).Where("id IN ?", subIDs).Find(&subs).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]*model.WebhookSubscription, len(subs))
+	for _, s := range subs {
+		byID[s.ID] = s
+	}
+	for _, d := range deliveries {
+		d.Subscription = byID[d.SubscriptionID]
+	}
+	return deliveries, nil
+}
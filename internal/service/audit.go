@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/internal/repository"
+	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/response"
+	"go-api-scaffold/pkg/strutil"
+)
+
+// AuditEntry 审计事件
+type AuditEntry struct {
+	Action       string
+	ResourceType string
+	ResourceID   string
+	ActorID      string      // 为空时取上下文中的当前用户
+	Before       interface{} // 变更前状态，创建时为nil
+	After        interface{} // 变更后状态，删除时为nil
+}
+
+// AuditService 审计服务接口
+type AuditService interface {
+	// Record 记录审计事件，ctx处于事务中时与业务变更一起提交
+	Record(ctx context.Context, entry AuditEntry) error
+	List(ctx context.Context, filter model.AuditLogFilter, page, pageSize int) ([]*model.AuditLog, *response.PageMeta, error)
+}
+
+// auditService 审计服务实现
+type auditService struct {
+	repo   repository.AuditRepository
+	logger logger.Logger
+}
+
+// NewAuditService 创建审计服务实例
+func NewAuditService(repo repository.AuditRepository, logger logger.Logger) AuditService {
+	return &auditService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Record 记录审计事件
+func (s *auditService) Record(ctx context.Context, entry AuditEntry) error {
+	changes, err := auditDiff(entry.Before, entry.After)
+	if err != nil {
+		return err
+	}
+
+	actorID := entry.ActorID
+	if actorID == "" {
+		actorID = logger.UserIDFromContext(ctx)
+	}
+	client := logger.ClientInfoFromContext(ctx)
+
+	log := &model.AuditLog{
+		ActorID:      actorID,
+		Action:       entry.Action,
+		ResourceType: entry.ResourceType,
+		ResourceID:   entry.ResourceID,
+		Changes:      changes,
+		IP:           client.IP,
+		UserAgent:    strutil.Truncate(client.UserAgent, 255),
+		RequestID:    logger.RequestIDFromContext(ctx),
+	}
+	if err := s.repo.Create(ctx, log); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to write audit log")
+		return errors.New("failed to write audit log")
+	}
+	return nil
+}
+
+// List 查询审计日志
+func (s *auditService) List(ctx context.Context, filter model.AuditLogFilter, page, pageSize int) ([]*model.AuditLog, *response.PageMeta, error) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+
+	offset := (page - 1) * pageSize
+	logs, total, err := s.repo.List(ctx, filter, offset, pageSize)
+	if err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to get audit logs")
+		return nil, nil, errors.New("failed to get audit logs")
+	}
+
+	meta := &response.PageMeta{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}
+	return logs, meta, nil
+}
+
+// auditDiff 计算变更前后差异，按json字段名比较，json中忽略的字段（如密码）不会记录
+func auditDiff(before, after interface{}) (string, error) {
+	beforeFields, err := toFieldMap(before)
+	if err != nil {
+		return "", err
+	}
+	afterFields, err := toFieldMap(after)
+	if err != nil {
+		return "", err
+	}
+
+	changes := make(map[string]model.AuditChange)
+	for name, value := range afterFields {
+		old, ok := beforeFields[name]
+		if ok && reflect.DeepEqual(old, value) {
+			continue
+		}
+		changes[name] = model.AuditChange{Before: old, After: value}
+	}
+	for name, value := range beforeFields {
+		if _, ok := afterFields[name]; !ok {
+			changes[name] = model.AuditChange{Before: value}
+		}
+	}
+
+	if len(changes) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// toFieldMap 将结构体按json编码规则转换为字段映射
+func toFieldMap(v interface{}) (map[string]interface{}, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Ptr && reflect.ValueOf(v).IsNil()) {
+		return map[string]interface{}{}, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,17 @@
+package service
+
+import "errors"
+
+// 业务错误，处理器据此映射HTTP状态码
+var (
+	ErrUserNotFound   = errors.New("user not found")
+	ErrUsernameExists = errors.New("username already exists")
+	ErrEmailExists    = errors.New("email already exists")
+	ErrUserExists     = errors.New("user already exists")
+	ErrUserNotDeleted = errors.New("user is not deleted")
+
+	ErrWebhookNotFound     = errors.New("webhook not found")
+	ErrDeliveryNotFound    = errors.New("webhook delivery not found")
+	ErrInvalidWebhookEvent = errors.New("invalid webhook event")
+	ErrInvalidWebhookURL   = errors.New("invalid webhook url")
+)
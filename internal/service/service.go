@@ -1,18 +1,27 @@
 package service
 
 import (
+	"go-api-scaffold/internal/event"
 	"go-api-scaffold/internal/repository"
+	"go-api-scaffold/pkg/eventbus"
 	"go-api-scaffold/pkg/logger"
 )
 
 // Service 服务接口集合
 type Service struct {
-	User UserService
+	User    UserService
+	Audit   AuditService
+	Webhook WebhookService
 }
 
-// New 创建服务实例
-func New(repo *repository.Repository, logger logger.Logger) *Service {
+// New 创建服务实例，领域事件写入outbox并在提交后分发到bus
+func New(repo *repository.Repository, bus *eventbus.Bus, logger logger.Logger) *Service {
+	audit := NewAuditService(repo.Audit, logger)
+	events := event.NewPublisher(repo.Outbox, bus, logger)
+
 	return &Service{
-		User: NewUserService(repo.User, logger),
+		User:    NewTracedUserService(NewUserService(repo.User, repo.Tx, audit, events, logger)),
+		Audit:   audit,
+		Webhook: NewWebhookService(repo.Webhook, logger),
 	}
 }
@@ -1,64 +1,87 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
+	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/sync/errgroup"
 	"gorm.io/gorm"
 
+	"go-api-scaffold/internal/event"
 	"go-api-scaffold/internal/model"
 	"go-api-scaffold/internal/repository"
+	"go-api-scaffold/pkg/database"
 	"go-api-scaffold/pkg/logger"
 	"go-api-scaffold/pkg/response"
+	"go-api-scaffold/pkg/tracing"
 )
 
 // UserService 用户服务接口
 type UserService interface {
-	Create(req *model.UserCreateRequest) (*model.UserResponse, error)
-	GetByID(id uint) (*model.UserResponse, error)
-	Update(id uint, req *model.UserUpdateRequest) (*model.UserResponse, error)
-	Delete(id uint) error
-	List(page, pageSize int) ([]*model.UserResponse, *response.PageMeta, error)
-	Login(username, password string) (*model.UserResponse, error)
+	Create(ctx context.Context, req *model.UserCreateRequest) (*model.UserResponse, error)
+	GetByID(ctx context.Context, id uint) (*model.UserResponse, error)
+	Update(ctx context.Context, id uint, req *model.UserUpdateRequest) (*model.UserResponse, error)
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, page, pageSize int) ([]*model.UserResponse, *response.PageMeta, error)
+	Login(ctx context.Context, username, password string) (*model.UserResponse, error)
+	// PurgeDeleted 物理删除before之前软删除的用户，返回删除数量
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+	// Import 批量导入用户，逐行创建，单行失败不影响其他行；dryRun时只校验不写入
+	Import(ctx context.Context, records []model.UserImportRecord, dryRun bool) (*model.UserImportResult, error)
+	// Export 按ID顺序逐个输出全部用户，分批读取，不会一次加载整张表
+	Export(ctx context.Context, fn func(user *model.UserResponse) error) error
+	// Batch 对多个用户执行同一操作，atomic模式下任一失败全部回滚，best_effort模式下逐个执行
+	Batch(ctx context.Context, req *model.UserBatchRequest) (*model.UserBatchResult, error)
 }
 
 // userService 用户服务实现
 type userService struct {
 	repo   repository.UserRepository
+	tx     repository.TxManager
+	audit  AuditService
+	events event.Publisher
 	logger logger.Logger
 }
 
 // NewUserService 创建用户服务实例
-func NewUserService(repo repository.UserRepository, logger logger.Logger) UserService {
+func NewUserService(repo repository.UserRepository, tx repository.TxManager, audit AuditService, events event.Publisher, logger logger.Logger) UserService {
 	return &userService{
 		repo:   repo,
+		tx:     tx,
+		audit:  audit,
+		events: events,
 		logger: logger,
 	}
 }
 
 // Create 创建用户
-func (s *userService) Create(req *model.UserCreateRequest) (*model.UserResponse, error) {
-	// 检查用户名是否存在
-	if _, err := s.repo.GetByUsername(req.Username); err == nil {
-		return nil, errors.New("username already exists")
-	}
-
-	// 检查邮箱是否存在
-	if _, err := s.repo.GetByEmail(req.Email); err == nil {
-		return nil, errors.New("email already exists")
-	}
-
-	// 加密密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+func (s *userService) Create(ctx context.Context, req *model.UserCreateRequest) (*model.UserResponse, error) {
+	// 加密密码，耗时操作放在事务外
+	hashedPassword, err := hashPassword(ctx, req.Password)
 	if err != nil {
-		s.logger.Error(fmt.Sprintf("Failed to hash password: %v", err))
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to hash password")
 		return nil, errors.New("failed to create user")
 	}
+	return s.create(ctx, req, hashedPassword)
+}
 
-	// 创建用户
+// hashPassword 加密密码
+func hashPassword(ctx context.Context, password string) ([]byte, error) {
+	_, span := tracing.Start(ctx, "bcrypt.GenerateFromPassword")
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	tracing.End(span, err)
+	return hashedPassword, err
+}
+
+// create 使用已加密的密码创建用户
+func (s *userService) create(ctx context.Context, req *model.UserCreateRequest, hashedPassword []byte) (*model.UserResponse, error) {
 	user := &model.User{
 		Username: req.Username,
 		Email:    req.Email,
@@ -68,8 +91,40 @@ func (s *userService) Create(req *model.UserCreateRequest) (*model.UserResponse,
 		Status:   1,
 	}
 
-	if err := s.repo.Create(user); err != nil {
-		s.logger.Error(fmt.Sprintf("Failed to create user: %v", err))
+	// 唯一性检查与插入在同一事务中执行，并发冲突由唯一索引兜底
+	// 写操作中的读取强制走主库，不使用从库或缓存中可能过期的数据
+	ctx = database.WithPrimary(ctx)
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// 检查用户名是否存在
+		if _, err := s.repo.GetByUsername(ctx, req.Username); err == nil {
+			return ErrUsernameExists
+		}
+
+		// 检查邮箱是否存在
+		if _, err := s.repo.GetByEmail(ctx, req.Email); err == nil {
+			return ErrEmailExists
+		}
+
+		if err := s.repo.Create(ctx, user); err != nil {
+			return err
+		}
+
+		if err := s.audit.Record(ctx, AuditEntry{
+			Action:       model.AuditActionCreate,
+			ResourceType: auditResourceUser,
+			ResourceID:   auditID(user.ID),
+			After:        s.toUserResponse(user),
+		}); err != nil {
+			return err
+		}
+
+		return s.events.Publish(ctx, event.UserCreated{User: s.toUserResponse(user)})
+	})
+	if err != nil {
+		if conflict := conflictError(err); conflict != nil {
+			return nil, conflict
+		}
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to create user")
 		return nil, errors.New("failed to create user")
 	}
 
@@ -77,13 +132,13 @@ func (s *userService) Create(req *model.UserCreateRequest) (*model.UserResponse,
 }
 
 // GetByID 根据ID获取用户
-func (s *userService) GetByID(id uint) (*model.UserResponse, error) {
-	user, err := s.repo.GetByID(id)
+func (s *userService) GetByID(ctx context.Context, id uint) (*model.UserResponse, error) {
+	user, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, ErrUserNotFound
 		}
-		s.logger.Error(fmt.Sprintf("Failed to get user by ID: %v", err))
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to get user by ID")
 		return nil, errors.New("failed to get user")
 	}
 
@@ -91,32 +146,58 @@ func (s *userService) GetByID(id uint) (*model.UserResponse, error) {
 }
 
 // Update 更新用户
-func (s *userService) Update(id uint, req *model.UserUpdateRequest) (*model.UserResponse, error) {
-	user, err := s.repo.GetByID(id)
-	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+func (s *userService) Update(ctx context.Context, id uint, req *model.UserUpdateRequest) (*model.UserResponse, error) {
+	var user *model.User
+
+	// 先读后写放在同一事务中并读主库，避免基于过期数据覆盖
+	ctx = database.WithPrimary(ctx)
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
 		}
-		s.logger.Error(fmt.Sprintf("Failed to get user by ID: %v", err))
-		return nil, errors.New("failed to update user")
-	}
+		before := s.toUserResponse(user)
 
-	// 更新字段
-	if req.Nickname != "" {
-		user.Nickname = req.Nickname
-	}
-	if req.Avatar != "" {
-		user.Avatar = req.Avatar
-	}
-	if req.Phone != "" {
-		user.Phone = req.Phone
-	}
-	if req.Status != nil {
-		user.Status = *req.Status
-	}
+		// 更新字段
+		if req.Nickname != "" {
+			user.Nickname = req.Nickname
+		}
+		if req.Avatar != "" {
+			user.Avatar = req.Avatar
+		}
+		if req.Phone != "" {
+			user.Phone = req.Phone
+		}
+		if req.Status != nil {
+			user.Status = *req.Status
+		}
 
-	if err := s.repo.Update(user); err != nil {
-		s.logger.Error(fmt.Sprintf("Failed to update user: %v", err))
+		if err := s.repo.Update(ctx, user); err != nil {
+			return err
+		}
+
+		after := s.toUserResponse(user)
+		if err := s.audit.Record(ctx, AuditEntry{
+			Action:       model.AuditActionUpdate,
+			ResourceType: auditResourceUser,
+			ResourceID:   auditID(user.ID),
+			Before:       before,
+			After:        after,
+		}); err != nil {
+			return err
+		}
+
+		return s.events.Publish(ctx, event.UserUpdated{Before: before, After: after})
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		if conflict := conflictError(err); conflict != nil {
+			return nil, conflict
+		}
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to update user")
 		return nil, errors.New("failed to update user")
 	}
 
@@ -124,17 +205,33 @@ func (s *userService) Update(id uint, req *model.UserUpdateRequest) (*model.User
 }
 
 // Delete 删除用户
-func (s *userService) Delete(id uint) error {
-	if _, err := s.repo.GetByID(id); err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("user not found")
+func (s *userService) Delete(ctx context.Context, id uint) error {
+	ctx = database.WithPrimary(ctx)
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		user, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		if err := s.audit.Record(ctx, AuditEntry{
+			Action:       model.AuditActionDelete,
+			ResourceType: auditResourceUser,
+			ResourceID:   auditID(id),
+			Before:       s.toUserResponse(user),
+		}); err != nil {
+			return err
 		}
-		s.logger.Error(fmt.Sprintf("Failed to get user by ID: %v", err))
-		return errors.New("failed to delete user")
-	}
 
-	if err := s.repo.Delete(id); err != nil {
-		s.logger.Error(fmt.Sprintf("Failed to delete user: %v", err))
+		return s.events.Publish(ctx, event.UserDeleted{User: s.toUserResponse(user)})
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to delete user")
 		return errors.New("failed to delete user")
 	}
 
@@ -142,7 +239,7 @@ func (s *userService) Delete(id uint) error {
 }
 
 // List 获取用户列表
-func (s *userService) List(page, pageSize int) ([]*model.UserResponse, *response.PageMeta, error) {
+func (s *userService) List(ctx context.Context, page, pageSize int) ([]*model.UserResponse, *response.PageMeta, error) {
 	if page <= 0 {
 		page = 1
 	}
@@ -151,9 +248,9 @@ func (s *userService) List(page, pageSize int) ([]*model.UserResponse, *response
 	}
 
 	offset := (page - 1) * pageSize
-	users, total, err := s.repo.List(offset, pageSize)
+	users, total, err := s.repo.List(ctx, offset, pageSize)
 	if err != nil {
-		s.logger.Error(fmt.Sprintf("Failed to get user list: %v", err))
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to get user list")
 		return nil, nil, errors.New("failed to get user list")
 	}
 
@@ -176,41 +273,362 @@ func (s *userService) List(page, pageSize int) ([]*model.UserResponse, *response
 }
 
 // Login 用户登录
-func (s *userService) Login(username, password string) (*model.UserResponse, error) {
+func (s *userService) Login(ctx context.Context, username, password string) (*model.UserResponse, error) {
 	// 根据用户名或邮箱查找用户
 	var user *model.User
 	var err error
 
 	// 先尝试用户名
-	user, err = s.repo.GetByUsername(username)
+	user, err = s.repo.GetByUsername(ctx, username)
 	if err != nil {
 		// 再尝试邮箱
-		user, err = s.repo.GetByEmail(username)
+		user, err = s.repo.GetByEmail(ctx, username)
 		if err != nil {
+			s.recordLoginFailure(ctx, username, nil, "user not found")
 			return nil, errors.New("invalid username or password")
 		}
 	}
 
 	// 检查用户状态
 	if user.Status != 1 {
+		s.recordLoginFailure(ctx, username, user, "account disabled")
 		return nil, errors.New("user account is disabled")
 	}
 
-	// 验证密码
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+	// 验证密码，密码不匹配属于正常分支，不标记span错误
+	_, span := tracing.Start(ctx, "bcrypt.CompareHashAndPassword")
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	span.End()
+	if err != nil {
+		s.recordLoginFailure(ctx, username, user, "invalid password")
 		return nil, errors.New("invalid username or password")
 	}
 
-	// 更新最后登录时间
+	// 更新最后登录时间并记录登录审计
+	// 用户可能读自缓存或从库，只写登录时间一列，避免用旧数据覆盖并发修改的昵称、状态或密码
 	now := time.Now()
 	user.LastLogin = &now
-	if err := s.repo.Update(user); err != nil {
-		s.logger.Warn(fmt.Sprintf("Failed to update last login time: %v", err))
+	user.UpdatedAt = now
+	err = s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.TouchLastLogin(ctx, user.ID, now); err != nil {
+			return err
+		}
+		if err := s.audit.Record(ctx, AuditEntry{
+			Action:       model.AuditActionLogin,
+			ResourceType: auditResourceUser,
+			ResourceID:   auditID(user.ID),
+			ActorID:      auditID(user.ID),
+		}); err != nil {
+			return err
+		}
+
+		client := logger.ClientInfoFromContext(ctx)
+		return s.events.Publish(ctx, event.UserLoggedIn{
+			UserID:    user.ID,
+			IP:        client.IP,
+			UserAgent: client.UserAgent,
+		})
+	})
+	if err != nil {
+		s.logger.WithContext(ctx).WithError(err).Warn("Failed to update last login time")
+		return s.toUserResponse(user), nil
 	}
 
+	// 登录前的读取可能来自缓存或从库，写入后从主库重新读取，返回最新的用户信息
+	if fresh, err := s.repo.GetByID(database.WithPrimary(ctx), user.ID); err == nil {
+		user = fresh
+	}
 	return s.toUserResponse(user), nil
 }
 
+// purgeBatchSize 每批物理删除的用户数，避免长事务和大范围锁
+const purgeBatchSize = 500
+
+// PurgeDeleted 分批物理删除软删除的用户
+func (s *userService) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	var total int64
+	for {
+		n, err := s.repo.PurgeDeleted(ctx, before, purgeBatchSize)
+		total += n
+		if err != nil {
+			s.logger.WithContext(ctx).WithError(err).Error("Failed to purge deleted users")
+			return total, errors.New("failed to purge deleted users")
+		}
+		if n < purgeBatchSize {
+			return total, nil
+		}
+		if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
+}
+
+// exportBatchSize 导出时每批读取的用户数
+const exportBatchSize = 500
+
+// Import 批量导入用户
+// 文件内重复的用户名、邮箱只保留第一行；密码并发加密，创建按行顺序各自在独立事务中执行
+func (s *userService) Import(ctx context.Context, records []model.UserImportRecord, dryRun bool) (*model.UserImportResult, error) {
+	result := &model.UserImportResult{
+		DryRun: dryRun,
+		Total:  len(records),
+		Rows:   make([]model.UserImportRow, len(records)),
+	}
+
+	usernames := make(map[string]int, len(records))
+	emails := make(map[string]int, len(records))
+	for i, record := range records {
+		row := &result.Rows[i]
+		row.Row = record.Row
+		row.Username = record.Request.Username
+		row.Errors = record.Errors
+		if len(row.Errors) > 0 {
+			continue
+		}
+		if first, ok := usernames[strings.ToLower(record.Request.Username)]; ok {
+			row.Errors = append(row.Errors, fmt.Sprintf("username duplicates row %d", first))
+		} else {
+			usernames[strings.ToLower(record.Request.Username)] = record.Row
+		}
+		if first, ok := emails[strings.ToLower(record.Request.Email)]; ok {
+			row.Errors = append(row.Errors, fmt.Sprintf("email duplicates row %d", first))
+		} else {
+			emails[strings.ToLower(record.Request.Email)] = record.Row
+		}
+	}
+
+	var hashes [][]byte
+	if !dryRun {
+		var err error
+		if hashes, err = s.hashImportPasswords(ctx, records, result.Rows); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range records {
+		row := &result.Rows[i]
+		if len(row.Errors) == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			req := &records[i].Request
+			if dryRun {
+				if err := s.checkAvailable(ctx, req); err != nil {
+					row.Errors = append(row.Errors, err.Error())
+				} else {
+					row.Status = model.UserImportValid
+				}
+			} else if user, err := s.create(ctx, req, hashes[i]); err != nil {
+				row.Errors = append(row.Errors, err.Error())
+			} else {
+				row.Status = model.UserImportCreated
+				row.ID = user.ID
+			}
+		}
+
+		if len(row.Errors) > 0 {
+			row.Status = model.UserImportFailed
+			result.Failed++
+		} else {
+			result.Succeeded++
+		}
+	}
+
+	return result, nil
+}
+
+// hashImportPasswords 并发加密待导入行的密码，已失败的行跳过
+func (s *userService) hashImportPasswords(ctx context.Context, records []model.UserImportRecord, rows []model.UserImportRow) ([][]byte, error) {
+	hashes := make([][]byte, len(records))
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(runtime.GOMAXPROCS(0))
+	for i := range records {
+		if len(rows[i].Errors) > 0 {
+			continue
+		}
+		g.Go(func() error {
+			hash, err := hashPassword(ctx, records[i].Request.Password)
+			if err != nil {
+				s.logger.WithContext(ctx).WithError(err).Error("Failed to hash password")
+				return errors.New("failed to import users")
+			}
+			hashes[i] = hash
+			return ctx.Err()
+		})
+	}
+	return hashes, g.Wait()
+}
+
+// checkAvailable 检查用户名、邮箱是否已被使用
+func (s *userService) checkAvailable(ctx context.Context, req *model.UserCreateRequest) error {
+	if _, err := s.repo.GetByUsername(ctx, req.Username); err == nil {
+		return ErrUsernameExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to get user by username")
+		return errors.New("failed to check username")
+	}
+	if _, err := s.repo.GetByEmail(ctx, req.Email); err == nil {
+		return ErrEmailExists
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to get user by email")
+		return errors.New("failed to check email")
+	}
+	return nil
+}
+
+// Export 分批读取并逐个输出用户
+func (s *userService) Export(ctx context.Context, fn func(user *model.UserResponse) error) error {
+	return s.repo.Each(ctx, exportBatchSize, func(users []*model.User) error {
+		for _, user := range users {
+			if err := fn(s.toUserResponse(user)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// errBatchRolledBack 原子批量操作中有失败项，用于回滚整个事务
+var errBatchRolledBack = errors.New("batch rolled back")
+
+// Batch 批量操作用户
+// 每个用户复用单个操作的逻辑（审计、事件、缓存失效）；原子模式下单项在保存点中执行，
+// 有失败项时回滚外层事务，执行成功的项标记为rolled_back
+func (s *userService) Batch(ctx context.Context, req *model.UserBatchRequest) (*model.UserBatchResult, error) {
+	result := &model.UserBatchResult{
+		Operation: req.Operation,
+		Mode:      req.Mode,
+		Items:     make([]model.UserBatchItem, len(req.IDs)),
+	}
+	if result.Mode == "" {
+		result.Mode = model.UserBatchAtomic
+	}
+
+	if result.Mode == model.UserBatchBestEffort {
+		if err := s.runBatch(ctx, req, result); err != nil {
+			return nil, err
+		}
+		return result, nil
+	}
+
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.runBatch(ctx, req, result); err != nil {
+			return err
+		}
+		if result.Failed > 0 {
+			return errBatchRolledBack
+		}
+		return nil
+	})
+	if errors.Is(err, errBatchRolledBack) {
+		result.RolledBack = true
+		result.Succeeded = 0
+		for i := range result.Items {
+			if result.Items[i].Status == model.UserBatchSucceeded {
+				result.Items[i].Status = model.UserBatchRolledBack
+			}
+		}
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// runBatch 按顺序对每个用户执行操作并记录结果
+func (s *userService) runBatch(ctx context.Context, req *model.UserBatchRequest, result *model.UserBatchResult) error {
+	for i, id := range req.IDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var err error
+		switch req.Operation {
+		case model.UserBatchSetStatus:
+			_, err = s.Update(ctx, id, &model.UserUpdateRequest{Status: req.Status})
+		case model.UserBatchDelete:
+			err = s.Delete(ctx, id)
+		case model.UserBatchRestore:
+			err = s.restore(ctx, id)
+		default:
+			return fmt.Errorf("unsupported batch operation %q", req.Operation)
+		}
+
+		item := &result.Items[i]
+		item.ID = id
+		if err != nil {
+			item.Status = model.UserBatchFailed
+			item.Err = err
+			result.Failed++
+		} else {
+			item.Status = model.UserBatchSucceeded
+			result.Succeeded++
+		}
+	}
+	return nil
+}
+
+// restore 恢复软删除的用户
+func (s *userService) restore(ctx context.Context, id uint) error {
+	// 恢复后重新读取用户，从库可能尚未同步
+	ctx = database.WithPrimary(ctx)
+	err := s.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Restore(ctx, id); err != nil {
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			// 区分用户不存在与用户未被删除
+			if _, getErr := s.repo.GetByID(ctx, id); getErr == nil {
+				return ErrUserNotDeleted
+			} else if !errors.Is(getErr, gorm.ErrRecordNotFound) {
+				return getErr
+			}
+			return err
+		}
+
+		user, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.audit.Record(ctx, AuditEntry{
+			Action:       model.AuditActionRestore,
+			ResourceType: auditResourceUser,
+			ResourceID:   auditID(id),
+			After:        s.toUserResponse(user),
+		}); err != nil {
+			return err
+		}
+
+		return s.events.Publish(ctx, event.UserRestored{User: s.toUserResponse(user)})
+	})
+	if err != nil {
+		if errors.Is(err, ErrUserNotDeleted) {
+			return err
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to restore user")
+		return errors.New("failed to restore user")
+	}
+
+	return nil
+}
+
+// recordLoginFailure 记录登录失败审计，失败不影响登录流程
+func (s *userService) recordLoginFailure(ctx context.Context, username string, user *model.User, reason string) {
+	entry := AuditEntry{
+		Action:       model.AuditActionLoginFailed,
+		ResourceType: auditResourceUser,
+		After:        map[string]string{"username": username, "reason": reason},
+	}
+	if user != nil {
+		entry.ResourceID = auditID(user.ID)
+	}
+	_ = s.audit.Record(ctx, entry)
+}
+
 // toUserResponse 转换为用户响应格式
 func (s *userService) toUserResponse(user *model.User) *model.UserResponse {
 	resp := &model.UserResponse{
@@ -231,3 +649,31 @@ func (s *userService) toUserResponse(user *model.User) *model.UserResponse {
 
 	return resp
 }
+
+// auditResourceUser 用户资源的审计类型
+const auditResourceUser = "user"
+
+// auditID 格式化审计资源ID
+func auditID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// conflictError 将唯一约束冲突转换为业务错误，非冲突错误返回nil
+func conflictError(err error) error {
+	if errors.Is(err, ErrUsernameExists) || errors.Is(err, ErrEmailExists) {
+		return err
+	}
+
+	var conflict *repository.ConflictError
+	if !errors.As(err, &conflict) {
+		return nil
+	}
+	switch conflict.Column {
+	case "username":
+		return ErrUsernameExists
+	case "email":
+		return ErrEmailExists
+	default:
+		return ErrUserExists
+	}
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/internal/repository"
+	"go-api-scaffold/pkg/database"
+	"go-api-scaffold/pkg/eventbus"
+	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/response"
+)
+
+// memUserRepository 内存用户仓储，软删除的用户保存在deleted中
+type memUserRepository struct {
+	repository.UserRepository
+	users   map[uint]model.User
+	deleted map[uint]model.User
+	// failDelete 删除这些用户时返回错误，模拟数据库故障
+	failDelete map[uint]error
+	// primaryReads 每次按ID读取时是否要求读主库
+	primaryReads []bool
+}
+
+func newMemUserRepository(ids ...uint) *memUserRepository {
+	r := &memUserRepository{
+		users:      make(map[uint]model.User),
+		deleted:    make(map[uint]model.User),
+		failDelete: make(map[uint]error),
+	}
+	for _, id := range ids {
+		r.users[id] = model.User{BaseModel: model.BaseModel{ID: id}, Status: 1}
+	}
+	return r
+}
+
+func (r *memUserRepository) GetByID(ctx context.Context, id uint) (*model.User, error) {
+	r.primaryReads = append(r.primaryReads, database.PrimaryRequested(ctx))
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &user, nil
+}
+
+func (r *memUserRepository) Update(_ context.Context, user *model.User) error {
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *memUserRepository) Delete(_ context.Context, id uint) error {
+	if err := r.failDelete[id]; err != nil {
+		return err
+	}
+	r.deleted[id] = r.users[id]
+	delete(r.users, id)
+	return nil
+}
+
+func (r *memUserRepository) Restore(_ context.Context, id uint) error {
+	user, ok := r.deleted[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	r.users[id] = user
+	delete(r.deleted, id)
+	return nil
+}
+
+// snapshot 复制当前状态，用于模拟事务回滚
+func (r *memUserRepository) snapshot() (map[uint]model.User, map[uint]model.User) {
+	users := make(map[uint]model.User, len(r.users))
+	for id, user := range r.users {
+		users[id] = user
+	}
+	deleted := make(map[uint]model.User, len(r.deleted))
+	for id, user := range r.deleted {
+		deleted[id] = user
+	}
+	return users, deleted
+}
+
+// memTxManager 事务管理器桩，fn返回错误时恢复进入前的状态，嵌套调用相当于保存点
+type memTxManager struct {
+	repo *memUserRepository
+}
+
+func (m *memTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	users, deleted := m.repo.snapshot()
+	if err := fn(ctx); err != nil {
+		m.repo.users, m.repo.deleted = users, deleted
+		return err
+	}
+	return nil
+}
+
+// nopAudit 不记录审计日志
+type nopAudit struct{}
+
+func (nopAudit) Record(context.Context, AuditEntry) error { return nil }
+
+func (nopAudit) List(context.Context, model.AuditLogFilter, int, int) ([]*model.AuditLog, *response.PageMeta, error) {
+	return nil, nil, nil
+}
+
+// nopPublisher 不发布事件
+type nopPublisher struct{}
+
+func (nopPublisher) Publish(context.Context, ...eventbus.Event) error { return nil }
+
+func newBatchService(repo *memUserRepository) UserService {
+	log := logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"})
+	return NewUserService(repo, &memTxManager{repo: repo}, nopAudit{}, nopPublisher{}, log)
+}
+
+// itemStatuses 各项的状态
+func itemStatuses(result *model.UserBatchResult) []string {
+	statuses := make([]string, len(result.Items))
+	for i, item := range result.Items {
+		statuses[i] = item.Status
+	}
+	return statuses
+}
+
+func TestBatchAtomicRollsBack(t *testing.T) {
+	repo := newMemUserRepository(1, 2)
+	svc := newBatchService(repo)
+
+	result, err := svc.Batch(context.Background(), &model.UserBatchRequest{
+		Operation: model.UserBatchDelete,
+		IDs:       []uint{1, 2, 3},
+	})
+	if err != nil {
+		t.Fatalf("Batch() error = %v", err)
+	}
+
+	if result.Mode != model.UserBatchAtomic || !result.RolledBack {
+		t.Errorf("mode = %s, rolled_back = %v, want atomic and rolled back", result.Mode, result.RolledBack)
+	}
+	want := []string{model.UserBatchRolledBack, model.UserBatchRolledBack, model.UserBatchFailed}
+	if got := itemStatuses(result); !slices.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	if result.Succeeded != 0 || result.Failed != 1 {
+		t.Errorf("succeeded = %d, failed = %d, want 0 and 1", result.Succeeded, result.Failed)
+	}
+	if !errors.Is(result.Items[2].Err, ErrUserNotFound) {
+		t.Errorf("item 3 error = %v, want ErrUserNotFound", result.Items[2].Err)
+	}
+
+	// 已执行的删除被回滚
+	for _, id := range []uint{1, 2} {
+		if _, ok := repo.users[id]; !ok {
+			t.Errorf("user %d was deleted, want rolled back", id)
+		}
+	}
+}
+
+func TestBatchAtomicCommits(t *testing.T) {
+	repo := newMemUserRepository(1, 2)
+	svc := newBatchService(repo)
+	status := 0
+
+	result, err := svc.Batch(context.Background(), &model.UserBatchRequest{
+		Operation: model.UserBatchSetStatus,
+		IDs:       []uint{1, 2},
+		Status:    &status,
+	})
+	if err != nil {
+		t.Fatalf("Batch() error = %v", err)
+	}
+	if result.RolledBack || result.Succeeded != 2 {
+		t.Errorf("rolled_back = %v, succeeded = %d, want committed with 2 succeeded", result.RolledBack, result.Succeeded)
+	}
+	for _, id := range []uint{1, 2} {
+		if repo.users[id].Status != 0 {
+			t.Errorf("user %d status = %d, want 0", id, repo.users[id].Status)
+		}
+	}
+}
+
+func TestBatchBestEffortPartialFailure(t *testing.T) {
+	repo := newMemUserRepository(1, 2, 3)
+	repo.failDelete[2] = errors.New("driver: bad connection")
+	svc := newBatchService(repo)
+
+	result, err := svc.Batch(context.Background(), &model.UserBatchRequest{
+		Operation: model.UserBatchDelete,
+		IDs:       []uint{1, 2, 3, 4},
+		Mode:      model.UserBatchBestEffort,
+	})
+	if err != nil {
+		t.Fatalf("Batch() error = %v", err)
+	}
+
+	want := []string{model.UserBatchSucceeded, model.UserBatchFailed, model.UserBatchSucceeded, model.UserBatchFailed}
+	if got := itemStatuses(result); !slices.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	if result.RolledBack || result.Succeeded != 2 || result.Failed != 2 {
+		t.Errorf("rolled_back = %v, succeeded = %d, failed = %d, want false, 2, 2",
+			result.RolledBack, result.Succeeded, result.Failed)
+	}
+	if !errors.Is(result.Items[3].Err, ErrUserNotFound) {
+		t.Errorf("item 4 error = %v, want ErrUserNotFound", result.Items[3].Err)
+	}
+
+	// 单个失败不影响其他项
+	if _, ok := repo.deleted[1]; !ok {
+		t.Error("user 1 was not deleted")
+	}
+	if _, ok := repo.deleted[3]; !ok {
+		t.Error("user 3 was not deleted")
+	}
+	if _, ok := repo.users[2]; !ok {
+		t.Error("user 2 was deleted despite the failure")
+	}
+}
+
+func TestBatchRestore(t *testing.T) {
+	repo := newMemUserRepository(1, 2)
+	repo.deleted[1] = repo.users[1]
+	delete(repo.users, 1)
+	svc := newBatchService(repo)
+
+	result, err := svc.Batch(context.Background(), &model.UserBatchRequest{
+		Operation: model.UserBatchRestore,
+		IDs:       []uint{1, 2, 3},
+		Mode:      model.UserBatchBestEffort,
+	})
+	if err != nil {
+		t.Fatalf("Batch() error = %v", err)
+	}
+
+	want := []string{model.UserBatchSucceeded, model.UserBatchFailed, model.UserBatchFailed}
+	if got := itemStatuses(result); !slices.Equal(got, want) {
+		t.Errorf("statuses = %v, want %v", got, want)
+	}
+	if !errors.Is(result.Items[1].Err, ErrUserNotDeleted) {
+		t.Errorf("item 2 error = %v, want ErrUserNotDeleted", result.Items[1].Err)
+	}
+	if !errors.Is(result.Items[2].Err, ErrUserNotFound) {
+		t.Errorf("item 3 error = %v, want ErrUserNotFound", result.Items[2].Err)
+	}
+}
+
+func TestBatchCanceled(t *testing.T) {
+	svc := newBatchService(newMemUserRepository(1))
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	if _, err := svc.Batch(ctx, &model.UserBatchRequest{
+		Operation: model.UserBatchDelete,
+		IDs:       []uint{1},
+		Mode:      model.UserBatchBestEffort,
+	}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Batch() error = %v, want context.DeadlineExceeded", err)
+	}
+}
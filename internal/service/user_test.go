@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"go-api-scaffold/internal/model"
+)
+
+// loginUserRepository 支持按用户名查找和更新登录时间的内存仓储
+type loginUserRepository struct {
+	*memUserRepository
+}
+
+func (r loginUserRepository) GetByUsername(_ context.Context, username string) (*model.User, error) {
+	for _, user := range r.users {
+		if user.Username == username {
+			return &user, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r loginUserRepository) TouchLastLogin(_ context.Context, id uint, at time.Time) error {
+	user := r.users[id]
+	user.LastLogin = &at
+	r.users[id] = user
+	return nil
+}
+
+// TestWritePathsReadPrimary 写操作中的读取和写后重读强制走主库
+func TestWritePathsReadPrimary(t *testing.T) {
+	ctx := context.Background()
+	status := 1
+	tests := []struct {
+		name string
+		run  func(svc UserService) error
+	}{
+		{"update", func(svc UserService) error {
+			_, err := svc.Update(ctx, 1, &model.UserUpdateRequest{Nickname: "alice", Status: &status})
+			return err
+		}},
+		{"delete", func(svc UserService) error { return svc.Delete(ctx, 1) }},
+		{"restore", func(svc UserService) error {
+			_, err := svc.Batch(ctx, &model.UserBatchRequest{Operation: model.UserBatchRestore, IDs: []uint{2}})
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemUserRepository(1, 2)
+			repo.deleted[2] = repo.users[2]
+			delete(repo.users, 2)
+
+			if err := tt.run(newBatchService(repo)); err != nil {
+				t.Fatal(err)
+			}
+			if len(repo.primaryReads) == 0 || slices.Contains(repo.primaryReads, false) {
+				t.Errorf("primary reads = %v, want all true", repo.primaryReads)
+			}
+		})
+	}
+}
+
+// TestLoginRereadsPrimary 登录写入登录时间后从主库重新读取用户
+func TestLoginRereadsPrimary(t *testing.T) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := newMemUserRepository()
+	repo.users[1] = model.User{BaseModel: model.BaseModel{ID: 1}, Username: "alice", Password: string(hashed), Status: 1}
+	svc := newBatchService(repo)
+	svc.(*userService).repo = loginUserRepository{repo}
+
+	user, err := svc.Login(context.Background(), "alice", "secret123")
+	if err != nil {
+		t.Fatalf("Login() error = %v", err)
+	}
+	if !slices.Equal(repo.primaryReads, []bool{true}) {
+		t.Errorf("primary reads = %v, want one primary read after TouchLastLogin", repo.primaryReads)
+	}
+	if user.LastLogin.IsZero() {
+		t.Error("LastLogin = nil, want the stored login time")
+	}
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/pkg/response"
+	"go-api-scaffold/pkg/tracing"
+)
+
+// tracedUserService 用户服务的追踪装饰器，为每个方法创建span
+type tracedUserService struct {
+	inner UserService
+}
+
+// NewTracedUserService 创建带追踪的用户服务，未启用追踪时全局提供者为no-op，开销可忽略
+func NewTracedUserService(inner UserService) UserService {
+	return &tracedUserService{inner: inner}
+}
+
+// Create 创建用户
+func (s *tracedUserService) Create(ctx context.Context, req *model.UserCreateRequest) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Create")
+	resp, err := s.inner.Create(ctx, req)
+	endSpan(span, err)
+	return resp, err
+}
+
+// GetByID 根据ID获取用户
+func (s *tracedUserService) GetByID(ctx context.Context, id uint) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetByID",
+		trace.WithAttributes(attribute.Int64("user.id", int64(id))))
+	resp, err := s.inner.GetByID(ctx, id)
+	endSpan(span, err)
+	return resp, err
+}
+
+// Update 更新用户
+func (s *tracedUserService) Update(ctx context.Context, id uint, req *model.UserUpdateRequest) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Update",
+		trace.WithAttributes(attribute.Int64("user.id", int64(id))))
+	resp, err := s.inner.Update(ctx, id, req)
+	endSpan(span, err)
+	return resp, err
+}
+
+// Delete 删除用户
+func (s *tracedUserService) Delete(ctx context.Context, id uint) error {
+	ctx, span := tracing.Start(ctx, "UserService.Delete",
+		trace.WithAttributes(attribute.Int64("user.id", int64(id))))
+	err := s.inner.Delete(ctx, id)
+	endSpan(span, err)
+	return err
+}
+
+// List 获取用户列表
+func (s *tracedUserService) List(ctx context.Context, page, pageSize int) ([]*model.UserResponse, *response.PageMeta, error) {
+	ctx, span := tracing.Start(ctx, "UserService.List",
+		trace.WithAttributes(attribute.Int("page", page), attribute.Int("page_size", pageSize)))
+	users, meta, err := s.inner.List(ctx, page, pageSize)
+	endSpan(span, err)
+	return users, meta, err
+}
+
+// Login 用户登录，不记录用户名以免敏感信息进入追踪系统
+func (s *tracedUserService) Login(ctx context.Context, username, password string) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Login")
+	resp, err := s.inner.Login(ctx, username, password)
+	endSpan(span, err)
+	return resp, err
+}
+
+// PurgeDeleted 物理删除软删除用户
+func (s *tracedUserService) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := tracing.Start(ctx, "UserService.PurgeDeleted")
+	purged, err := s.inner.PurgeDeleted(ctx, before)
+	span.SetAttributes(attribute.Int64("users.purged", purged))
+	endSpan(span, err)
+	return purged, err
+}
+
+// Import 批量导入用户
+func (s *tracedUserService) Import(ctx context.Context, records []model.UserImportRecord, dryRun bool) (*model.UserImportResult, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Import",
+		trace.WithAttributes(attribute.Int("users.rows", len(records)), attribute.Bool("import.dry_run", dryRun)))
+	result, err := s.inner.Import(ctx, records, dryRun)
+	if result != nil {
+		span.SetAttributes(attribute.Int("users.succeeded", result.Succeeded), attribute.Int("users.failed", result.Failed))
+	}
+	endSpan(span, err)
+	return result, err
+}
+
+// Export 导出全部用户
+func (s *tracedUserService) Export(ctx context.Context, fn func(user *model.UserResponse) error) error {
+	ctx, span := tracing.Start(ctx, "UserService.Export")
+	exported := 0
+	err := s.inner.Export(ctx, func(user *model.UserResponse) error {
+		exported++
+		return fn(user)
+	})
+	span.SetAttributes(attribute.Int("users.exported", exported))
+	endSpan(span, err)
+	return err
+}
+
+// Batch 批量操作用户
+func (s *tracedUserService) Batch(ctx context.Context, req *model.UserBatchRequest) (*model.UserBatchResult, error) {
+	ctx, span := tracing.Start(ctx, "UserService.Batch",
+		trace.WithAttributes(
+			attribute.String("batch.operation", req.Operation),
+			attribute.String("batch.mode", req.Mode),
+			attribute.Int("users.count", len(req.IDs)),
+		))
+	result, err := s.inner.Batch(ctx, req)
+	if result != nil {
+		span.SetAttributes(
+			attribute.Int("users.succeeded", result.Succeeded),
+			attribute.Int("users.failed", result.Failed),
+			attribute.Bool("batch.rolled_back", result.RolledBack),
+		)
+	}
+	endSpan(span, err)
+	return result, err
+}
+
+// endSpan 结束服务span，预期内的业务错误只记录事件，不将span标记为失败
+func endSpan(span trace.Span, err error) {
+	if isBusinessError(err) {
+		span.RecordError(err)
+		span.End()
+		return
+	}
+	tracing.End(span, err)
+}
+
+// isBusinessError 是否为映射到4xx的业务错误
+func isBusinessError(err error) bool {
+	for _, target := range []error{
+		ErrUserNotFound, ErrUsernameExists, ErrEmailExists, ErrUserExists, ErrUserNotDeleted,
+		ErrWebhookNotFound, ErrDeliveryNotFound, ErrInvalidWebhookEvent, ErrInvalidWebhookURL,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
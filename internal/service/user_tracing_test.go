@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/gorm"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/tracing"
+)
+
+// spanByName 按名称查找span
+func spanByName(t *testing.T, spans tracetest.SpanStubs, name string) tracetest.SpanStub {
+	t.Helper()
+	for _, span := range spans {
+		if span.Name == name {
+			return span
+		}
+	}
+	t.Fatalf("span %q not found in %d spans", name, len(spans))
+	return tracetest.SpanStub{}
+}
+
+// newMemoryTracer 注册memory导出器的全局追踪提供者
+func newMemoryTracer(t *testing.T) *tracing.Provider {
+	t.Helper()
+	tp, err := tracing.New(config.TracingConfig{Enabled: true, Exporter: "memory"}, config.AppConfig{Name: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return tp
+}
+
+// createUserRepository 支持创建用户的内存仓储
+type createUserRepository struct {
+	*memUserRepository
+}
+
+func (r createUserRepository) GetByUsername(context.Context, string) (*model.User, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r createUserRepository) GetByEmail(context.Context, string) (*model.User, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r createUserRepository) Create(_ context.Context, user *model.User) error {
+	user.ID = uint(len(r.users) + 1)
+	r.users[user.ID] = *user
+	return nil
+}
+
+func TestTracedUserService(t *testing.T) {
+	tp := newMemoryTracer(t)
+
+	repo := newMemUserRepository(1, 2)
+	repo.failDelete[2] = errors.New("driver: bad connection")
+	svc := NewTracedUserService(newBatchService(repo))
+
+	ctx, parent := tracing.Start(context.Background(), "request")
+	tests := []struct {
+		name       string
+		call       func() error
+		span       string
+		wantStatus codes.Code
+		wantEvents int
+	}{
+		{"success", func() error { _, err := svc.GetByID(ctx, 1); return err }, "UserService.GetByID", codes.Unset, 0},
+		// 业务错误只记录事件，不标记span失败
+		{"business error", func() error { _, err := svc.GetByID(ctx, 99); return err }, "UserService.GetByID", codes.Unset, 1},
+		{"internal error", func() error { return svc.Delete(ctx, 2) }, "UserService.Delete", codes.Error, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp.Reset()
+			_ = tt.call()
+
+			span := spanByName(t, tp.Spans(), tt.span)
+			if span.Status.Code != tt.wantStatus || len(span.Events) != tt.wantEvents {
+				t.Errorf("status = %v, events = %d, want %v and %d", span.Status, len(span.Events), tt.wantStatus, tt.wantEvents)
+			}
+			if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+				t.Error("service span is not a child of the caller span")
+			}
+			var userID int64
+			for _, kv := range span.Attributes {
+				if kv.Key == "user.id" {
+					userID = kv.Value.AsInt64()
+				}
+			}
+			if userID == 0 {
+				t.Errorf("attributes = %v, want user.id", span.Attributes)
+			}
+		})
+	}
+	parent.End()
+}
+
+// TestTracedUserServiceCreate 密码加密作为创建用户span的子span，区分bcrypt耗时
+func TestTracedUserServiceCreate(t *testing.T) {
+	tp := newMemoryTracer(t)
+	repo := createUserRepository{newMemUserRepository()}
+	log := logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"})
+	svc := NewTracedUserService(NewUserService(repo, &memTxManager{repo: repo.memUserRepository}, nopAudit{}, nopPublisher{}, log))
+
+	if _, err := svc.Create(context.Background(), &model.UserCreateRequest{
+		Username: "alice",
+		Email:    "alice@example.com",
+		Password: "password123",
+	}); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	spans := tp.Spans()
+	create := spanByName(t, spans, "UserService.Create")
+	bcrypt := spanByName(t, spans, "bcrypt.GenerateFromPassword")
+	if bcrypt.Parent.SpanID() != create.SpanContext.SpanID() {
+		t.Error("bcrypt span is not a child of UserService.Create")
+	}
+	if create.Parent.IsValid() || create.Status.Code != codes.Unset {
+		t.Errorf("create span parent = %v, status = %v, want root span without error", create.Parent, create.Status)
+	}
+}
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api-scaffold/internal/event"
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/internal/repository"
+	"go-api-scaffold/internal/webhook"
+	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/response"
+)
+
+// WebhookService Webhook订阅服务接口
+type WebhookService interface {
+	Create(ctx context.Context, req *model.WebhookCreateRequest) (*model.WebhookResponse, error)
+	GetByID(ctx context.Context, id uint) (*model.WebhookResponse, error)
+	Update(ctx context.Context, id uint, req *model.WebhookUpdateRequest) (*model.WebhookResponse, error)
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context, page, pageSize int) ([]*model.WebhookResponse, *response.PageMeta, error)
+	ListDeliveries(ctx context.Context, id uint, status string, page, pageSize int) ([]*model.WebhookDelivery, *response.PageMeta, error)
+	// Redeliver 重置投递记录为待投递，重新获得完整的重试次数
+	Redeliver(ctx context.Context, id, deliveryID uint) (*model.WebhookDelivery, error)
+}
+
+// webhookService Webhook订阅服务实现
+type webhookService struct {
+	repo   repository.WebhookRepository
+	logger logger.Logger
+}
+
+// NewWebhookService 创建Webhook订阅服务实例
+func NewWebhookService(repo repository.WebhookRepository, logger logger.Logger) WebhookService {
+	return &webhookService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// Create 创建订阅，未指定密钥时自动生成，密钥仅在创建时返回
+func (s *webhookService) Create(ctx context.Context, req *model.WebhookCreateRequest) (*model.WebhookResponse, error) {
+	events, err := normalizeWebhookEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateWebhookURL(ctx, req.URL); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret = newWebhookSecret()
+	}
+
+	sub := &model.WebhookSubscription{
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      events,
+		Description: req.Description,
+		Active:      true,
+	}
+	if req.Active != nil {
+		sub.Active = *req.Active
+	}
+
+	if err := s.repo.Create(ctx, sub); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to create webhook")
+		return nil, errors.New("failed to create webhook")
+	}
+
+	resp := s.toWebhookResponse(sub)
+	resp.Secret = secret
+	return resp, nil
+}
+
+// GetByID 根据ID获取订阅
+func (s *webhookService) GetByID(ctx context.Context, id uint) (*model.WebhookResponse, error) {
+	sub, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.toWebhookResponse(sub), nil
+}
+
+// Update 更新订阅
+func (s *webhookService) Update(ctx context.Context, id uint, req *model.WebhookUpdateRequest) (*model.WebhookResponse, error) {
+	sub, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != "" {
+		if err := validateWebhookURL(ctx, req.URL); err != nil {
+			return nil, err
+		}
+		sub.URL = req.URL
+	}
+	if req.Events != nil {
+		if sub.Events, err = normalizeWebhookEvents(req.Events); err != nil {
+			return nil, err
+		}
+	}
+	if req.Secret != "" {
+		sub.Secret = req.Secret
+	}
+	if req.Description != nil {
+		sub.Description = *req.Description
+	}
+	if req.Active != nil {
+		sub.Active = *req.Active
+	}
+
+	if err := s.repo.Update(ctx, sub); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to update webhook")
+		return nil, errors.New("failed to update webhook")
+	}
+
+	return s.toWebhookResponse(sub), nil
+}
+
+// Delete 删除订阅，尚未投递的记录会在投递时进入死信状态
+func (s *webhookService) Delete(ctx context.Context, id uint) error {
+	if _, err := s.get(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to delete webhook")
+		return errors.New("failed to delete webhook")
+	}
+	return nil
+}
+
+// List 获取订阅列表
+func (s *webhookService) List(ctx context.Context, page, pageSize int) ([]*model.WebhookResponse, *response.PageMeta, error) {
+	page, pageSize = normalizePage(page, pageSize)
+
+	subs, total, err := s.repo.List(ctx, (page-1)*pageSize, pageSize)
+	if err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to get webhook list")
+		return nil, nil, errors.New("failed to get webhook list")
+	}
+
+	resps := make([]*model.WebhookResponse, len(subs))
+	for i, sub := range subs {
+		resps[i] = s.toWebhookResponse(sub)
+	}
+	return resps, pageMeta(page, pageSize, total), nil
+}
+
+// ListDeliveries 获取订阅的投递历史
+func (s *webhookService) ListDeliveries(ctx context.Context, id uint, status string, page, pageSize int) ([]*model.WebhookDelivery, *response.PageMeta, error) {
+	if _, err := s.get(ctx, id); err != nil {
+		return nil, nil, err
+	}
+	page, pageSize = normalizePage(page, pageSize)
+
+	deliveries, total, err := s.repo.ListDeliveries(ctx, id, status, (page-1)*pageSize, pageSize)
+	if err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to get webhook deliveries")
+		return nil, nil, errors.New("failed to get webhook deliveries")
+	}
+	return deliveries, pageMeta(page, pageSize, total), nil
+}
+
+// Redeliver 重新投递
+func (s *webhookService) Redeliver(ctx context.Context, id, deliveryID uint) (*model.WebhookDelivery, error) {
+	if _, err := s.get(ctx, id); err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.repo.GetDelivery(ctx, id, deliveryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrDeliveryNotFound
+		}
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to get webhook delivery")
+		return nil, errors.New("failed to get webhook delivery")
+	}
+
+	delivery.Status = model.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to redeliver webhook")
+		return nil, errors.New("failed to redeliver webhook")
+	}
+	return delivery, nil
+}
+
+// get 获取订阅并转换不存在错误
+func (s *webhookService) get(ctx context.Context, id uint) (*model.WebhookSubscription, error) {
+	sub, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWebhookNotFound
+		}
+		s.logger.WithContext(ctx).WithError(err).Error("Failed to get webhook")
+		return nil, errors.New("failed to get webhook")
+	}
+	return sub, nil
+}
+
+// toWebhookResponse 转换为订阅响应格式，不包含密钥
+func (s *webhookService) toWebhookResponse(sub *model.WebhookSubscription) *model.WebhookResponse {
+	return &model.WebhookResponse{
+		ID:          sub.ID,
+		URL:         sub.URL,
+		Events:      sub.EventList(),
+		Description: sub.Description,
+		Active:      sub.Active,
+		CreatedAt:   sub.CreatedAt,
+		UpdatedAt:   sub.UpdatedAt,
+	}
+}
+
+// normalizeWebhookEvents 校验并去重事件名，返回逗号分隔的存储格式
+func normalizeWebhookEvents(events []string) (string, error) {
+	seen := make(map[string]bool, len(events))
+	names := make([]string, 0, len(events))
+	for _, name := range events {
+		name = strings.TrimSpace(name)
+		if name != model.WebhookEventAll && !event.Known(name) {
+			return "", fmt.Errorf("%w: %s", ErrInvalidWebhookEvent, name)
+		}
+		if name == model.WebhookEventAll {
+			return model.WebhookEventAll, nil
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return model.WebhookEventAll, nil
+	}
+	return strings.Join(names, ","), nil
+}
+
+// validateWebhookURL 校验订阅地址，防止将投递指向内网服务（SSRF）
+func validateWebhookURL(ctx context.Context, raw string) error {
+	if err := webhook.ValidateURL(ctx, raw); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhookURL, err)
+	}
+	return nil
+}
+
+// newWebhookSecret 生成32字节随机签名密钥
+func newWebhookSecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+// normalizePage 规范化分页参数
+func normalizePage(page, pageSize int) (int, int) {
+	if page <= 0 {
+		page = 1
+	}
+	if pageSize <= 0 {
+		pageSize = 20
+	}
+	return page, pageSize
+}
+
+// pageMeta 计算分页信息
+func pageMeta(page, pageSize int, total int64) *response.PageMeta {
+	return &response.PageMeta{
+		Page:       page,
+		PageSize:   pageSize,
+		Total:      total,
+		TotalPages: int(math.Ceil(float64(total) / float64(pageSize))),
+	}
+}
@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/internal/repository"
+	"go-api-scaffold/pkg/eventbus"
+)
+
+// Dispatcher 将领域事件展开为各订阅的投递记录，作为outbox中继的投递目标使用
+// 写入投递记录后中继才标记消息已投递，两者之间退出时消息会被重新投递，接收方应按事件ID（X-Webhook-ID）去重
+type Dispatcher struct {
+	repo   repository.WebhookRepository
+	notify func()
+}
+
+// NewDispatcher 创建事件分发器，notify在投递记录提交后调用以唤醒投递worker，可为nil
+func NewDispatcher(repo repository.WebhookRepository, notify func()) *Dispatcher {
+	return &Dispatcher{repo: repo, notify: notify}
+}
+
+// Send 实现eventbus.Sink接口
+func (d *Dispatcher) Send(ctx context.Context, msg eventbus.Message) error {
+	subs, err := d.repo.ListActive(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var deliveries []*model.WebhookDelivery
+	for _, sub := range subs {
+		if !sub.Matches(msg.Name) {
+			continue
+		}
+		deliveries = append(deliveries, &model.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        msg.ID,
+			EventName:      msg.Name,
+			Payload:        string(body),
+			Status:         model.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	if err := d.repo.CreateDeliveries(ctx, deliveries...); err != nil {
+		return err
+	}
+	if d.notify != nil {
+		repository.AfterCommit(ctx, func(context.Context) { d.notify() })
+	}
+	return nil
+}
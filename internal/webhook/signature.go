@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook请求头
+const (
+	HeaderEventID    = "X-Webhook-ID"
+	HeaderDeliveryID = "X-Webhook-Delivery"
+	HeaderEvent      = "X-Webhook-Event"
+	HeaderTimestamp  = "X-Webhook-Timestamp"
+	HeaderSignature  = "X-Webhook-Signature"
+)
+
+// signaturePrefix 签名算法前缀
+const signaturePrefix = "sha256="
+
+// 签名校验错误
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrInvalidTimestamp = errors.New("invalid webhook timestamp")
+	ErrTimestampExpired = errors.New("webhook timestamp outside tolerance")
+)
+
+// Sign 计算签名：HMAC-SHA256(secret, "<timestamp>.<body>")，时间戳为Unix秒
+// 将时间戳纳入签名以便接收方拒绝重放请求
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 校验签名和时间戳，供接收方使用，tolerance为0时不校验时间偏差
+func Verify(secret, timestamp, signature string, body []byte, tolerance time.Duration) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+	if tolerance > 0 {
+		skew := time.Since(time.Unix(ts, 0))
+		if skew > tolerance || skew < -tolerance {
+			return ErrTimestampExpired
+		}
+	}
+
+	if !strings.HasPrefix(signature, signaturePrefix) {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(Sign(secret, ts, body)), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// 订阅地址校验错误
+var (
+	ErrInsecureURL     = errors.New("webhook url must use https")
+	ErrForbiddenTarget = errors.New("webhook target address is not allowed")
+)
+
+// forbiddenNetworks 除回环、私有、链路本地等地址外额外禁止的网段
+var forbiddenNetworks = []*net.IPNet{
+	mustCIDR("0.0.0.0/8"),     // 本网络
+	mustCIDR("100.64.0.0/10"), // 运营商级NAT，部分云厂商的元数据服务位于此网段
+	mustCIDR("192.0.0.0/24"),  // IETF协议分配
+	mustCIDR("198.18.0.0/15"), // 基准测试
+}
+
+// ValidateURL 校验订阅地址：必须为https，且主机解析出的全部地址都不能是内网地址
+// 投递时拨号前还会再次校验，防止DNS重绑定绕过
+func ValidateURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" {
+		return ErrInsecureURL
+	}
+	host := u.Hostname()
+	if host == "" {
+		return errors.New("webhook url has no host")
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return checkIP(ip)
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return fmt.Errorf("resolve webhook host %s: %w", host, err)
+	}
+	for _, addr := range addrs {
+		if err := checkIP(addr.IP); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewClient 创建投递使用的HTTP客户端
+// 拨号时校验实际连接的地址，不使用代理，不跟随重定向
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil {
+				return fmt.Errorf("%w: %s", ErrForbiddenTarget, host)
+			}
+			return checkIP(ip)
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// checkIP 拒绝回环、私有、链路本地、未指定、组播等地址
+func checkIP(ip net.IP) error {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, ip)
+	}
+	for _, network := range forbiddenNetworks {
+		if network.Contains(ip) {
+			return fmt.Errorf("%w: %s", ErrForbiddenTarget, ip)
+		}
+	}
+	return nil
+}
+
+// mustCIDR 解析网段，仅用于常量
+func mustCIDR(s string) *net.IPNet {
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
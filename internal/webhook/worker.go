@@ -0,0 +1,235 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/internal/repository"
+	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/retry"
+	"go-api-scaffold/pkg/strutil"
+)
+
+// WorkerOptions 投递worker选项
+type WorkerOptions struct {
+	Timeout        time.Duration
+	PollInterval   time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Client         *http.Client // 为nil时使用NewClient创建；自定义Client不做目标地址校验
+}
+
+// Worker Webhook投递worker
+// 周期性领取到期投递记录，签名后POST到订阅地址，非2xx响应按指数退避重试，超过次数进入死信状态
+type Worker struct {
+	repo   repository.WebhookRepository
+	opts   WorkerOptions
+	client *http.Client
+	logger logger.Logger
+
+	ctx    context.Context // 投递上下文，强制关闭时取消
+	cancel context.CancelFunc
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewWorker 创建投递worker并立即开始轮询
+func NewWorker(repo repository.WebhookRepository, opts WorkerOptions, logger logger.Logger) *Worker {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 50
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 8
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = 30 * time.Second
+	}
+	if opts.MaxBackoff < opts.InitialBackoff {
+		opts.MaxBackoff = opts.InitialBackoff
+	}
+	client := opts.Client
+	if client == nil {
+		client = NewClient(opts.Timeout)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Worker{
+		repo:   repo,
+		opts:   opts,
+		client: client,
+		logger: logger.WithField("component", "webhook_worker"),
+		ctx:    ctx,
+		cancel: cancel,
+		wake:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Notify 唤醒worker立即投递，不阻塞
+func (w *Worker) Notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Shutdown 停止轮询并等待当前批次投递完成
+// ctx到期时取消投递中的请求并返回ctx.Err()，未更新状态的记录在租约到期后重新投递
+func (w *Worker) Shutdown(ctx context.Context) error {
+	close(w.stop)
+	defer w.cancel()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run 轮询投递，一批满载时立即继续下一批
+func (w *Worker) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		case <-w.wake:
+		}
+
+		for {
+			n, err := w.Flush(w.ctx)
+			if err != nil {
+				w.logger.WithError(err).Error("Failed to deliver webhooks")
+			}
+			if err != nil || n < w.opts.BatchSize {
+				break
+			}
+			select {
+			case <-w.stop:
+				return
+			default:
+			}
+		}
+	}
+}
+
+// Flush 投递一批到期记录，返回处理的记录数
+func (w *Worker) Flush(ctx context.Context) (int, error) {
+	// 领取时推迟下次投递时间，进程在投递中途退出时租约到期后会被重新领取
+	lease := 2 * w.opts.Timeout
+	deliveries, err := w.repo.ClaimDueDeliveries(ctx, time.Now(), lease, w.opts.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, d := range deliveries {
+		w.deliver(ctx, d)
+		if err := w.repo.UpdateDelivery(ctx, d); err != nil {
+			w.logger.WithError(err).WithField("delivery_id", d.ID).Error("Failed to update webhook delivery")
+		}
+	}
+	return len(deliveries), nil
+}
+
+// deliver 执行一次投递并更新记录状态
+func (w *Worker) deliver(ctx context.Context, d *model.WebhookDelivery) {
+	now := time.Now()
+	d.Attempts++
+	d.LastAttemptAt = &now
+
+	var status int
+	var err error
+	switch {
+	case d.Subscription == nil:
+		err = fmt.Errorf("subscription %d no longer exists", d.SubscriptionID)
+		d.Attempts = w.opts.MaxAttempts
+	case !d.Subscription.Active:
+		err = fmt.Errorf("subscription %d is inactive", d.SubscriptionID)
+		d.Attempts = w.opts.MaxAttempts
+	default:
+		status, err = w.send(ctx, d)
+	}
+	d.ResponseStatus = status
+
+	if err == nil {
+		d.Status = model.WebhookDeliverySucceeded
+		d.LastError = ""
+		d.DeliveredAt = &now
+		return
+	}
+
+	d.LastError = strutil.Truncate(err.Error(), 1024)
+	log := w.logger.WithContext(ctx).WithError(err).WithFields(map[string]interface{}{
+		"delivery_id":     d.ID,
+		"subscription_id": d.SubscriptionID,
+		"event_name":      d.EventName,
+		"attempts":        d.Attempts,
+	})
+	if d.Attempts >= w.opts.MaxAttempts {
+		d.Status = model.WebhookDeliveryDead
+		log.Error("Webhook delivery moved to dead letter")
+		return
+	}
+	d.NextAttemptAt = now.Add(retry.Backoff(w.opts.InitialBackoff, w.opts.MaxBackoff, d.Attempts))
+	log.Warn("Webhook delivery failed, will retry")
+}
+
+// send 发送签名请求，返回响应状态码
+func (w *Worker) send(ctx context.Context, d *model.WebhookDelivery) (int, error) {
+	body := []byte(d.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.Subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	// 校验前创建的订阅可能仍是http地址
+	if req.URL.Scheme != "https" {
+		return 0, ErrInsecureURL
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "go-api-scaffold-webhooks")
+	req.Header.Set(HeaderEventID, d.EventID)
+	req.Header.Set(HeaderDeliveryID, strconv.FormatUint(uint64(d.ID), 10))
+	req.Header.Set(HeaderEvent, d.EventName)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(d.Subscription.Secret, timestamp, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// 读完响应体以便复用连接；响应内容不保存，避免通过投递记录读取目标服务的响应
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
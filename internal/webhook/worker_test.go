@@ -0,0 +1,391 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/internal/service"
+	"go-api-scaffold/internal/webhook"
+	"go-api-scaffold/pkg/logger"
+)
+
+const testSecret = "whsec_test_0123456789abcdef"
+
+// receiver 本地Webhook接收端，failures次之前返回status，之后返回200
+type receiver struct {
+	server   *httptest.Server
+	status   int
+	failures atomic.Int32
+
+	mu       sync.Mutex
+	requests []*http.Request
+	verified []error
+}
+
+func newReceiver(t *testing.T, status int, failures int32) *receiver {
+	r := &receiver{status: status}
+	r.failures.Store(failures)
+	r.server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.requests = append(r.requests, req)
+		r.verified = append(r.verified, webhook.Verify(testSecret,
+			req.Header.Get(webhook.HeaderTimestamp), req.Header.Get(webhook.HeaderSignature), body, time.Minute))
+		r.mu.Unlock()
+
+		if r.failures.Add(-1) >= 0 {
+			w.WriteHeader(r.status)
+			_, _ = w.Write([]byte("internal response that must not be stored"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+// memoryRepo 内存实现的Webhook仓储
+type memoryRepo struct {
+	mu         sync.Mutex
+	subs       map[uint]*model.WebhookSubscription
+	deliveries map[uint]*model.WebhookDelivery
+}
+
+func newMemoryRepo() *memoryRepo {
+	return &memoryRepo{
+		subs:       map[uint]*model.WebhookSubscription{},
+		deliveries: map[uint]*model.WebhookDelivery{},
+	}
+}
+
+func (r *memoryRepo) Create(_ context.Context, sub *model.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sub.ID = uint(len(r.subs) + 1)
+	r.subs[sub.ID] = sub
+	return nil
+}
+
+func (r *memoryRepo) GetByID(_ context.Context, id uint) (*model.WebhookSubscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sub, ok := r.subs[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *sub
+	return &copied, nil
+}
+
+func (r *memoryRepo) Update(_ context.Context, sub *model.WebhookSubscription) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subs[sub.ID] = sub
+	return nil
+}
+
+func (r *memoryRepo) Delete(_ context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.subs, id)
+	return nil
+}
+
+func (r *memoryRepo) List(context.Context, int, int) ([]*model.WebhookSubscription, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *memoryRepo) ListActive(context.Context) ([]*model.WebhookSubscription, error) {
+	return nil, nil
+}
+
+func (r *memoryRepo) CreateDeliveries(_ context.Context, deliveries ...*model.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, d := range deliveries {
+		d.ID = uint(len(r.deliveries) + 1)
+		copied := *d
+		r.deliveries[d.ID] = &copied
+	}
+	return nil
+}
+
+func (r *memoryRepo) GetDelivery(_ context.Context, subscriptionID, id uint) (*model.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d, ok := r.deliveries[id]
+	if !ok || d.SubscriptionID != subscriptionID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *d
+	return &copied, nil
+}
+
+func (r *memoryRepo) UpdateDelivery(_ context.Context, delivery *model.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *delivery
+	copied.Subscription = nil
+	r.deliveries[delivery.ID] = &copied
+	return nil
+}
+
+func (r *memoryRepo) ListDeliveries(context.Context, uint, string, int, int) ([]*model.WebhookDelivery, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *memoryRepo) ClaimDueDeliveries(_ context.Context, now time.Time, lease time.Duration, limit int) ([]*model.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var claimed []*model.WebhookDelivery
+	for id := uint(1); id <= uint(len(r.deliveries)) && len(claimed) < limit; id++ {
+		d := r.deliveries[id]
+		if d.Status != model.WebhookDeliveryPending || d.NextAttemptAt.After(now) {
+			continue
+		}
+		d.NextAttemptAt = now.Add(lease)
+		copied := *d
+		if sub, ok := r.subs[d.SubscriptionID]; ok {
+			subCopy := *sub
+			copied.Subscription = &subCopy
+		}
+		claimed = append(claimed, &copied)
+	}
+	return claimed, nil
+}
+
+// delivery 读取投递记录当前状态
+func (r *memoryRepo) delivery(id uint) model.WebhookDelivery {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return *r.deliveries[id]
+}
+
+// makeDue 将投递记录设为立即到期，模拟退避时间已过
+func (r *memoryRepo) makeDue(id uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deliveries[id].NextAttemptAt = time.Now().Add(-time.Second)
+}
+
+// setup 创建订阅、一条待投递记录和投递worker
+func setup(t *testing.T, rcv *receiver, opts webhook.WorkerOptions) (*memoryRepo, *webhook.Worker, logger.Logger) {
+	t.Helper()
+	repo := newMemoryRepo()
+	ctx := context.Background()
+	if err := repo.Create(ctx, &model.WebhookSubscription{URL: rcv.server.URL, Secret: testSecret, Events: "*", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.CreateDeliveries(ctx, &model.WebhookDelivery{
+		SubscriptionID: 1,
+		EventID:        "evt-1",
+		EventName:      "user.created",
+		Payload:        `{"id":"evt-1","name":"user.created"}`,
+		Status:         model.WebhookDeliveryPending,
+		NextAttemptAt:  time.Now(),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	log := logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"})
+	opts.Client = rcv.server.Client()
+	opts.PollInterval = time.Hour
+	w := webhook.NewWorker(repo, opts, log)
+	t.Cleanup(func() { _ = w.Shutdown(context.Background()) })
+	return repo, w, log
+}
+
+// flush 投递一批并校验处理数量
+func flush(t *testing.T, w *webhook.Worker, want int) {
+	t.Helper()
+	n, err := w.Flush(context.Background())
+	if err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if n != want {
+		t.Fatalf("Flush() = %d, want %d", n, want)
+	}
+}
+
+// assertNextAttempt 校验下次投递时间为失败时刻加上退避间隔
+func assertNextAttempt(t *testing.T, d model.WebhookDelivery, backoff time.Duration) {
+	t.Helper()
+	got := d.NextAttemptAt.Sub(*d.LastAttemptAt)
+	if got != backoff {
+		t.Fatalf("backoff after attempt %d = %v, want %v", d.Attempts, got, backoff)
+	}
+}
+
+func TestWorkerRetriesAndSigns(t *testing.T) {
+	rcv := newReceiver(t, http.StatusInternalServerError, 1)
+	repo, w, _ := setup(t, rcv, webhook.WorkerOptions{MaxAttempts: 3, InitialBackoff: time.Minute})
+
+	flush(t, w, 1)
+	d := repo.delivery(1)
+	if d.Status != model.WebhookDeliveryPending || d.Attempts != 1 || d.ResponseStatus != http.StatusInternalServerError {
+		t.Fatalf("after failure: status=%s attempts=%d response=%d", d.Status, d.Attempts, d.ResponseStatus)
+	}
+	if d.LastError != "endpoint responded with status 500" {
+		t.Fatalf("LastError = %q, response body must not be stored", d.LastError)
+	}
+	assertNextAttempt(t, d, time.Minute)
+
+	// 退避期间不会重新投递
+	flush(t, w, 0)
+
+	repo.makeDue(1)
+	flush(t, w, 1)
+	d = repo.delivery(1)
+	if d.Status != model.WebhookDeliverySucceeded || d.Attempts != 2 || d.DeliveredAt == nil || d.LastError != "" {
+		t.Fatalf("after retry: status=%s attempts=%d delivered=%v error=%q", d.Status, d.Attempts, d.DeliveredAt, d.LastError)
+	}
+
+	if rcv.count() != 2 {
+		t.Fatalf("receiver got %d requests, want 2", rcv.count())
+	}
+	for i, req := range rcv.requests {
+		if err := rcv.verified[i]; err != nil {
+			t.Errorf("request %d signature: %v", i, err)
+		}
+		if got := req.Header.Get(webhook.HeaderEvent); got != "user.created" {
+			t.Errorf("request %d %s = %q", i, webhook.HeaderEvent, got)
+		}
+		if got := req.Header.Get(webhook.HeaderEventID); got != "evt-1" {
+			t.Errorf("request %d %s = %q", i, webhook.HeaderEventID, got)
+		}
+		if got := req.Header.Get(webhook.HeaderDeliveryID); got != "1" {
+			t.Errorf("request %d %s = %q", i, webhook.HeaderDeliveryID, got)
+		}
+	}
+}
+
+func TestWorkerDeadLetterAndRedeliver(t *testing.T) {
+	rcv := newReceiver(t, http.StatusServiceUnavailable, 3)
+	repo, w, log := setup(t, rcv, webhook.WorkerOptions{
+		MaxAttempts:    3,
+		InitialBackoff: time.Minute,
+		MaxBackoff:     90 * time.Second,
+	})
+
+	// 指数退避，不超过MaxBackoff
+	for _, backoff := range []time.Duration{time.Minute, 90 * time.Second} {
+		flush(t, w, 1)
+		d := repo.delivery(1)
+		if d.Status != model.WebhookDeliveryPending {
+			t.Fatalf("attempt %d: status = %s, want pending", d.Attempts, d.Status)
+		}
+		assertNextAttempt(t, d, backoff)
+		repo.makeDue(1)
+	}
+
+	flush(t, w, 1)
+	d := repo.delivery(1)
+	if d.Status != model.WebhookDeliveryDead || d.Attempts != 3 {
+		t.Fatalf("after max attempts: status=%s attempts=%d, want dead/3", d.Status, d.Attempts)
+	}
+	repo.makeDue(1)
+	flush(t, w, 0)
+
+	// 重新投递获得完整的重试次数，接收端恢复后投递成功
+	svc := service.NewWebhookService(repo, log)
+	redelivered, err := svc.Redeliver(context.Background(), 1, 1)
+	if err != nil {
+		t.Fatalf("Redeliver() error = %v", err)
+	}
+	if redelivered.Status != model.WebhookDeliveryPending || redelivered.Attempts != 0 {
+		t.Fatalf("Redeliver() = status %s attempts %d", redelivered.Status, redelivered.Attempts)
+	}
+	if _, err := svc.Redeliver(context.Background(), 1, 99); !errors.Is(err, service.ErrDeliveryNotFound) {
+		t.Fatalf("Redeliver(unknown) error = %v, want ErrDeliveryNotFound", err)
+	}
+
+	flush(t, w, 1)
+	d = repo.delivery(1)
+	if d.Status != model.WebhookDeliverySucceeded || d.Attempts != 1 {
+		t.Fatalf("after redeliver: status=%s attempts=%d", d.Status, d.Attempts)
+	}
+	if rcv.count() != 4 {
+		t.Fatalf("receiver got %d requests, want 4", rcv.count())
+	}
+}
+
+func TestWorkerRejectsInsecureURL(t *testing.T) {
+	rcv := newReceiver(t, http.StatusOK, 0)
+	repo, w, _ := setup(t, rcv, webhook.WorkerOptions{MaxAttempts: 3})
+	repo.subs[1].URL = strings.Replace(rcv.server.URL, "https://", "http://", 1)
+
+	flush(t, w, 1)
+	d := repo.delivery(1)
+	if d.Status != model.WebhookDeliveryPending || d.LastError != webhook.ErrInsecureURL.Error() {
+		t.Fatalf("status=%s error=%q, want pending with %q", d.Status, d.LastError, webhook.ErrInsecureURL)
+	}
+	if rcv.count() != 0 {
+		t.Fatalf("receiver got %d requests, want 0", rcv.count())
+	}
+}
+
+func TestValidateURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want error
+	}{
+		{"https://93.184.216.34/hook", nil},
+		{"http://93.184.216.34/hook", webhook.ErrInsecureURL},
+		{"ftp://93.184.216.34/hook", webhook.ErrInsecureURL},
+		{"https://127.0.0.1/hook", webhook.ErrForbiddenTarget},
+		{"https://localhost:8443/hook", webhook.ErrForbiddenTarget},
+		{"https://[::1]/hook", webhook.ErrForbiddenTarget},
+		{"https://[::ffff:127.0.0.1]/hook", webhook.ErrForbiddenTarget},
+		{"https://10.1.2.3/hook", webhook.ErrForbiddenTarget},
+		{"https://172.16.0.1/hook", webhook.ErrForbiddenTarget},
+		{"https://192.168.1.1/hook", webhook.ErrForbiddenTarget},
+		{"https://169.254.169.254/latest/meta-data", webhook.ErrForbiddenTarget},
+		{"https://100.100.100.200/latest/meta-data", webhook.ErrForbiddenTarget},
+		{"https://0.0.0.0/hook", webhook.ErrForbiddenTarget},
+		{"https://[fd00::1]/hook", webhook.ErrForbiddenTarget},
+		{"https://[fe80::1]/hook", webhook.ErrForbiddenTarget},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			err := webhook.ValidateURL(context.Background(), tt.url)
+			if tt.want == nil && err != nil {
+				t.Fatalf("ValidateURL() error = %v, want nil", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("ValidateURL() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClientRejectsPrivateAddressAtDial(t *testing.T) {
+	rcv := newReceiver(t, http.StatusOK, 0)
+
+	// 主机名在校验后可能被重新解析到内网地址，拨号时仍会拒绝
+	client := webhook.NewClient(time.Second)
+	_, err := client.Post(rcv.server.URL, "application/json", strings.NewReader("{}"))
+	if !errors.Is(err, webhook.ErrForbiddenTarget) {
+		t.Fatalf("Post() error = %v, want ErrForbiddenTarget", err)
+	}
+	if rcv.count() != 0 {
+		t.Fatalf("receiver got %d requests, want 0", rcv.count())
+	}
+}
@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/internal/service"
+	"go-api-scaffold/pkg/jobs"
+	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/scheduler"
+)
+
+// 内置任务名称
+const (
+	JobPurgeDeletedUsers = "users.purge_deleted"
+)
+
+// PurgeDeletedUsersPayload 清理软删除用户任务参数
+type PurgeDeletedUsersPayload struct {
+	OlderThan string `json:"older_than,omitempty"` // 如"720h"，为空时使用配置
+}
+
+// Register 注册应用内置的后台任务
+func Register(w *jobs.Worker, users service.UserService, cfg config.JobsConfig, logger logger.Logger) {
+	w.Register(purgeDeletedUsers(users, cfg.PurgeDeletedUsersAfter, logger), jobs.Concurrency(1))
+}
+
+// purgeDeletedUsers 物理删除保留期外的软删除用户
+func purgeDeletedUsers(users service.UserService, retention time.Duration, logger logger.Logger) jobs.Job {
+	return jobs.Func(JobPurgeDeletedUsers, func(ctx context.Context, payload []byte) error {
+		olderThan := retention
+		if len(payload) > 0 {
+			var p PurgeDeletedUsersPayload
+			if err := json.Unmarshal(payload, &p); err != nil {
+				return jobs.Permanent(err)
+			}
+			if p.OlderThan != "" {
+				d, err := time.ParseDuration(p.OlderThan)
+				if err != nil {
+					return jobs.Permanent(err)
+				}
+				olderThan = d
+			}
+		}
+
+		n, err := users.PurgeDeleted(ctx, time.Now().Add(-olderThan))
+		if n > 0 {
+			logger.WithContext(ctx).WithField("count", n).Info("Purged deleted users")
+		}
+		return err
+	})
+}
+
+// Schedule 注册内置定时任务，定时任务仅负责入队，由工作池执行并按策略重试
+func Schedule(s *scheduler.Scheduler, queue *jobs.Queue, cfg config.SchedulerConfig) error {
+	if spec := cfg.TaskSpec(JobPurgeDeletedUsers, "0 3 * * *"); spec != "" {
+		if err := s.Register(JobPurgeDeletedUsers, spec, enqueue(queue, JobPurgeDeletedUsers)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enqueue 返回将指定任务入队的定时任务函数
+func enqueue(queue *jobs.Queue, name string) scheduler.TaskFunc {
+	return func(ctx context.Context) error {
+		_, err := queue.Enqueue(ctx, name, nil)
+		return err
+	}
+}
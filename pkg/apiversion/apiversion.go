@@ -0,0 +1,292 @@
+// Package apiversion 接口版本管理
+// 同一组路由可以注册到多个版本，客户端通过路径（/api/v2/users）或Accept头
+// （application/vnd.go-api-scaffold+json; version=2）选择版本，已弃用的版本自动输出
+// Deprecation（RFC 9745）与Sunset（RFC 8594）响应头
+package apiversion
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 默认配置
+const (
+	DefaultPrefix    = "/api"
+	DefaultMediaType = "application/vnd.go-api-scaffold+json"
+)
+
+// HeaderVersion 响应头，标明实际处理请求的接口版本
+const HeaderVersion = "API-Version"
+
+// contextKey gin上下文中保存版本号的键
+const contextKey = "api_version"
+
+// Version 单个接口版本
+type Version struct {
+	Number int
+	// Deprecated 弃用时间，零值表示未弃用；可以是将来的时间，提前告知客户端
+	Deprecated time.Time
+	// Sunset 下线时间，零值表示未计划下线
+	Sunset time.Time
+	// Link 迁移说明文档地址，作为 rel="deprecation" 的Link头输出
+	Link string
+}
+
+// Options 版本选项
+type Options struct {
+	// Prefix 版本路径前缀，默认 /api，版本路由为 {Prefix}/v{N}
+	Prefix string
+	// MediaType 通过Accept头选择版本时使用的媒体类型
+	MediaType string
+	// Default 未指定版本的请求使用的版本，为0时使用最早的版本
+	Default  int
+	Versions []Version
+}
+
+// normalize 填充默认值并按版本号排序
+func (o Options) normalize() Options {
+	if o.Prefix == "" {
+		o.Prefix = DefaultPrefix
+	}
+	o.Prefix = strings.TrimSuffix(o.Prefix, "/")
+	if o.MediaType == "" {
+		o.MediaType = DefaultMediaType
+	}
+	versions := make([]Version, len(o.Versions))
+	copy(versions, o.Versions)
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Number < versions[j].Number })
+	o.Versions = versions
+	if o.Default == 0 && len(versions) > 0 {
+		o.Default = versions[0].Number
+	}
+	return o
+}
+
+// Validate 校验版本号与默认版本
+func (o Options) Validate() error {
+	o = o.normalize()
+	if len(o.Versions) == 0 {
+		return fmt.Errorf("no api versions configured")
+	}
+	seen := make(map[int]bool, len(o.Versions))
+	for _, v := range o.Versions {
+		if v.Number <= 0 {
+			return fmt.Errorf("invalid api version: %d", v.Number)
+		}
+		if seen[v.Number] {
+			return fmt.Errorf("duplicate api version: %d", v.Number)
+		}
+		seen[v.Number] = true
+	}
+	if !seen[o.Default] {
+		return fmt.Errorf("default api version %d is not supported", o.Default)
+	}
+	return nil
+}
+
+// supports 是否支持指定版本
+func (o Options) supports(number int) bool {
+	for _, v := range o.Versions {
+		if v.Number == number {
+			return true
+		}
+	}
+	return false
+}
+
+// Router 按版本注册路由
+type Router struct {
+	opts   Options
+	groups map[int]*gin.RouterGroup
+}
+
+// New 为每个版本创建 {Prefix}/v{N} 路由组
+func New(router gin.IRouter, opts Options) *Router {
+	opts = opts.normalize()
+	r := &Router{
+		opts:   opts,
+		groups: make(map[int]*gin.RouterGroup, len(opts.Versions)),
+	}
+	for _, v := range opts.Versions {
+		r.groups[v.Number] = router.Group(r.path(v.Number), versionHeaders(v))
+	}
+	return r
+}
+
+// Group 返回指定版本的路由组，用于只存在于单个版本的路由
+func (r *Router) Group(version int) *gin.RouterGroup {
+	group, ok := r.groups[version]
+	if !ok {
+		panic(fmt.Sprintf("apiversion: version %d is not configured", version))
+	}
+	return group
+}
+
+// Register 将同一组路由注册到多个版本，versions为空时注册到全部版本
+// 未配置的版本会被忽略，这样下线某个版本只需修改配置
+// 旧版本响应附带指向最新版本同一路由的 rel="successor-version" Link头
+func (r *Router) Register(register func(group *gin.RouterGroup), versions ...int) {
+	if len(versions) == 0 {
+		for _, v := range r.opts.Versions {
+			versions = append(versions, v.Number)
+		}
+	}
+
+	var enabled []int
+	for _, number := range versions {
+		if _, ok := r.groups[number]; ok {
+			enabled = append(enabled, number)
+		}
+	}
+	sort.Ints(enabled)
+	if len(enabled) == 0 {
+		return
+	}
+
+	latest := enabled[len(enabled)-1]
+	for _, number := range enabled {
+		group := r.groups[number]
+		if number != latest {
+			group = group.Group("", r.successor(number, latest))
+		}
+		register(group)
+	}
+}
+
+// path 版本路由前缀
+func (r *Router) path(version int) string {
+	return r.opts.Prefix + "/v" + strconv.Itoa(version)
+}
+
+// successor 为旧版本响应添加指向新版本的Link头
+func (r *Router) successor(version, latest int) gin.HandlerFunc {
+	from, to := r.path(version), r.path(latest)
+	return func(c *gin.Context) {
+		target := to + strings.TrimPrefix(c.Request.URL.Path, from)
+		c.Writer.Header().Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, target))
+		c.Next()
+	}
+}
+
+// versionHeaders 记录版本号并输出版本、弃用相关响应头
+func versionHeaders(v Version) gin.HandlerFunc {
+	number := strconv.Itoa(v.Number)
+	return func(c *gin.Context) {
+		c.Set(contextKey, v.Number)
+		header := c.Writer.Header()
+		header.Set(HeaderVersion, number)
+		if !v.Deprecated.IsZero() {
+			header.Set("Deprecation", "@"+strconv.FormatInt(v.Deprecated.Unix(), 10))
+		}
+		if !v.Sunset.IsZero() {
+			header.Set("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if v.Link != "" && (!v.Deprecated.IsZero() || !v.Sunset.IsZero()) {
+			header.Add("Link", fmt.Sprintf(`<%s>; rel="deprecation"; type="text/html"`, v.Link))
+		}
+		c.Next()
+	}
+}
+
+// FromContext 返回处理当前请求的接口版本，不在版本路由下时返回0
+func FromContext(c *gin.Context) int {
+	return c.GetInt(contextKey)
+}
+
+// Negotiate 包装HTTP处理器，将未带版本的 {Prefix}/... 请求按Accept头中的version参数
+// 改写为 {Prefix}/v{N}/... 后交给路由处理，未指定时使用默认版本
+// 路径中已带版本时以路径为准；请求的版本不受支持时由路由的NoRoute处理器返回406，见 UnsupportedVersion
+func Negotiate(next http.Handler, opts Options) http.Handler {
+	opts = opts.normalize()
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		rest, ok := unversionedPath(req.URL.Path, opts.Prefix)
+		if !ok {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		// 同一地址的响应随Accept变化，缓存需要区分
+		w.Header().Add("Vary", "Accept")
+
+		version := opts.Default
+		if value, ok := acceptVersion(req.Header.Get("Accept"), opts.MediaType); ok {
+			number, err := strconv.Atoi(strings.TrimPrefix(value, "v"))
+			if err != nil || !opts.supports(number) {
+				// 路径保持不变，交给路由的NoRoute处理器按统一的错误格式返回406
+				next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), unsupportedKey{}, value)))
+				return
+			}
+			version = number
+		}
+
+		prefix := opts.Prefix + "/v" + strconv.Itoa(version)
+		req.URL.Path = prefix + rest
+		if req.URL.RawPath != "" {
+			if rawRest, ok := unversionedPath(req.URL.RawPath, opts.Prefix); ok {
+				req.URL.RawPath = prefix + rawRest
+			} else {
+				req.URL.RawPath = ""
+			}
+		}
+		next.ServeHTTP(w, req)
+	})
+}
+
+// unversionedPath 路径在前缀下且未带版本时返回前缀之后的部分
+func unversionedPath(path, prefix string) (string, bool) {
+	if path != prefix && !strings.HasPrefix(path, prefix+"/") {
+		return "", false
+	}
+	rest := strings.TrimPrefix(path, prefix)
+	segment := strings.TrimPrefix(rest, "/")
+	if i := strings.IndexByte(segment, '/'); i >= 0 {
+		segment = segment[:i]
+	}
+	if len(segment) > 1 && segment[0] == 'v' {
+		if _, err := strconv.Atoi(segment[1:]); err == nil {
+			return "", false
+		}
+	}
+	return rest, true
+}
+
+// acceptVersion 从Accept头中解析version参数
+// 优先使用本服务媒体类型上的参数，其次是任意媒体类型上的参数
+func acceptVersion(accept, mediaType string) (string, bool) {
+	fallback, found := "", false
+	for _, part := range strings.Split(accept, ",") {
+		typ, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		value, ok := params["version"]
+		if !ok {
+			continue
+		}
+		if typ == mediaType {
+			return value, true
+		}
+		if !found {
+			fallback, found = value, true
+		}
+	}
+	return fallback, found
+}
+
+// unsupportedKey 请求上下文中保存不支持的版本号的键
+type unsupportedKey struct{}
+
+// UnsupportedVersion 返回Accept头中请求的、服务端不支持的版本
+// Negotiate 遇到不支持的版本时不改写路径，请求落到路由的NoRoute处理器，由其据此返回406
+func UnsupportedVersion(req *http.Request) (string, bool) {
+	version, ok := req.Context().Value(unsupportedKey{}).(string)
+	return version, ok
+}
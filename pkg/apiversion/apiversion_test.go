@@ -0,0 +1,115 @@
+package apiversion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUnversionedPath(t *testing.T) {
+	tests := []struct {
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{"/api", "", true},
+		{"/api/", "/", true},
+		{"/api/users", "/users", true},
+		{"/api/users/1", "/users/1", true},
+		{"/api/videos", "/videos", true},
+		{"/api/v", "/v", true},
+		{"/api/v1", "", false},
+		{"/api/v2/users", "", false},
+		{"/api/v10/users", "", false},
+		{"/apiary", "", false},
+		{"/health/", "", false},
+		{"/", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := unversionedPath(tt.path, "/api")
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("unversionedPath(%q) = %q, %v, want %q, %v", tt.path, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestAcceptVersion(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   string
+		wantOK bool
+	}{
+		{"", "", false},
+		{"application/json", "", false},
+		{DefaultMediaType + "; version=2", "2", true},
+		{DefaultMediaType + ";version=v1", "v1", true},
+		{"application/json; version=3", "3", true},
+		{"application/json; version=3, " + DefaultMediaType + "; version=2", "2", true},
+		{"application/json; version=3, application/xml; version=4", "3", true},
+		{"invalid;;, " + DefaultMediaType + "; version=2", "2", true},
+	}
+	for _, tt := range tests {
+		got, ok := acceptVersion(tt.accept, DefaultMediaType)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("acceptVersion(%q) = %q, %v, want %q, %v", tt.accept, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"single version", Options{Versions: []Version{{Number: 1}}}, false},
+		{"default is earliest", Options{Versions: []Version{{Number: 2}, {Number: 1}}}, false},
+		{"explicit default", Options{Default: 2, Versions: []Version{{Number: 1}, {Number: 2}}}, false},
+		{"no versions", Options{}, true},
+		{"zero version", Options{Versions: []Version{{Number: 0}}}, true},
+		{"negative version", Options{Versions: []Version{{Number: -1}, {Number: 1}}}, true},
+		{"duplicate version", Options{Versions: []Version{{Number: 1}, {Number: 1}}}, true},
+		{"unsupported default", Options{Default: 3, Versions: []Version{{Number: 1}, {Number: 2}}}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	opts := Options{Versions: []Version{{Number: 1}, {Number: 2}}}
+	var gotPath string
+	var gotUnsupported string
+	handler := Negotiate(http.HandlerFunc(func(_ http.ResponseWriter, req *http.Request) {
+		gotPath = req.URL.Path
+		gotUnsupported, _ = UnsupportedVersion(req)
+	}), opts)
+
+	tests := []struct {
+		path            string
+		accept          string
+		wantPath        string
+		wantUnsupported string
+	}{
+		{"/api/users", "", "/api/v1/users", ""},
+		{"/api/users", DefaultMediaType + "; version=2", "/api/v2/users", ""},
+		{"/api/users", DefaultMediaType + "; version=v2", "/api/v2/users", ""},
+		{"/api/v1/users", DefaultMediaType + "; version=2", "/api/v1/users", ""},
+		{"/health/", DefaultMediaType + "; version=9", "/health/", ""},
+		{"/api/users", DefaultMediaType + "; version=9", "/api/users", "9"},
+		{"/api/users", DefaultMediaType + "; version=latest", "/api/users", "latest"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept", tt.accept)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		if gotPath != tt.wantPath || gotUnsupported != tt.wantUnsupported {
+			t.Errorf("%s with Accept %q: path = %q unsupported = %q, want %q, %q",
+				tt.path, tt.accept, gotPath, gotUnsupported, tt.wantPath, tt.wantUnsupported)
+		}
+	}
+}
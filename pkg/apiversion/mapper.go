@@ -0,0 +1,61 @@
+package apiversion
+
+import (
+	"sort"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Mapper 按接口版本将内部模型转换为对应的响应结构
+// 请求版本没有注册转换函数时使用不高于该版本的最近一个，新版本只需为发生变化的模型注册；
+// 没有任何可用的转换函数时原样返回
+type Mapper[T any] struct {
+	versions []int
+	funcs    map[int]func(T) interface{}
+}
+
+// NewMapper 创建转换器
+func NewMapper[T any]() *Mapper[T] {
+	return &Mapper[T]{funcs: make(map[int]func(T) interface{})}
+}
+
+// Register 注册从指定版本开始使用的转换函数，应在初始化阶段调用
+func (m *Mapper[T]) Register(version int, fn func(T) interface{}) *Mapper[T] {
+	if _, ok := m.funcs[version]; !ok {
+		m.versions = append(m.versions, version)
+		sort.Ints(m.versions)
+	}
+	m.funcs[version] = fn
+	return m
+}
+
+// Map 按当前请求的版本转换单个对象
+func (m *Mapper[T]) Map(c *gin.Context, value T) interface{} {
+	fn := m.lookup(FromContext(c))
+	if fn == nil {
+		return value
+	}
+	return fn(value)
+}
+
+// MapAll 按当前请求的版本转换列表
+func (m *Mapper[T]) MapAll(c *gin.Context, values []T) interface{} {
+	fn := m.lookup(FromContext(c))
+	if fn == nil {
+		return values
+	}
+	mapped := make([]interface{}, len(values))
+	for i, value := range values {
+		mapped[i] = fn(value)
+	}
+	return mapped
+}
+
+// lookup 查找不高于version的最近一个转换函数
+func (m *Mapper[T]) lookup(version int) func(T) interface{} {
+	i := sort.SearchInts(m.versions, version+1)
+	if i == 0 {
+		return nil
+	}
+	return m.funcs[m.versions[i-1]]
+}
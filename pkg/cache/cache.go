@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"go-api-scaffold/internal/config"
+)
+
+// ErrUnsupportedStore 不支持的缓存存储类型
+var ErrUnsupportedStore = errors.New("unsupported cache store")
+
+// Store 缓存存储接口
+type Store interface {
+	// Get 获取缓存值，不存在时ok为false
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set 写入缓存值，ttl<=0表示不过期
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Delete 删除缓存值
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// NewRedisClient 根据配置创建Redis客户端
+func NewRedisClient(cfg config.RedisConfig) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     cfg.Addr,
+		Password: cfg.Password.Value(),
+		DB:       cfg.DB,
+	})
+}
+
+// NewStore 根据配置为指定资源创建缓存存储
+// memory存储每个资源独立，redis存储共享客户端并以资源名作为键前缀
+func NewStore(cfg config.CacheConfig, client *redis.Client, resource string) (Store, error) {
+	rc := cfg.Resource(resource)
+
+	switch strings.ToLower(cfg.Store) {
+	case "", "memory":
+		return NewMemoryStore(rc.MaxEntries), nil
+	case "redis":
+		if client == nil {
+			return nil, fmt.Errorf("redis client is required for cache store %q", cfg.Store)
+		}
+		return NewRedisStore(client, cfg.KeyPrefix+resource+":"), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedStore, cfg.Store)
+	}
+}
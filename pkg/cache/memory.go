@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryEntry 内存缓存条目
+type memoryEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryStore 进程内LRU缓存，支持条目级TTL
+type MemoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	ll         *list.List
+	items      map[string]*list.Element
+}
+
+// NewMemoryStore 创建内存缓存，maxEntries<=0表示不限制条目数
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		ll:         list.New(),
+		items:      make(map[string]*list.Element),
+	}
+}
+
+// Get 实现Store接口
+func (s *MemoryStore) Get(_ context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	el, ok := s.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	entry := el.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		s.removeElement(el)
+		return nil, false, nil
+	}
+
+	s.ll.MoveToFront(el)
+	return entry.value, true, nil
+}
+
+// Set 实现Store接口
+func (s *MemoryStore) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if el, ok := s.items[key]; ok {
+		entry := el.Value.(*memoryEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		s.ll.MoveToFront(el)
+		return nil
+	}
+
+	s.items[key] = s.ll.PushFront(&memoryEntry{key: key, value: value, expiresAt: expiresAt})
+
+	// 超出容量时淘汰最久未使用的条目
+	for s.maxEntries > 0 && s.ll.Len() > s.maxEntries {
+		s.removeElement(s.ll.Back())
+	}
+	return nil
+}
+
+// Delete 实现Store接口
+func (s *MemoryStore) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if el, ok := s.items[key]; ok {
+			s.removeElement(el)
+		}
+	}
+	return nil
+}
+
+// Len 返回当前条目数（含未清理的过期条目）
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ll.Len()
+}
+
+// removeElement 移除条目，调用方需持有锁
+func (s *MemoryStore) removeElement(el *list.Element) {
+	s.ll.Remove(el)
+	delete(s.items, el.Value.(*memoryEntry).key)
+}
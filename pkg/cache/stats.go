@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"expvar"
+	"sync/atomic"
+)
+
+// Stats 缓存命中统计
+type Stats struct {
+	hits         atomic.Int64
+	misses       atomic.Int64
+	negativeHits atomic.Int64
+	errors       atomic.Int64
+}
+
+// StatsSnapshot 统计快照
+type StatsSnapshot struct {
+	Hits         int64   `json:"hits"`
+	Misses       int64   `json:"misses"`
+	NegativeHits int64   `json:"negative_hits"`
+	Errors       int64   `json:"errors"`
+	HitRatio     float64 `json:"hit_ratio"`
+}
+
+// Hit 记录命中
+func (s *Stats) Hit() { s.hits.Add(1) }
+
+// Miss 记录未命中
+func (s *Stats) Miss() { s.misses.Add(1) }
+
+// NegativeHit 记录命中"不存在"缓存
+func (s *Stats) NegativeHit() { s.negativeHits.Add(1) }
+
+// Error 记录缓存读写错误
+func (s *Stats) Error() { s.errors.Add(1) }
+
+// Snapshot 获取统计快照
+func (s *Stats) Snapshot() StatsSnapshot {
+	snap := StatsSnapshot{
+		Hits:         s.hits.Load(),
+		Misses:       s.misses.Load(),
+		NegativeHits: s.negativeHits.Load(),
+		Errors:       s.errors.Load(),
+	}
+	if total := snap.Hits + snap.NegativeHits + snap.Misses; total > 0 {
+		snap.HitRatio = float64(snap.Hits+snap.NegativeHits) / float64(total)
+	}
+	return snap
+}
+
+// Publish 以 cache.<name> 发布到expvar，可通过 /debug/vars 查看
+func (s *Stats) Publish(name string) {
+	key := "cache." + name
+	if expvar.Get(key) != nil {
+		return
+	}
+	expvar.Publish(key, expvar.Func(func() any { return s.Snapshot() }))
+}
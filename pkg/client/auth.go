@@ -0,0 +1,26 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"go-api-scaffold/internal/model"
+)
+
+// LoginRequest 登录请求
+type LoginRequest = model.UserLoginRequest
+
+// AuthService 认证接口
+type AuthService struct {
+	client *Client
+}
+
+// Login 使用用户名或邮箱登录，返回登录用户
+func (s *AuthService) Login(ctx context.Context, username, password string) (*User, error) {
+	var user User
+	req := &LoginRequest{Username: username, Password: password}
+	if _, err := s.client.do(ctx, http.MethodPost, "/api/v1/login", nil, req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,282 @@
+// Package client 本服务的Go SDK，封装统一响应结构、鉴权令牌刷新、失败重试与分页
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 默认配置
+const (
+	defaultTimeout        = 30 * time.Second
+	defaultMaxRetries     = 3
+	defaultInitialBackoff = 200 * time.Millisecond
+	defaultMaxBackoff     = 5 * time.Second
+	defaultUserAgent      = "go-api-scaffold-client/1.0"
+)
+
+// Options 客户端选项
+type Options struct {
+	// HTTPClient 为空时使用带30秒超时的默认客户端
+	HTTPClient *http.Client
+	// TokenSource 提供Bearer令牌，为空时不发送Authorization头
+	TokenSource TokenSource
+	// MaxRetries 5xx、429及网络错误的最大重试次数，0使用默认值3，负数表示不重试
+	MaxRetries int
+	// InitialBackoff 首次重试间隔，之后指数增长并加入随机抖动
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	UserAgent      string
+}
+
+// Client API客户端
+type Client struct {
+	baseURL *url.URL
+	http    *http.Client
+	tokens  *tokenCache
+	opts    Options
+
+	Users  *UsersService
+	Auth   *AuthService
+	Health *HealthService
+}
+
+// New 创建客户端，baseURL为服务地址，如 http://localhost:8080
+func New(baseURL string, opts Options) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base url: %q", baseURL)
+	}
+
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = &http.Client{Timeout: defaultTimeout}
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = defaultMaxRetries
+	}
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = defaultInitialBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaultMaxBackoff
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = defaultUserAgent
+	}
+
+	c := &Client{
+		baseURL: u,
+		http:    opts.HTTPClient,
+		opts:    opts,
+	}
+	if opts.TokenSource != nil {
+		c.tokens = &tokenCache{source: opts.TokenSource}
+	}
+	c.Users = &UsersService{client: c}
+	c.Auth = &AuthService{client: c}
+	c.Health = &HealthService{client: c}
+	return c, nil
+}
+
+// envelope 统一响应结构，与 pkg/response 一致
+// 服务端配置为 RFC 7807 问题详情时，错误响应的字段解析到title、detail、errors等成员
+type envelope struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+	Meta    *PageMeta       `json:"meta,omitempty"`
+
+	Title     string       `json:"title,omitempty"`
+	Detail    string       `json:"detail,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// do 发送请求并解析统一响应结构，data非nil时解码响应中的data字段
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, data interface{}) (*PageMeta, error) {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+	}
+
+	resp, respBody, err := c.send(ctx, method, path, query, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var env envelope
+	if len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, &env); err != nil {
+			if resp.StatusCode >= http.StatusBadRequest {
+				return nil, newError(resp, envelope{Message: strings.TrimSpace(string(respBody))})
+			}
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, newError(resp, env)
+	}
+
+	if data != nil && len(env.Data) > 0 {
+		if err := json.Unmarshal(env.Data, data); err != nil {
+			return nil, fmt.Errorf("failed to decode response data: %w", err)
+		}
+	}
+	return env.Meta, nil
+}
+
+// send 发送请求，按策略重试，令牌失效时刷新令牌后重发一次
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, payload []byte) (*http.Response, []byte, error) {
+	refreshed := false
+	for attempt := 0; ; attempt++ {
+		resp, body, err := c.sendOnce(ctx, method, path, query, payload)
+
+		if err == nil && c.tokens != nil && !refreshed && tokenRejected(resp) {
+			c.tokens.invalidate()
+			refreshed = true
+			attempt--
+			continue
+		}
+
+		if attempt >= c.opts.MaxRetries || !shouldRetry(method, resp, err) {
+			return resp, body, err
+		}
+
+		wait := c.backoff(attempt)
+		if resp != nil {
+			if after := retryAfter(resp); after > 0 {
+				wait = after
+			}
+		}
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				return resp, body, nil
+			}
+			return nil, nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// sendOnce 发送单次请求并读取完整响应体
+func (c *Client) sendOnce(ctx context.Context, method, path string, query url.Values, payload []byte) (*http.Response, []byte, error) {
+	u := *c.baseURL
+	u.Path = c.baseURL.Path + path
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
+
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.opts.UserAgent)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.tokens != nil {
+		token, err := c.tokens.token(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get access token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return resp, body, nil
+}
+
+// tokenRejected 服务端或认证网关是否拒绝了令牌（RFC 6750：401且带Bearer质询）
+// 登录失败等业务401不带质询头，不触发刷新
+func tokenRejected(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusUnauthorized &&
+		strings.HasPrefix(strings.ToLower(resp.Header.Get("WWW-Authenticate")), "bearer")
+}
+
+// shouldRetry 判断是否重试
+// 429和503表示请求未被处理，任何方法都可以重试；其余5xx和网络错误只重试幂等方法，避免重复创建
+func shouldRetry(method string, resp *http.Response, err error) bool {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false
+		}
+		return idempotent(method)
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusServiceUnavailable:
+		return true
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return idempotent(method)
+	default:
+		return false
+	}
+}
+
+// idempotent 是否为幂等方法
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff 第attempt次重试前的等待时间，指数退避并加入±20%抖动
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := c.opts.InitialBackoff << attempt
+	if wait <= 0 || wait > c.opts.MaxBackoff {
+		wait = c.opts.MaxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(wait)/5*2+1)) - wait/5
+	return wait + jitter
+}
+
+// retryAfter 解析Retry-After头（秒数或HTTP日期）
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
@@ -0,0 +1,380 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-api-scaffold/pkg/response"
+)
+
+// newTestClient 指向测试服务器的客户端，重试间隔很短
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts Options) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	if opts.InitialBackoff == 0 {
+		opts.InitialBackoff = time.Millisecond
+	}
+	if opts.MaxBackoff == 0 {
+		opts.MaxBackoff = 5 * time.Millisecond
+	}
+	c, err := New(server.URL, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// writeJSON 输出统一响应结构
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestTokenRefreshOnBearerChallenge(t *testing.T) {
+	var issued atomic.Int32
+	tokens := TokenSourceFunc(func(context.Context) (*Token, error) {
+		return &Token{AccessToken: "token-" + strconv.Itoa(int(issued.Add(1)))}, nil
+	})
+
+	var requests atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			writeJSON(w, http.StatusUnauthorized, response.Response{Code: response.CodeUnauthorized, Message: "Invalid API key"})
+			return
+		}
+		writeJSON(w, http.StatusOK, response.Response{Code: response.CodeSuccess, Data: User{ID: 1}})
+	}, Options{TokenSource: tokens})
+
+	user, err := c.Users.Get(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if user.ID != 1 || issued.Load() != 2 || requests.Load() != 2 {
+		t.Errorf("user = %d, tokens issued = %d, requests = %d, want 1, 2, 2", user.ID, issued.Load(), requests.Load())
+	}
+
+	// 刷新后的令牌被缓存
+	if _, err := c.Users.Get(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if issued.Load() != 2 {
+		t.Errorf("tokens issued = %d after second request, want cached token", issued.Load())
+	}
+}
+
+func TestTokenRefreshOncePerRequest(t *testing.T) {
+	var issued, requests atomic.Int32
+	tokens := TokenSourceFunc(func(context.Context) (*Token, error) {
+		issued.Add(1)
+		return &Token{AccessToken: "revoked"}, nil
+	})
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeJSON(w, http.StatusUnauthorized, response.Response{Code: response.CodeUnauthorized, Message: "Invalid API key"})
+	}, Options{TokenSource: tokens})
+
+	_, err := c.Users.Get(context.Background(), 1)
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Get() error = %v, want ErrUnauthorized", err)
+	}
+	if issued.Load() != 2 || requests.Load() != 2 {
+		t.Errorf("tokens issued = %d, requests = %d, want one refresh and one resend", issued.Load(), requests.Load())
+	}
+}
+
+// TestLoginFailureDoesNotRefresh 不带Bearer质询的业务401不会触发刷新
+func TestLoginFailureDoesNotRefresh(t *testing.T) {
+	var issued atomic.Int32
+	tokens := TokenSourceFunc(func(context.Context) (*Token, error) {
+		issued.Add(1)
+		return &Token{AccessToken: "token"}, nil
+	})
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusUnauthorized, response.Response{Code: response.CodeUnauthorized, Message: "invalid username or password"})
+	}, Options{TokenSource: tokens})
+
+	if _, err := c.Auth.Login(context.Background(), "alice", "wrong"); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Login() error = %v, want ErrUnauthorized", err)
+	}
+	if issued.Load() != 1 {
+		t.Errorf("tokens issued = %d, want 1", issued.Load())
+	}
+}
+
+func TestTokenRefreshBeforeExpiry(t *testing.T) {
+	var issued atomic.Int32
+	tokens := TokenSourceFunc(func(context.Context) (*Token, error) {
+		issued.Add(1)
+		// 在刷新提前量之内过期，每次请求都会重新获取
+		return &Token{AccessToken: "token", Expiry: time.Now().Add(tokenRefreshSkew / 2)}, nil
+	})
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, response.Response{Code: response.CodeSuccess})
+	}, Options{TokenSource: tokens})
+
+	for i := 0; i < 2; i++ {
+		if err := c.Users.Delete(context.Background(), 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if issued.Load() != 2 {
+		t.Errorf("tokens issued = %d, want a refresh for each request", issued.Load())
+	}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		status   int
+		wantReqs int32
+	}{
+		{"GET 500 retried", http.MethodGet, http.StatusInternalServerError, 4},
+		{"GET 502 retried", http.MethodGet, http.StatusBadGateway, 4},
+		{"POST 500 not retried", http.MethodPost, http.StatusInternalServerError, 1},
+		{"POST 503 retried", http.MethodPost, http.StatusServiceUnavailable, 4},
+		{"POST 429 retried", http.MethodPost, http.StatusTooManyRequests, 4},
+		{"GET 404 not retried", http.MethodGet, http.StatusNotFound, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				writeJSON(w, tt.status, response.Response{Code: tt.status, Message: http.StatusText(tt.status)})
+			}, Options{})
+
+			var err error
+			if tt.method == http.MethodGet {
+				_, err = c.Users.Get(context.Background(), 1)
+			} else {
+				_, err = c.Users.Create(context.Background(), &CreateUserRequest{Username: "alice"})
+			}
+			if err == nil {
+				t.Fatal("error = nil, want API error")
+			}
+			if got := requests.Load(); got != tt.wantReqs {
+				t.Errorf("requests = %d, want %d", got, tt.wantReqs)
+			}
+		})
+	}
+}
+
+func TestRetrySucceeds(t *testing.T) {
+	var requests atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			writeJSON(w, http.StatusServiceUnavailable, response.Response{Code: 503})
+			return
+		}
+		writeJSON(w, http.StatusOK, response.Response{Code: response.CodeSuccess, Data: User{ID: 7}})
+	}, Options{})
+
+	user, err := c.Users.Get(context.Background(), 7)
+	if err != nil || user.ID != 7 {
+		t.Fatalf("Get() = %v, %v", user, err)
+	}
+}
+
+func TestRetryDisabled(t *testing.T) {
+	var requests atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		writeJSON(w, http.StatusServiceUnavailable, response.Response{Code: 503})
+	}, Options{MaxRetries: -1})
+
+	if _, err := c.Users.Get(context.Background(), 1); !errors.Is(err, ErrServer) {
+		t.Fatalf("Get() error = %v, want ErrServer", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("requests = %d, want 1", requests.Load())
+	}
+}
+
+func TestRetryAfterHonored(t *testing.T) {
+	var requests atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			writeJSON(w, http.StatusTooManyRequests, response.Response{Code: 429})
+			return
+		}
+		writeJSON(w, http.StatusOK, response.Response{Code: response.CodeSuccess})
+	}, Options{})
+
+	start := time.Now()
+	if err := c.Users.Delete(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("retried after %s, want Retry-After of 1s", elapsed)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		value string
+		min   time.Duration
+		max   time.Duration
+	}{
+		{"", 0, 0},
+		{"3", 3 * time.Second, 3 * time.Second},
+		{"0", 0, 0},
+		{"soon", 0, 0},
+		{time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat), 8 * time.Second, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}}
+		if tt.value != "" {
+			resp.Header.Set("Retry-After", tt.value)
+		}
+		if got := retryAfter(resp); got < tt.min || got > tt.max {
+			t.Errorf("retryAfter(%q) = %s, want between %s and %s", tt.value, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestUsersAll(t *testing.T) {
+	const total, pageSize = 5, 2
+	var pages atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		pages.Add(1)
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		size, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+		var users []User
+		for id := (page-1)*size + 1; id <= min(page*size, total); id++ {
+			users = append(users, User{ID: uint(id)})
+		}
+		writeJSON(w, http.StatusOK, response.PageResponse{
+			Code: response.CodeSuccess,
+			Data: users,
+			Meta: response.PageMeta{Page: page, PageSize: size, Total: total, TotalPages: (total + size - 1) / size},
+		})
+	}, Options{})
+
+	var ids []uint
+	for user, err := range c.Users.All(context.Background(), pageSize) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, user.ID)
+	}
+	if fmt.Sprint(ids) != "[1 2 3 4 5]" {
+		t.Errorf("ids = %v, want [1 2 3 4 5]", ids)
+	}
+	if pages.Load() != 3 {
+		t.Errorf("pages requested = %d, want 3", pages.Load())
+	}
+
+	// 提前结束遍历时不再请求后续页
+	pages.Store(0)
+	for range c.Users.All(context.Background(), pageSize) {
+		break
+	}
+	if pages.Load() != 1 {
+		t.Errorf("pages requested after break = %d, want 1", pages.Load())
+	}
+}
+
+func TestUsersAllError(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusForbidden, response.Response{Code: response.CodeForbidden, Message: "Forbidden"})
+	}, Options{})
+
+	var errs int
+	for user, err := range c.Users.All(context.Background(), 0) {
+		if user != nil || !errors.Is(err, ErrForbidden) {
+			t.Errorf("yielded %v, %v, want nil user and ErrForbidden", user, err)
+		}
+		errs++
+	}
+	if errs != 1 {
+		t.Errorf("yielded %d errors, want 1", errs)
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	tests := []struct {
+		status int
+		body   string
+		want   error
+	}{
+		{http.StatusBadRequest, `{"code":400,"message":"Validation failed","data":[{"in":"body","field":"email","message":"required"}]}`, ErrBadRequest},
+		{http.StatusUnauthorized, `{"code":401,"message":"Unauthorized"}`, ErrUnauthorized},
+		{http.StatusForbidden, `{"code":403,"message":"Forbidden"}`, ErrForbidden},
+		{http.StatusNotFound, `{"code":404,"message":"User not found"}`, ErrNotFound},
+		{http.StatusConflict, `{"code":409,"message":"username already exists"}`, ErrConflict},
+		{http.StatusTooManyRequests, `{"code":429,"message":"slow down"}`, ErrRateLimited},
+		{http.StatusInternalServerError, `{"code":500,"message":"Failed to get user"}`, ErrServer},
+		// 网关返回的非统一结构响应按HTTP状态码分类
+		{http.StatusBadGateway, `<html>bad gateway</html>`, ErrServer},
+		// 问题详情
+		{http.StatusNotFound, `{"type":"about:blank","title":"Not Found","status":404,"detail":"User not found","request_id":"req-1"}`, ErrNotFound},
+	}
+	for _, tt := range tests {
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			_, _ = w.Write([]byte(tt.body))
+		}, Options{MaxRetries: -1})
+
+		_, err := c.Users.Get(context.Background(), 1)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("status %d: error = %v, want %v", tt.status, err, tt.want)
+		}
+		var apiErr *Error
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+			t.Errorf("status %d: error = %#v, want *Error with the status code", tt.status, err)
+		}
+	}
+}
+
+func TestErrorDetails(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "req-42")
+		writeJSON(w, http.StatusBadRequest, response.Response{
+			Code:    response.CodeBadRequest,
+			Message: "Validation failed",
+			Data:    []FieldError{{In: "body", Field: "email", Message: "required"}},
+		})
+	}, Options{})
+
+	_, err := c.Users.Create(context.Background(), &CreateUserRequest{})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want *Error", err)
+	}
+	if apiErr.Message != "Validation failed" || apiErr.RequestID != "req-42" {
+		t.Errorf("error = %+v", apiErr)
+	}
+	if len(apiErr.Fields) != 1 || apiErr.Fields[0].Field != "email" {
+		t.Errorf("fields = %+v, want email error", apiErr.Fields)
+	}
+	if apiErr.Error() != "api error 400: Validation failed (request_id=req-42)" {
+		t.Errorf("Error() = %q", apiErr.Error())
+	}
+
+	problem := newError(&http.Response{StatusCode: http.StatusBadRequest, Header: http.Header{}}, envelope{
+		Title:     "Bad Request",
+		Detail:    "Validation failed",
+		RequestID: "req-7",
+		Errors:    []FieldError{{In: "query", Field: "page", Message: "must be a number"}},
+	})
+	if problem.Code != http.StatusBadRequest || problem.Message != "Validation failed" ||
+		problem.RequestID != "req-7" || len(problem.Fields) != 1 {
+		t.Errorf("problem error = %+v", problem)
+	}
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"go-api-scaffold/pkg/response"
+)
+
+// 按响应码分类的错误，可通过 errors.Is 判断
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("conflict")
+	ErrRateLimited  = errors.New("rate limited")
+	ErrServer       = errors.New("server error")
+)
+
+// FieldError 请求校验失败时的字段错误
+type FieldError = response.FieldError
+
+// Error 服务端返回的错误响应
+type Error struct {
+	StatusCode int          // HTTP状态码
+	Code       int          // 响应体中的业务码
+	Message    string       // 响应体中的错误信息
+	RequestID  string       // X-Request-ID，便于与服务端日志关联
+	Fields     []FieldError // 请求校验失败时的字段错误
+}
+
+// Error 错误描述
+func (e *Error) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("api error %d: %s (request_id=%s)", e.Code, e.Message, e.RequestID)
+	}
+	return fmt.Sprintf("api error %d: %s", e.Code, e.Message)
+}
+
+// Unwrap 返回对应的分类错误
+func (e *Error) Unwrap() error {
+	switch {
+	case e.Code == response.CodeBadRequest:
+		return ErrBadRequest
+	case e.Code == response.CodeUnauthorized:
+		return ErrUnauthorized
+	case e.Code == response.CodeForbidden:
+		return ErrForbidden
+	case e.Code == response.CodeNotFound:
+		return ErrNotFound
+	case e.Code == response.CodeConflict:
+		return ErrConflict
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrServer
+	default:
+		return nil
+	}
+}
+
+// newError 根据响应构建错误，响应体不是统一结构时以HTTP状态码作为业务码
+// 问题详情（application/problem+json）使用detail、title作为错误信息，errors作为字段错误
+func newError(resp *http.Response, env envelope) *Error {
+	e := &Error{
+		StatusCode: resp.StatusCode,
+		Code:       env.Code,
+		Message:    env.Message,
+		RequestID:  resp.Header.Get("X-Request-ID"),
+		Fields:     env.Errors,
+	}
+	if e.Code == 0 {
+		e.Code = resp.StatusCode
+	}
+	if e.Message == "" {
+		e.Message = env.Detail
+	}
+	if e.Message == "" {
+		e.Message = env.Title
+	}
+	if e.Message == "" {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+	if e.RequestID == "" {
+		e.RequestID = env.RequestID
+	}
+	if len(env.Data) > 0 {
+		_ = json.Unmarshal(env.Data, &e.Fields)
+	}
+	return e
+}
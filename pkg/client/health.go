@@ -0,0 +1,77 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Health 基础健康状态
+type Health struct {
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+	Version   string    `json:"version"`
+	Uptime    string    `json:"uptime"`
+}
+
+// Readiness 就绪状态，Checks为各依赖的检查结果（"ok"或错误信息）
+type Readiness struct {
+	Status    string            `json:"status"`
+	Checks    map[string]string `json:"checks"`
+	Timestamp time.Time         `json:"timestamp"`
+}
+
+// Ready 是否就绪
+func (r *Readiness) Ready() bool {
+	return r.Status == "ready"
+}
+
+// HealthService 健康检查接口
+type HealthService struct {
+	client *Client
+}
+
+// Check 基础健康检查
+func (s *HealthService) Check(ctx context.Context) (*Health, error) {
+	var health Health
+	if _, err := s.client.do(ctx, http.MethodGet, "/api/v1/health/check", nil, nil, &health); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// Ready 就绪检查，未就绪（503）时返回各依赖的检查结果而不是错误
+func (s *HealthService) Ready(ctx context.Context) (*Readiness, error) {
+	var readiness Readiness
+	if err := s.probe(ctx, "/api/v1/health/ready", &readiness); err != nil {
+		return nil, err
+	}
+	return &readiness, nil
+}
+
+// Live 存活检查
+func (s *HealthService) Live(ctx context.Context) error {
+	return s.probe(ctx, "/api/v1/health/live", nil)
+}
+
+// probe 就绪、存活检查不使用统一响应结构，且不重试，以便如实反映当前状态
+// out非nil时503也解码响应体，由调用方根据状态判断
+func (s *HealthService) probe(ctx context.Context, path string, out interface{}) error {
+	resp, body, err := s.client.sendOnce(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return err
+	}
+	decodable := resp.StatusCode == http.StatusOK ||
+		(out != nil && resp.StatusCode == http.StatusServiceUnavailable)
+	if !decodable {
+		return newError(resp, envelope{})
+	}
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// tokenRefreshSkew 令牌到期前提前刷新的时间，避免请求途中过期
+const tokenRefreshSkew = 30 * time.Second
+
+// Token 访问令牌
+type Token struct {
+	AccessToken string
+	// Expiry 过期时间，零值表示不过期
+	Expiry time.Time
+}
+
+// TokenSource 令牌来源，如向认证网关换取令牌
+// 客户端会缓存令牌，在过期前或服务端以Bearer质询返回401时重新获取
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// TokenSourceFunc 函数形式的令牌来源
+type TokenSourceFunc func(ctx context.Context) (*Token, error)
+
+// Token 获取令牌
+func (f TokenSourceFunc) Token(ctx context.Context) (*Token, error) {
+	return f(ctx)
+}
+
+// StaticToken 固定令牌
+func StaticToken(accessToken string) TokenSource {
+	return TokenSourceFunc(func(context.Context) (*Token, error) {
+		return &Token{AccessToken: accessToken}, nil
+	})
+}
+
+// tokenCache 缓存令牌，并发请求共享同一次刷新
+type tokenCache struct {
+	source TokenSource
+
+	mu      sync.Mutex
+	current *Token
+}
+
+// token 返回有效令牌，即将过期或已失效时重新获取
+func (t *tokenCache) token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.current != nil && (t.current.Expiry.IsZero() || time.Until(t.current.Expiry) > tokenRefreshSkew) {
+		return t.current.AccessToken, nil
+	}
+
+	token, err := t.source.Token(ctx)
+	if err != nil {
+		return "", err
+	}
+	if token == nil || token.AccessToken == "" {
+		return "", errors.New("token source returned empty token")
+	}
+	t.current = token
+	return token.AccessToken, nil
+}
+
+// invalidate 丢弃缓存的令牌，下次请求时重新获取
+func (t *tokenCache) invalidate() {
+	t.mu.Lock()
+	t.current = nil
+	t.mu.Unlock()
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"go-api-scaffold/internal/model"
+	"go-api-scaffold/pkg/response"
+)
+
+// 请求、响应模型与服务端共用
+type (
+	User              = model.UserResponse
+	CreateUserRequest = model.UserCreateRequest
+	UpdateUserRequest = model.UserUpdateRequest
+	PageMeta          = response.PageMeta
+)
+
+// defaultPageSize 遍历时每页数量，与服务端上限一致
+const defaultPageSize = 100
+
+// UsersService 用户接口
+type UsersService struct {
+	client *Client
+}
+
+// Create 创建用户
+func (s *UsersService) Create(ctx context.Context, req *CreateUserRequest) (*User, error) {
+	var user User
+	if _, err := s.client.do(ctx, http.MethodPost, "/api/v1/users/", nil, req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Get 获取用户
+func (s *UsersService) Get(ctx context.Context, id uint) (*User, error) {
+	var user User
+	if _, err := s.client.do(ctx, http.MethodGet, userPath(id), nil, nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Update 更新用户
+func (s *UsersService) Update(ctx context.Context, id uint, req *UpdateUserRequest) (*User, error) {
+	var user User
+	if _, err := s.client.do(ctx, http.MethodPut, userPath(id), nil, req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Delete 删除用户
+func (s *UsersService) Delete(ctx context.Context, id uint) error {
+	_, err := s.client.do(ctx, http.MethodDelete, userPath(id), nil, nil, nil)
+	return err
+}
+
+// List 获取一页用户
+func (s *UsersService) List(ctx context.Context, page, pageSize int) ([]*User, *PageMeta, error) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("page_size", strconv.Itoa(pageSize))
+
+	var users []*User
+	meta, err := s.client.do(ctx, http.MethodGet, "/api/v1/users/", query, nil, &users)
+	if err != nil {
+		return nil, nil, err
+	}
+	return users, meta, nil
+}
+
+// All 逐页遍历全部用户，pageSize<=0时使用100，出错时产出错误并结束遍历
+//
+//	for user, err := range c.Users.All(ctx, 0) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+func (s *UsersService) All(ctx context.Context, pageSize int) iter.Seq2[*User, error] {
+	return paginate(ctx, pageSize, s.List)
+}
+
+// userPath 用户详情路径
+func userPath(id uint) string {
+	return "/api/v1/users/" + strconv.FormatUint(uint64(id), 10)
+}
+
+// paginate 基于PageMeta的通用分页遍历
+func paginate[T any](ctx context.Context, pageSize int, list func(ctx context.Context, page, pageSize int) ([]T, *PageMeta, error)) iter.Seq2[T, error] {
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	return func(yield func(T, error) bool) {
+		for page := 1; ; page++ {
+			items, meta, err := list(ctx, page, pageSize)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+			if meta == nil || page >= meta.TotalPages || len(items) == 0 {
+				return
+			}
+		}
+	}
+}
@@ -1,60 +1,140 @@
 package database
 
 import (
+	"database/sql"
 	"fmt"
 	"time"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 
 	"go-api-scaffold/internal/config"
+	"go-api-scaffold/pkg/logger"
 )
 
 // New 创建数据库连接
-func New(cfg config.DatabaseConfig) (*gorm.DB, error) {
+func New(cfg config.DatabaseConfig, log logger.Logger) (*gorm.DB, error) {
 	// 只支持MySQL
 	if cfg.Driver != "mysql" {
 		return nil, fmt.Errorf("unsupported database driver: %s, only mysql is supported", cfg.Driver)
 	}
 
 	// MySQL DSN
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		cfg.Username, cfg.Password, cfg.Host, cfg.Port, cfg.Database)
-	dialector := mysql.Open(dsn)
+	dsn := mysqlDSN(cfg.Username, cfg.Password.Value(), cfg.Host, cfg.Port, cfg.Database)
 
-	// GORM配置
-	gormConfig := &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info),
-	}
+	// GORM日志
+	gormLogger := NewGormLogger(log, GormLoggerConfig{
+		LogLevel:      ParseGormLogLevel(cfg.LogLevel),
+		SlowThreshold: cfg.SlowThreshold,
+		RedactColumns: cfg.RedactColumns,
+	})
+
+	// 创建数据库连接，数据库尚未就绪时按配置退避重试
+	var db *gorm.DB
+	err := connectWithRetry(cfg.Retry, log.WithField("component", "database"), func() error {
+		// gorm.Open会修改配置对象，每次尝试使用新的配置
+		conn, err := gorm.Open(mysql.Open(dsn), &gorm.Config{Logger: gormLogger})
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+
+		// 获取底层sql.DB对象进行连接池配置
+		sqlDB, err := conn.DB()
+		if err != nil {
+			return fmt.Errorf("failed to get underlying sql.DB: %w", err)
+		}
+
+		// 设置连接池参数
+		configurePool(sqlDB, cfg)
 
-	// 创建数据库连接
-	db, err := gorm.Open(dialector, gormConfig)
+		// 测试连接
+		if err := sqlDB.Ping(); err != nil {
+			_ = sqlDB.Close()
+			return fmt.Errorf("failed to ping database: %w", err)
+		}
+
+		db = conn
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, err
 	}
 
-	// 获取底层sql.DB对象进行连接池配置
-	sqlDB, err := db.DB()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get underlying sql.DB: %w", err)
+	// 注册只读副本
+	if len(cfg.Replicas) > 0 {
+		replicas, err := openReplicas(cfg, log)
+		if err != nil {
+			return nil, err
+		}
+		if err := db.Use(replicas); err != nil {
+			_ = replicas.Close()
+			return nil, fmt.Errorf("failed to register replicas: %w", err)
+		}
 	}
 
-	// 设置连接池参数
-	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
-	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
-	sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
+	return db, nil
+}
+
+// openReplicas 连接所有只读副本，副本暂不可用时先摘除，由健康检查负责恢复
+func openReplicas(cfg config.DatabaseConfig, log logger.Logger) (*ReplicaSet, error) {
+	rs := newReplicaSet(log, cfg.ReplicaCheckPeriod, cfg.ReplicaMaxFailures)
 
-	// 测试连接
-	if err := sqlDB.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+	for _, rc := range cfg.Replicas {
+		host, port := rc.Host, rc.Port
+		if host == "" {
+			host = cfg.Host
+		}
+		if port == 0 {
+			port = cfg.Port
+		}
+		username, password, database := rc.Username, rc.Password.Value(), rc.Database
+		if username == "" {
+			username, password = cfg.Username, cfg.Password.Value()
+		}
+		if database == "" {
+			database = cfg.Database
+		}
+
+		sqlDB, err := sql.Open("mysql", mysqlDSN(username, password, host, port, database))
+		if err != nil {
+			_ = rs.Close()
+			return nil, fmt.Errorf("failed to open replica %s:%d: %w", host, port, err)
+		}
+		configurePool(sqlDB, cfg)
+
+		name := fmt.Sprintf("%s:%d", host, port)
+		r := rs.add(name, sqlDB)
+		if err := sqlDB.Ping(); err != nil {
+			// 启动时不可达的副本先摘除，健康检查探测成功后再加入
+			r.healthy.Store(false)
+			log.WithError(err).WithField("replica", name).Warn("Replica is not reachable, ejected until it recovers")
+		}
 	}
 
-	return db, nil
+	return rs, nil
+}
+
+// mysqlDSN 构建MySQL连接串
+func mysqlDSN(username, password, host string, port int, database string) string {
+	return fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+		username, password, host, port, database)
+}
+
+// configurePool 设置连接池参数
+func configurePool(sqlDB *sql.DB, cfg config.DatabaseConfig) {
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime) * time.Second)
 }
 
 // Close 关闭数据库连接
 func Close(db *gorm.DB) error {
+	if plugin, ok := db.Config.Plugins[replicaPluginName]; ok {
+		if rs, ok := plugin.(*ReplicaSet); ok {
+			_ = rs.Close()
+		}
+	}
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		return err
@@ -0,0 +1,195 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+
+	"go-api-scaffold/pkg/logger"
+)
+
+// redactedValue 脱敏后的SQL参数占位值
+const redactedValue = "[REDACTED]"
+
+// defaultRedactColumns 默认需要脱敏的列
+var defaultRedactColumns = []string{"password", "secret", "token"}
+
+// comparedColumnPattern 匹配占位符前的 "col =" / "`col` <>" 等比较表达式
+var comparedColumnPattern = regexp.MustCompile("(?i)[`\"]?(\\w+)[`\"]?\\s*(?:=|<>|!=|>=|<=|>|<|like)\\s*$")
+
+// GormLoggerConfig GORM日志适配器配置
+type GormLoggerConfig struct {
+	LogLevel      gormlogger.LogLevel
+	SlowThreshold time.Duration
+	RedactColumns []string
+}
+
+// gormLogger 将GORM日志输出到应用日志
+type gormLogger struct {
+	log           logger.Logger
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+	redact        map[string]struct{}
+}
+
+// NewGormLogger 创建基于应用日志的GORM日志适配器
+func NewGormLogger(log logger.Logger, cfg GormLoggerConfig) gormlogger.Interface {
+	columns := cfg.RedactColumns
+	if len(columns) == 0 {
+		columns = defaultRedactColumns
+	}
+	redact := make(map[string]struct{}, len(columns))
+	for _, col := range columns {
+		redact[strings.ToLower(col)] = struct{}{}
+	}
+
+	return &gormLogger{
+		log:           log.WithField("component", "gorm"),
+		level:         cfg.LogLevel,
+		slowThreshold: cfg.SlowThreshold,
+		redact:        redact,
+	}
+}
+
+// ParseGormLogLevel 将应用日志级别映射为GORM日志级别
+// debug级别输出全部SQL，info/warn仅输出慢查询和错误，error仅输出错误
+func ParseGormLogLevel(level string) gormlogger.LogLevel {
+	switch strings.ToLower(level) {
+	case "debug":
+		return gormlogger.Info
+	case "info", "warn":
+		return gormlogger.Warn
+	case "error", "fatal":
+		return gormlogger.Error
+	case "silent":
+		return gormlogger.Silent
+	default:
+		return gormlogger.Warn
+	}
+}
+
+// LogMode 实现gormlogger.Interface
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	next := *l
+	next.level = level
+	return &next
+}
+
+// Info 实现gormlogger.Interface
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.log.WithContext(ctx).Infof(msg, args...)
+	}
+}
+
+// Warn 实现gormlogger.Interface
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.log.WithContext(ctx).Warnf(msg, args...)
+	}
+}
+
+// Error 实现gormlogger.Interface
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.log.WithContext(ctx).Errorf(msg, args...)
+	}
+}
+
+// Trace 记录SQL执行事件，包含耗时、影响行数和调用位置
+// 错误、慢查询、普通SQL分别以Error、Warn、Info级别写入应用日志，仍受log.level过滤
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	slow := l.slowThreshold > 0 && elapsed > l.slowThreshold
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+
+	switch {
+	case failed && l.level >= gormlogger.Error:
+	case slow && l.level >= gormlogger.Warn:
+	case l.level >= gormlogger.Info:
+	default:
+		return
+	}
+
+	sql, rows := fc()
+	entry := l.log.WithContext(ctx).WithFields(map[string]interface{}{
+		"sql":         sql,
+		"duration_ms": float64(elapsed.Nanoseconds()) / 1e6,
+		"rows":        rows,
+		"source":      utils.FileWithLineNum(),
+	})
+
+	switch {
+	case failed:
+		entry.WithError(err).Error("SQL error")
+	case slow:
+		entry.WithField("slow_threshold", l.slowThreshold.String()).Warn("Slow SQL")
+	default:
+		// 是否输出全部SQL已由GORM日志级别决定，这里使用Info级别，
+		// database.log_level为debug、log.level为info时SQL仍能输出
+		entry.Info("SQL executed")
+	}
+}
+
+// ParamsFilter 实现gorm.ParamsFilter，在SQL写入日志前对敏感列参数脱敏
+func (l *gormLogger) ParamsFilter(_ context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if len(params) == 0 || len(l.redact) == 0 {
+		return sql, params
+	}
+
+	filtered := make([]interface{}, len(params))
+	copy(filtered, params)
+
+	for i, col := range placeholderColumns(sql, len(params)) {
+		if _, ok := l.redact[strings.ToLower(col)]; ok {
+			filtered[i] = redactedValue
+		}
+	}
+	return sql, filtered
+}
+
+// placeholderColumns 推断每个?占位符对应的列名，无法推断时为空字符串
+func placeholderColumns(sql string, n int) []string {
+	columns := make([]string, n)
+
+	// INSERT INTO t (`a`,`b`) VALUES (?,?),(?,?) 按列顺序循环对应
+	upper := strings.ToUpper(sql)
+	if strings.HasPrefix(strings.TrimSpace(upper), "INSERT") {
+		open := strings.Index(sql, "(")
+		values := strings.Index(upper, "VALUES")
+		if open >= 0 && values > open {
+			if end := strings.Index(sql[open:values], ")"); end > 0 {
+				names := strings.Split(sql[open+1:open+end], ",")
+				for i := range names {
+					names[i] = strings.Trim(strings.TrimSpace(names[i]), "`\"")
+				}
+				for i := 0; i < n; i++ {
+					columns[i] = names[i%len(names)]
+				}
+				return columns
+			}
+		}
+	}
+
+	// UPDATE ... SET `a`=?, WHERE `b` = ? 等比较表达式
+	segments := strings.Split(sql, "?")
+	for i := 0; i < n && i < len(segments); i++ {
+		if columns[i] != "" {
+			continue
+		}
+		if m := comparedColumnPattern.FindStringSubmatch(segments[i]); m != nil {
+			columns[i] = m[1]
+		}
+	}
+	return columns
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	gormlogger "gorm.io/gorm/logger"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/pkg/logger"
+)
+
+// traceOutput 以给定的应用日志级别和数据库日志级别记录一条SQL，返回日志内容
+func traceOutput(t *testing.T, appLevel, dbLevel string, elapsed time.Duration, err error) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.log")
+	log := logger.New(config.LogConfig{Level: appLevel, Format: "text", Output: "file", File: config.LogFileConfig{Path: path}})
+
+	l := NewGormLogger(log, GormLoggerConfig{
+		LogLevel:      ParseGormLogLevel(dbLevel),
+		SlowThreshold: 100 * time.Millisecond,
+	})
+	l.Trace(context.Background(), time.Now().Add(-elapsed), func() (string, int64) {
+		return "SELECT * FROM `users` WHERE id = 1", 1
+	}, err)
+
+	if err := logger.Close(log); err != nil {
+		t.Fatal(err)
+	}
+	out, readErr := os.ReadFile(path)
+	if readErr != nil && !os.IsNotExist(readErr) {
+		t.Fatal(readErr)
+	}
+	return string(out)
+}
+
+func TestGormLoggerTrace(t *testing.T) {
+	tests := []struct {
+		name     string
+		appLevel string
+		dbLevel  string
+		elapsed  time.Duration
+		err      error
+		want     string
+	}{
+		{"debug sql with info app log", "info", "debug", time.Millisecond, nil, "SQL executed"},
+		{"debug sql with debug app log", "debug", "debug", time.Millisecond, nil, "SQL executed"},
+		{"info db level hides fast sql", "info", "info", time.Millisecond, nil, ""},
+		{"warn app log hides sql", "warn", "debug", time.Millisecond, nil, ""},
+		{"slow sql", "info", "info", time.Second, nil, "Slow SQL"},
+		{"error db level hides slow sql", "info", "error", time.Second, nil, ""},
+		{"failed sql", "info", "error", time.Millisecond, errors.New("boom"), "SQL error"},
+		{"silent", "debug", "silent", time.Second, errors.New("boom"), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := traceOutput(t, tt.appLevel, tt.dbLevel, tt.elapsed, tt.err)
+			if tt.want == "" {
+				if out != "" {
+					t.Fatalf("unexpected log output: %s", out)
+				}
+				return
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Fatalf("log output %q does not contain %q", out, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGormLogLevel(t *testing.T) {
+	tests := map[string]gormlogger.LogLevel{
+		"debug":   gormlogger.Info,
+		"DEBUG":   gormlogger.Info,
+		"info":    gormlogger.Warn,
+		"warn":    gormlogger.Warn,
+		"error":   gormlogger.Error,
+		"fatal":   gormlogger.Error,
+		"silent":  gormlogger.Silent,
+		"unknown": gormlogger.Warn,
+	}
+	for level, want := range tests {
+		if got := ParseGormLogLevel(level); got != want {
+			t.Errorf("ParseGormLogLevel(%q) = %v, want %v", level, got, want)
+		}
+	}
+}
+
+func TestGormLoggerParamsFilter(t *testing.T) {
+	l := NewGormLogger(logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"}), GormLoggerConfig{}).(*gormLogger)
+
+	tests := []struct {
+		sql    string
+		params []interface{}
+		want   []interface{}
+	}{
+		{
+			"INSERT INTO `users` (`username`,`password`) VALUES (?,?),(?,?)",
+			[]interface{}{"a", "p1", "b", "p2"},
+			[]interface{}{"a", redactedValue, "b", redactedValue},
+		},
+		{
+			"UPDATE `users` SET `password`=?,`updated_at`=? WHERE `id` = ?",
+			[]interface{}{"secret", "now", 1},
+			[]interface{}{redactedValue, "now", 1},
+		},
+		{
+			"SELECT * FROM `webhooks` WHERE secret <> ? AND name LIKE ?",
+			[]interface{}{"s", "n%"},
+			[]interface{}{redactedValue, "n%"},
+		},
+		{"SELECT 1", nil, nil},
+	}
+	for _, tt := range tests {
+		_, got := l.ParamsFilter(context.Background(), tt.sql, tt.params...)
+		if len(got) != len(tt.want) {
+			t.Fatalf("ParamsFilter(%q) = %v, want %v", tt.sql, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("ParamsFilter(%q)[%d] = %v, want %v", tt.sql, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"go-api-scaffold/pkg/logger"
+)
+
+// ErrNotChecked 尚未完成首次探测
+var ErrNotChecked = errors.New("database connection not checked yet")
+
+// Monitor 运行期数据库连接探测
+// 连接断开时由database/sql自动重连，Monitor仅记录状态供就绪检查使用，不会终止进程
+type Monitor struct {
+	db       *gorm.DB
+	log      logger.Logger
+	interval time.Duration
+
+	mu      sync.RWMutex
+	lastErr error
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewMonitor 创建连接探测器并立即开始探测
+func NewMonitor(db *gorm.DB, log logger.Logger, interval time.Duration) *Monitor {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	m := &Monitor{
+		db:       db,
+		log:      log.WithField("component", "database"),
+		interval: interval,
+		lastErr:  ErrNotChecked,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	m.probe()
+	go m.run()
+	return m
+}
+
+// Check 返回最近一次探测结果，可直接用作就绪检查
+func (m *Monitor) Check(_ context.Context) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastErr
+}
+
+// Stop 停止探测
+func (m *Monitor) Stop() {
+	close(m.stop)
+	<-m.done
+}
+
+// run 周期性探测连接
+func (m *Monitor) run() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.probe()
+		}
+	}
+}
+
+// probe 执行一次探测并在状态变化时记录日志
+func (m *Monitor) probe() {
+	ctx, cancel := context.WithTimeout(context.Background(), m.interval)
+	defer cancel()
+
+	err := Ping(ctx, m.db)
+
+	m.mu.Lock()
+	prev := m.lastErr
+	m.lastErr = err
+	m.mu.Unlock()
+
+	switch {
+	case err != nil && prev == nil:
+		m.log.WithError(err).Error("Database connection lost")
+	case err == nil && prev != nil && !errors.Is(prev, ErrNotChecked):
+		m.log.Info("Database connection restored")
+	}
+}
+
+// Ping 探测主库连接
+func Ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
@@ -0,0 +1,208 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"go-api-scaffold/pkg/logger"
+)
+
+// replicaPluginName 读写分离插件名
+const replicaPluginName = "database:replicas"
+
+// primaryKey 上下文中强制走主库的标记
+type primaryKey struct{}
+
+// WithPrimary 返回强制读主库的上下文，用于写后立即读等需要强一致的场景
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// PrimaryRequested 判断上下文是否要求读主库
+func PrimaryRequested(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	v, _ := ctx.Value(primaryKey{}).(bool)
+	return v
+}
+
+// replica 只读副本
+type replica struct {
+	name     string
+	db       *sql.DB
+	healthy  atomic.Bool
+	failures int
+}
+
+// ReplicaSet 读写分离插件
+// 查询在非事务、未加锁、未强制主库时路由到健康的只读副本，其余语句走主库
+type ReplicaSet struct {
+	replicas    []*replica
+	next        atomic.Uint64
+	log         logger.Logger
+	interval    time.Duration
+	maxFailures int
+	primary     gorm.ConnPool
+	stop        chan struct{}
+	wg          sync.WaitGroup
+}
+
+// newReplicaSet 创建读写分离插件
+func newReplicaSet(log logger.Logger, interval time.Duration, maxFailures int) *ReplicaSet {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	if maxFailures <= 0 {
+		maxFailures = 3
+	}
+	return &ReplicaSet{
+		log:         log.WithField("component", "replicas"),
+		interval:    interval,
+		maxFailures: maxFailures,
+		stop:        make(chan struct{}),
+	}
+}
+
+// add 添加只读副本，初始状态为健康
+func (rs *ReplicaSet) add(name string, db *sql.DB) *replica {
+	r := &replica{name: name, db: db}
+	r.healthy.Store(true)
+	rs.replicas = append(rs.replicas, r)
+	return r
+}
+
+// Name 实现gorm.Plugin
+func (rs *ReplicaSet) Name() string {
+	return replicaPluginName
+}
+
+// Initialize 实现gorm.Plugin，注册查询路由回调并启动健康检查
+func (rs *ReplicaSet) Initialize(db *gorm.DB) error {
+	rs.primary = db.ConnPool
+
+	if err := db.Callback().Query().Before("gorm:query").Register("database:route_query", rs.route); err != nil {
+		return err
+	}
+	if err := db.Callback().Row().Before("gorm:row").Register("database:route_row", rs.route); err != nil {
+		return err
+	}
+	if err := db.Callback().Raw().Before("gorm:raw").Register("database:route_raw", rs.routeRaw); err != nil {
+		return err
+	}
+
+	rs.wg.Add(1)
+	go rs.healthCheck()
+	return nil
+}
+
+// route 为只读查询选择副本
+func (rs *ReplicaSet) route(db *gorm.DB) {
+	stmt := db.Statement
+	// 事务中、自定义连接或显式要求主库时不切换
+	if stmt.ConnPool != rs.primary || PrimaryRequested(stmt.Context) {
+		return
+	}
+	// SELECT ... FOR UPDATE 等加锁读必须走主库
+	if _, locking := stmt.Clauses[clause.Locking{}.Name()]; locking {
+		return
+	}
+	if r := rs.pick(); r != nil {
+		stmt.ConnPool = r.db
+	}
+}
+
+// routeRaw 仅对Raw中的SELECT语句进行路由
+func (rs *ReplicaSet) routeRaw(db *gorm.DB) {
+	sql := strings.TrimSpace(db.Statement.SQL.String())
+	if len(sql) < 6 || !strings.EqualFold(sql[:6], "select") {
+		return
+	}
+	if strings.Contains(strings.ToUpper(sql), "FOR UPDATE") {
+		return
+	}
+	rs.route(db)
+}
+
+// pick 轮询选择健康副本，全部不可用时返回nil回退主库
+func (rs *ReplicaSet) pick() *replica {
+	n := len(rs.replicas)
+	start := rs.next.Add(1)
+	for i := 0; i < n; i++ {
+		r := rs.replicas[(start+uint64(i))%uint64(n)]
+		if r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+// healthCheck 周期性探测副本，连续失败达到阈值后摘除，恢复后重新加入
+func (rs *ReplicaSet) healthCheck() {
+	defer rs.wg.Done()
+
+	ticker := time.NewTicker(rs.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rs.stop:
+			return
+		case <-ticker.C:
+			for _, r := range rs.replicas {
+				rs.probe(r)
+			}
+		}
+	}
+}
+
+// probe 探测单个副本
+func (rs *ReplicaSet) probe(r *replica) {
+	ctx, cancel := context.WithTimeout(context.Background(), rs.interval)
+	defer cancel()
+
+	if err := r.db.PingContext(ctx); err != nil {
+		r.failures++
+		if r.failures >= rs.maxFailures && r.healthy.Swap(false) {
+			rs.log.WithError(err).WithField("replica", r.name).Warn("Replica ejected")
+		}
+		return
+	}
+
+	r.failures = 0
+	if !r.healthy.Swap(true) {
+		rs.log.WithField("replica", r.name).Info("Replica recovered")
+	}
+}
+
+// Healthy 返回当前健康的副本数量
+func (rs *ReplicaSet) Healthy() int {
+	n := 0
+	for _, r := range rs.replicas {
+		if r.healthy.Load() {
+			n++
+		}
+	}
+	return n
+}
+
+// Close 停止健康检查并关闭副本连接
+func (rs *ReplicaSet) Close() error {
+	close(rs.stop)
+	rs.wg.Wait()
+
+	var firstErr error
+	for _, r := range rs.replicas {
+		if err := r.db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
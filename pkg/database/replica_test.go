@@ -0,0 +1,217 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	gormlogger "gorm.io/gorm/logger"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/pkg/logger"
+)
+
+// item 测试表，source标明数据来自主库还是副本
+type item struct {
+	ID     uint
+	Source string
+}
+
+func testLogger() logger.Logger {
+	return logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"})
+}
+
+// openSQLite 打开独立的SQLite数据库并写入一条标明来源的数据
+func openSQLite(t *testing.T, source string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), source+".db")), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&item{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&item{Source: source}).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// newReplicated 主库和一个副本，副本由ReplicaSet管理
+func newReplicated(t *testing.T) (*gorm.DB, *ReplicaSet) {
+	t.Helper()
+	primary := openSQLite(t, "primary")
+	replicaDB, err := openSQLite(t, "replica").DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rs := newReplicaSet(testLogger(), time.Hour, 2)
+	rs.add("replica", replicaDB)
+	if err := primary.Use(rs); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = rs.Close() })
+	return primary, rs
+}
+
+// source 读取第一条数据的来源
+func source(t *testing.T, db *gorm.DB) string {
+	t.Helper()
+	var it item
+	if err := db.Order("id").First(&it).Error; err != nil {
+		t.Fatal(err)
+	}
+	return it.Source
+}
+
+func TestReplicaRouting(t *testing.T) {
+	db, _ := newReplicated(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		read func() string
+		want string
+	}{
+		{"plain read", func() string { return source(t, db.WithContext(ctx)) }, "replica"},
+		{"raw select", func() string {
+			var s string
+			if err := db.WithContext(ctx).Raw("SELECT source FROM items ORDER BY id LIMIT 1").Scan(&s).Error; err != nil {
+				t.Fatal(err)
+			}
+			return s
+		}, "replica"},
+		{"primary requested", func() string { return source(t, db.WithContext(WithPrimary(ctx))) }, "primary"},
+		{"locking read", func() string {
+			return source(t, db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}))
+		}, "primary"},
+		{"transaction", func() string {
+			var s string
+			_ = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+				s = source(t, tx)
+				return nil
+			})
+			return s
+		}, "primary"},
+	}
+	for _, tt := range tests {
+		if got := tt.read(); got != tt.want {
+			t.Errorf("%s: source = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReplicaWritesGoToPrimary(t *testing.T) {
+	db, _ := newReplicated(t)
+	ctx := context.Background()
+
+	if err := db.WithContext(ctx).Create(&item{Source: "written"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	count := func(ctx context.Context) int64 {
+		var n int64
+		if err := db.WithContext(ctx).Model(&item{}).Count(&n).Error; err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if n := count(WithPrimary(ctx)); n != 2 {
+		t.Errorf("primary count = %d, want 2", n)
+	}
+	if n := count(ctx); n != 1 {
+		t.Errorf("replica count = %d, want 1", n)
+	}
+}
+
+func TestReplicaFallbackToPrimary(t *testing.T) {
+	db, rs := newReplicated(t)
+	rs.replicas[0].healthy.Store(false)
+
+	if got := source(t, db.WithContext(context.Background())); got != "primary" {
+		t.Errorf("source = %s, want primary when no replica is healthy", got)
+	}
+}
+
+// flakyConnector 可切换可用性的连接器，用于模拟副本宕机和恢复
+type flakyConnector struct {
+	down atomic.Bool
+}
+
+func (c *flakyConnector) Connect(context.Context) (driver.Conn, error) {
+	if c.down.Load() {
+		return nil, errors.New("connection refused")
+	}
+	return &flakyConn{connector: c}, nil
+}
+
+func (c *flakyConnector) Driver() driver.Driver { return nil }
+
+type flakyConn struct {
+	connector *flakyConnector
+}
+
+func (c *flakyConn) Ping(context.Context) error {
+	if c.connector.down.Load() {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+func (c *flakyConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *flakyConn) Close() error                        { return nil }
+func (c *flakyConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func TestReplicaEjectionAndRecovery(t *testing.T) {
+	connector := &flakyConnector{}
+	rs := newReplicaSet(testLogger(), time.Second, 2)
+	r := rs.add("flaky", sql.OpenDB(connector))
+	defer r.db.Close()
+
+	connector.down.Store(true)
+	rs.probe(r)
+	if rs.Healthy() != 1 {
+		t.Fatal("replica ejected after one failure, want replica_max_failures consecutive failures")
+	}
+	rs.probe(r)
+	if rs.Healthy() != 0 {
+		t.Fatal("replica still healthy after reaching replica_max_failures")
+	}
+	if rs.pick() != nil {
+		t.Fatal("pick() returned an ejected replica")
+	}
+
+	connector.down.Store(false)
+	rs.probe(r)
+	if rs.Healthy() != 1 || r.failures != 0 {
+		t.Fatalf("healthy = %d, failures = %d after recovery, want 1 and 0", rs.Healthy(), r.failures)
+	}
+}
+
+// TestOpenReplicasUnreachable 启动时不可达的副本立即摘除，不会等待健康检查累计失败
+func TestOpenReplicasUnreachable(t *testing.T) {
+	rs, err := openReplicas(config.DatabaseConfig{
+		Host:     "127.0.0.1",
+		Port:     1,
+		Username: "root",
+		Database: "test",
+		Replicas: []config.ReplicaConfig{{}},
+	}, testLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rs.Close()
+
+	if rs.Healthy() != 0 {
+		t.Errorf("Healthy() = %d, want unreachable replica ejected at startup", rs.Healthy())
+	}
+}
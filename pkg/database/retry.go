@@ -0,0 +1,45 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/retry"
+)
+
+// connectWithRetry 按指数退避加抖动重试connect，直到成功或超过最大等待时间
+func connectWithRetry(cfg config.DatabaseRetryConfig, log logger.Logger, connect func() error) error {
+	interval := cfg.InitialInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		err := connect()
+		if err == nil {
+			if attempt > 1 {
+				log.WithFields(map[string]interface{}{
+					"attempt": attempt,
+					"elapsed": time.Since(start).String(),
+				}).Info("Database connected after retry")
+			}
+			return nil
+		}
+
+		// 加入抖动，避免多实例同时重连
+		delay := retry.Jitter(retry.Backoff(interval, cfg.MaxInterval, attempt))
+		if time.Since(start)+delay > cfg.MaxWait {
+			return fmt.Errorf("giving up after %d attempts in %s: %w", attempt, time.Since(start).Round(time.Millisecond), err)
+		}
+
+		log.WithError(err).WithFields(map[string]interface{}{
+			"attempt":    attempt,
+			"next_retry": delay.Round(time.Millisecond).String(),
+		}).Warn("Database connection failed, retrying")
+
+		time.Sleep(delay)
+	}
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/pkg/logger"
+)
+
+func TestConnectWithRetry(t *testing.T) {
+	log := logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"})
+	cfg := config.DatabaseRetryConfig{
+		InitialInterval: time.Millisecond,
+		MaxInterval:     4 * time.Millisecond,
+		MaxWait:         time.Second,
+	}
+	refused := errors.New("connection refused")
+
+	attempts := 0
+	err := connectWithRetry(cfg, log, func() error {
+		if attempts++; attempts < 4 {
+			return refused
+		}
+		return nil
+	})
+	if err != nil || attempts != 4 {
+		t.Fatalf("connectWithRetry() = %v after %d attempts, want success on the fourth", err, attempts)
+	}
+
+	// 超过最大等待时间后放弃并返回最后一次错误
+	cfg.MaxWait = 10 * time.Millisecond
+	start := time.Now()
+	err = connectWithRetry(cfg, log, func() error { return refused })
+	if !errors.Is(err, refused) {
+		t.Errorf("connectWithRetry() error = %v, want the connect error", err)
+	}
+	if elapsed := time.Since(start); elapsed > cfg.MaxWait+50*time.Millisecond {
+		t.Errorf("gave up after %s, want about MaxWait", elapsed)
+	}
+}
@@ -0,0 +1,118 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go-api-scaffold/pkg/logger"
+)
+
+// Event 领域事件
+type Event interface {
+	EventName() string
+}
+
+// Handler 事件处理函数
+type Handler func(ctx context.Context, event Event) error
+
+// subscription 订阅信息
+type subscription struct {
+	handler Handler
+	async   bool
+}
+
+// Bus 进程内事件总线
+// 同步订阅者在Publish调用中依次执行，错误会返回给发布方；异步订阅者在独立goroutine中执行，错误仅记录日志
+type Bus struct {
+	mu     sync.RWMutex
+	subs   map[string][]subscription
+	wg     sync.WaitGroup
+	logger logger.Logger
+
+	ctx    context.Context // 异步订阅者的取消信号，强制关闭时取消
+	cancel context.CancelFunc
+}
+
+// New 创建事件总线
+func New(logger logger.Logger) *Bus {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Bus{
+		subs:   make(map[string][]subscription),
+		logger: logger.WithField("component", "eventbus"),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Subscribe 注册同步订阅者
+func (b *Bus) Subscribe(name string, handler Handler) {
+	b.subscribe(name, handler, false)
+}
+
+// SubscribeAsync 注册异步订阅者
+func (b *Bus) SubscribeAsync(name string, handler Handler) {
+	b.subscribe(name, handler, true)
+}
+
+// subscribe 注册订阅者
+func (b *Bus) subscribe(name string, handler Handler, async bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[name] = append(b.subs[name], subscription{handler: handler, async: async})
+}
+
+// Publish 发布事件
+func (b *Bus) Publish(ctx context.Context, event Event) error {
+	b.mu.RLock()
+	subs := b.subs[event.EventName()]
+	b.mu.RUnlock()
+
+	for _, sub := range subs {
+		if sub.async {
+			b.wg.Add(1)
+			// 异步订阅者不受请求取消影响，只在总线强制关闭时取消
+			go b.runAsync(context.WithoutCancel(ctx), sub.handler, event)
+			continue
+		}
+		if err := sub.handler(ctx, event); err != nil {
+			return fmt.Errorf("handle %s: %w", event.EventName(), err)
+		}
+	}
+	return nil
+}
+
+// runAsync 执行异步订阅者，捕获panic避免影响进程
+func (b *Bus) runAsync(ctx context.Context, handler Handler, event Event) {
+	defer b.wg.Done()
+	ctx, cancel := context.WithCancel(ctx)
+	defer context.AfterFunc(b.ctx, cancel)()
+	defer cancel()
+	defer func() {
+		if r := recover(); r != nil {
+			b.logger.WithContext(ctx).WithField("event", event.EventName()).Errorf("Async event handler panic: %v", r)
+		}
+	}()
+
+	if err := handler(ctx, event); err != nil {
+		b.logger.WithContext(ctx).WithError(err).WithField("event", event.EventName()).Error("Async event handler failed")
+	}
+}
+
+// Shutdown 等待所有异步订阅者执行完毕，用于优雅关闭
+// ctx到期时取消执行中订阅者的上下文并返回ctx.Err()
+func (b *Bus) Shutdown(ctx context.Context) error {
+	drained := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,79 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/pkg/logger"
+)
+
+type testEvent struct{}
+
+func (testEvent) EventName() string { return "test.event" }
+
+func TestBusShutdown(t *testing.T) {
+	log := logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"})
+	bus := New(log)
+
+	started := make(chan struct{})
+	canceled := make(chan error, 1)
+	bus.SubscribeAsync("test.event", func(ctx context.Context, _ Event) error {
+		close(started)
+		<-ctx.Done()
+		canceled <- ctx.Err()
+		return ctx.Err()
+	})
+
+	// 请求结束不影响异步订阅者
+	reqCtx, cancelReq := context.WithCancel(context.Background())
+	if err := bus.Publish(reqCtx, testEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	cancelReq()
+	select {
+	case <-canceled:
+		t.Fatal("async handler canceled by request context")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := bus.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown() = %v, want deadline exceeded", err)
+	}
+	select {
+	case err := <-canceled:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("handler context error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("async handler not canceled after shutdown deadline")
+	}
+}
+
+func TestBusShutdownDrains(t *testing.T) {
+	log := logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"})
+	bus := New(log)
+
+	done := make(chan struct{})
+	bus.SubscribeAsync("test.event", func(context.Context, Event) error {
+		time.Sleep(10 * time.Millisecond)
+		close(done)
+		return nil
+	})
+	if err := bus.Publish(context.Background(), testEvent{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() = %v", err)
+	}
+	select {
+	case <-done:
+	default:
+		t.Fatal("Shutdown returned before async handler finished")
+	}
+}
@@ -0,0 +1,157 @@
+package eventbus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/pkg/logger"
+)
+
+// Message 待投递的外部消息
+type Message struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	Payload    json.RawMessage `json:"payload"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// Sink 外部消息投递目标
+type Sink interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// LogSink 将消息写入日志，适用于开发环境
+type LogSink struct {
+	logger logger.Logger
+}
+
+// NewLogSink 创建日志投递目标
+func NewLogSink(logger logger.Logger) *LogSink {
+	return &LogSink{logger: logger.WithField("component", "event_sink")}
+}
+
+// Send 实现Sink接口
+func (s *LogSink) Send(ctx context.Context, msg Message) error {
+	s.logger.WithContext(ctx).WithFields(map[string]interface{}{
+		"event_id":   msg.ID,
+		"event_name": msg.Name,
+		"payload":    string(msg.Payload),
+	}).Info("Event published")
+	return nil
+}
+
+// WebhookSink 以HTTP POST投递消息
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink 创建Webhook投递目标
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &WebhookSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Send 实现Sink接口，以JSON信封形式投递，非2xx响应视为失败
+func (s *WebhookSink) Send(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", msg.ID)
+	req.Header.Set("X-Event-Name", msg.Name)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// MemorySink 内存投递目标，模拟消息代理，便于测试和本地调试
+type MemorySink struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewMemorySink 创建内存投递目标
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+// Send 实现Sink接口
+func (s *MemorySink) Send(_ context.Context, msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+// Messages 返回已投递消息的副本
+func (s *MemorySink) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// multiSink 依次投递到多个目标
+type multiSink []Sink
+
+// MultiSink 组合多个投递目标，按顺序投递，遇到错误即停止以便整体重试
+// 重试时已成功的目标会再次收到消息，下游应按消息ID去重
+func MultiSink(sinks ...Sink) Sink {
+	if len(sinks) == 1 {
+		return sinks[0]
+	}
+	return multiSink(sinks)
+}
+
+// Send 实现Sink接口
+func (m multiSink) Send(ctx context.Context, msg Message) error {
+	for _, sink := range m {
+		if err := sink.Send(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewSink 按配置创建投递目标：log、webhook、memory，none表示不投递外部系统
+func NewSink(cfg config.EventsConfig, logger logger.Logger) (Sink, error) {
+	switch strings.ToLower(cfg.Sink) {
+	case "", "log":
+		return NewLogSink(logger), nil
+	case "webhook":
+		if cfg.WebhookURL == "" {
+			return nil, errors.New("events.webhook_url is required for webhook sink")
+		}
+		return NewWebhookSink(cfg.WebhookURL, cfg.WebhookTimeout), nil
+	case "memory":
+		return NewMemorySink(), nil
+	case "none":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported event sink: %s", cfg.Sink)
+	}
+}
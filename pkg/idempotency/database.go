@@ -0,0 +1,120 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// reserveAttempts 记录在查询前被删除时重新预留的次数
+const reserveAttempts = 3
+
+// DBStore 基于数据库的存储，多实例共享，主键冲突保证同一键只有一个请求获得锁
+type DBStore struct {
+	db *gorm.DB
+
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewDBStore 创建数据库存储
+func NewDBStore(db *gorm.DB) *DBStore {
+	return &DBStore{db: db}
+}
+
+// Migrate 创建幂等键表
+func (s *DBStore) Migrate() error {
+	return s.db.AutoMigrate(&Record{})
+}
+
+// Reserve 实现Store接口
+func (s *DBStore) Reserve(ctx context.Context, rec *Record, lockTTL time.Duration) (*Record, bool, error) {
+	s.sweep(ctx)
+
+	for i := 0; i < reserveAttempts; i++ {
+		now := time.Now()
+		reserved := *rec
+		reserved.Completed = false
+		reserved.Status = 0
+		reserved.Header = nil
+		reserved.Body = nil
+		reserved.ExpiresAt = now.Add(lockTTL)
+		reserved.CreatedAt = now
+
+		// 键不存在时创建，并发创建由主键冲突保证只有一个成功
+		result := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&reserved)
+		if result.Error != nil {
+			return nil, false, result.Error
+		}
+		if result.RowsAffected > 0 {
+			return nil, true, nil
+		}
+
+		// 接管已过期的记录
+		result = s.db.WithContext(ctx).Model(&Record{}).
+			Where("key_hash = ? AND expires_at <= ?", rec.Key, now).
+			Select("fingerprint", "token", "completed", "status", "header", "body", "expires_at", "created_at").
+			Updates(&reserved)
+		if result.Error != nil {
+			return nil, false, result.Error
+		}
+		if result.RowsAffected > 0 {
+			return nil, true, nil
+		}
+
+		var existing Record
+		err := s.db.WithContext(ctx).Where("key_hash = ?", rec.Key).Take(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		return &existing, false, nil
+	}
+	return nil, false, errors.New("failed to reserve idempotency key")
+}
+
+// Complete 实现Store接口
+func (s *DBStore) Complete(ctx context.Context, rec *Record, ttl time.Duration) error {
+	completed := *rec
+	completed.Completed = true
+	completed.ExpiresAt = time.Now().Add(ttl)
+
+	result := s.db.WithContext(ctx).Model(&Record{}).
+		Where("key_hash = ? AND token = ?", rec.Key, rec.Token).
+		Select("completed", "status", "header", "body", "expires_at").
+		Updates(&completed)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Release 实现Store接口
+func (s *DBStore) Release(ctx context.Context, rec *Record) error {
+	return s.db.WithContext(ctx).
+		Where("key_hash = ? AND token = ? AND completed = ?", rec.Key, rec.Token, false).
+		Delete(&Record{}).Error
+}
+
+// sweep 定期删除过期记录，清理失败不影响请求
+func (s *DBStore) sweep(ctx context.Context) {
+	s.mu.Lock()
+	now := time.Now()
+	if now.Sub(s.lastSweep) < sweepInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSweep = now
+	s.mu.Unlock()
+
+	s.db.WithContext(ctx).Where("expires_at <= ?", now).Delete(&Record{})
+}
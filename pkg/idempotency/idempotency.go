@@ -0,0 +1,100 @@
+// Package idempotency 幂等键存储，保存携带Idempotency-Key的请求的响应，重试时重放而不是重复执行
+package idempotency
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+
+	"go-api-scaffold/internal/config"
+)
+
+var (
+	// ErrUnsupportedStore 不支持的存储类型
+	ErrUnsupportedStore = errors.New("unsupported idempotency store")
+	// ErrLockLost 处理超过锁时长，记录已过期或被其他请求接管
+	ErrLockLost = errors.New("idempotency lock lost")
+)
+
+// Record 幂等键记录，处理中时Completed为false，ExpiresAt为锁的到期时间；
+// 处理完成后保存响应，ExpiresAt为响应的过期时间
+type Record struct {
+	Key         string      `json:"key" gorm:"column:key_hash;primaryKey;size:64"`
+	Fingerprint string      `json:"fingerprint" gorm:"size:64;not null"` // 请求体摘要，同一键的请求体必须一致
+	Token       string      `json:"token" gorm:"size:32;not null"`       // 持有者标识，防止锁过期后覆盖他人的记录
+	Completed   bool        `json:"completed" gorm:"not null;default:false"`
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header" gorm:"serializer:json"`
+	Body        []byte      `json:"body"`
+	ExpiresAt   time.Time   `json:"expires_at" gorm:"not null;index"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// TableName 表名
+func (Record) TableName() string {
+	return "idempotency_keys"
+}
+
+// expired 记录是否已过期，过期的处理中记录表示处理方已失败
+func (r *Record) expired(now time.Time) bool {
+	return !r.ExpiresAt.After(now)
+}
+
+// Store 幂等键存储接口
+type Store interface {
+	// Reserve 键不存在或已过期时以rec创建处理中记录，返回true；
+	// 否则返回已有记录，由调用方判断重放、请求体不一致或仍在处理
+	Reserve(ctx context.Context, rec *Record, lockTTL time.Duration) (*Record, bool, error)
+	// Complete 保存处理结果，ttl后过期；记录已不属于rec.Token时返回ErrLockLost
+	Complete(ctx context.Context, rec *Record, ttl time.Duration) error
+	// Release 删除自己的处理中记录，允许以同一键重试
+	Release(ctx context.Context, rec *Record) error
+}
+
+// NewStore 根据配置创建存储，database存储需要db，redis存储需要client
+func NewStore(cfg config.IdempotencyConfig, db *gorm.DB, client *redis.Client) (Store, error) {
+	switch strings.ToLower(cfg.Store) {
+	case "", "memory":
+		return NewMemoryStore(), nil
+	case "database":
+		if db == nil {
+			return nil, fmt.Errorf("database is required for idempotency store %q", cfg.Store)
+		}
+		return NewDBStore(db), nil
+	case "redis":
+		if client == nil {
+			return nil, fmt.Errorf("redis client is required for idempotency store %q", cfg.Store)
+		}
+		return NewRedisStore(client, cfg.KeyPrefix), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedStore, cfg.Store)
+	}
+}
+
+// Key 存储键：幂等键按用户和路由隔离，不同用户或接口可以使用相同的键
+func Key(user, route, key string) string {
+	sum := sha256.Sum256([]byte(user + "\n" + route + "\n" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Fingerprint 请求体摘要
+func Fingerprint(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// NewToken 生成持有者标识
+func NewToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
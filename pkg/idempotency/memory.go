@@ -0,0 +1,83 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval 清理过期记录的最小间隔
+const sweepInterval = time.Minute
+
+// MemoryStore 进程内存储，仅适用于单实例部署
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]*Record
+	lastSweep time.Time
+}
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]*Record)}
+}
+
+// Reserve 实现Store接口
+func (s *MemoryStore) Reserve(_ context.Context, rec *Record, lockTTL time.Duration) (*Record, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+	if existing, ok := s.records[rec.Key]; ok && !existing.expired(now) {
+		clone := *existing
+		return &clone, false, nil
+	}
+
+	reserved := *rec
+	reserved.Completed = false
+	reserved.ExpiresAt = now.Add(lockTTL)
+	reserved.CreatedAt = now
+	s.records[rec.Key] = &reserved
+	return nil, true, nil
+}
+
+// Complete 实现Store接口
+func (s *MemoryStore) Complete(_ context.Context, rec *Record, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.records[rec.Key]
+	if !ok || existing.Token != rec.Token {
+		return ErrLockLost
+	}
+	completed := *rec
+	completed.Completed = true
+	completed.ExpiresAt = time.Now().Add(ttl)
+	completed.CreatedAt = existing.CreatedAt
+	s.records[rec.Key] = &completed
+	return nil
+}
+
+// Release 实现Store接口
+func (s *MemoryStore) Release(_ context.Context, rec *Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.records[rec.Key]; ok && existing.Token == rec.Token && !existing.Completed {
+		delete(s.records, rec.Key)
+	}
+	return nil
+}
+
+// sweep 定期清理过期记录，调用方需持有锁
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, rec := range s.records {
+		if rec.expired(now) {
+			delete(s.records, key)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"go-api-scaffold/internal/config"
+)
+
+func newRecord(key, token string) *Record {
+	return &Record{Key: key, Fingerprint: Fingerprint([]byte("{}")), Token: token}
+}
+
+func TestMemoryStoreReserve(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	if existing, ok, err := store.Reserve(ctx, newRecord("k", "a"), time.Minute); err != nil || !ok || existing != nil {
+		t.Fatalf("first Reserve() = %v, %v, %v, want nil, true, nil", existing, ok, err)
+	}
+
+	// 处理中的键返回已有记录
+	existing, ok, err := store.Reserve(ctx, newRecord("k", "b"), time.Minute)
+	if err != nil || ok {
+		t.Fatalf("second Reserve() = %v, %v, want false, nil", ok, err)
+	}
+	if existing.Token != "a" || existing.Completed {
+		t.Errorf("existing = %+v, want in-progress record owned by a", existing)
+	}
+
+	// 返回的是副本，修改不影响存储
+	existing.Completed = true
+	if again, _, _ := store.Reserve(ctx, newRecord("k", "b"), time.Minute); again.Completed {
+		t.Error("Reserve() returned a shared record, want a copy")
+	}
+
+	// 不同的键互不影响
+	if _, ok, _ := store.Reserve(ctx, newRecord("other", "b"), time.Minute); !ok {
+		t.Error("Reserve(other) = false, want true")
+	}
+}
+
+func TestMemoryStoreLockExpiry(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	if _, ok, _ := store.Reserve(ctx, newRecord("k", "a"), -time.Second); !ok {
+		t.Fatal("first Reserve() = false, want true")
+	}
+	// 锁已过期，视为处理方失败，允许其他请求接管
+	if _, ok, _ := store.Reserve(ctx, newRecord("k", "b"), time.Minute); !ok {
+		t.Fatal("Reserve() after lock expiry = false, want true")
+	}
+	// 原持有者的结果不能覆盖接管者的记录
+	if err := store.Complete(ctx, newRecord("k", "a"), time.Minute); !errors.Is(err, ErrLockLost) {
+		t.Errorf("Complete() by previous owner = %v, want ErrLockLost", err)
+	}
+}
+
+func TestMemoryStoreComplete(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	rec := newRecord("k", "a")
+	if _, ok, _ := store.Reserve(ctx, rec, time.Minute); !ok {
+		t.Fatal("Reserve() = false, want true")
+	}
+	rec.Status = http.StatusCreated
+	rec.Header = http.Header{"Content-Type": {"application/json"}}
+	rec.Body = []byte(`{"code":200}`)
+	if err := store.Complete(ctx, rec, time.Hour); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	existing, ok, err := store.Reserve(ctx, newRecord("k", "b"), time.Minute)
+	if err != nil || ok {
+		t.Fatalf("Reserve() after Complete = %v, %v, want false, nil", ok, err)
+	}
+	if !existing.Completed || existing.Status != http.StatusCreated || string(existing.Body) != `{"code":200}` {
+		t.Errorf("existing = %+v, want completed response", existing)
+	}
+	if !existing.ExpiresAt.After(time.Now().Add(time.Minute)) {
+		t.Errorf("ExpiresAt = %v, want response ttl", existing.ExpiresAt)
+	}
+
+	// 已完成的记录不会被Release删除
+	if err := store.Release(ctx, rec); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, ok, _ := store.Reserve(ctx, newRecord("k", "b"), time.Minute); ok {
+		t.Error("Reserve() after releasing completed record = true, want false")
+	}
+
+	if err := store.Complete(ctx, newRecord("missing", "a"), time.Hour); !errors.Is(err, ErrLockLost) {
+		t.Errorf("Complete(missing) = %v, want ErrLockLost", err)
+	}
+}
+
+func TestMemoryStoreRelease(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	if _, ok, _ := store.Reserve(ctx, newRecord("k", "a"), time.Minute); !ok {
+		t.Fatal("Reserve() = false, want true")
+	}
+
+	// 只能释放自己持有的记录
+	if err := store.Release(ctx, newRecord("k", "b")); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, ok, _ := store.Reserve(ctx, newRecord("k", "b"), time.Minute); ok {
+		t.Fatal("Reserve() after foreign Release = true, want false")
+	}
+
+	if err := store.Release(ctx, newRecord("k", "a")); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, ok, _ := store.Reserve(ctx, newRecord("k", "b"), time.Minute); !ok {
+		t.Error("Reserve() after Release = false, want true")
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	store.records["expired"] = &Record{Key: "expired", ExpiresAt: now.Add(-time.Second)}
+	store.records["live"] = &Record{Key: "live", ExpiresAt: now.Add(time.Minute)}
+
+	store.sweep(now)
+	if _, ok := store.records["expired"]; ok {
+		t.Error("sweep() kept expired record")
+	}
+	if _, ok := store.records["live"]; !ok {
+		t.Error("sweep() removed live record")
+	}
+
+	// 间隔内不重复清理
+	store.records["expired"] = &Record{Key: "expired", ExpiresAt: now.Add(-time.Second)}
+	store.sweep(now.Add(time.Second))
+	if _, ok := store.records["expired"]; !ok {
+		t.Error("sweep() ran again within sweepInterval")
+	}
+}
+
+func TestKey(t *testing.T) {
+	base := Key("user", "POST /api/v1/users", "abc")
+	if base != Key("user", "POST /api/v1/users", "abc") {
+		t.Error("Key() is not deterministic")
+	}
+	for _, other := range []string{
+		Key("other", "POST /api/v1/users", "abc"),
+		Key("user", "POST /api/v1/webhooks", "abc"),
+		Key("user", "POST /api/v1/users", "abd"),
+	} {
+		if other == base {
+			t.Errorf("Key() collision: %s", other)
+		}
+	}
+}
+
+func TestNewStore(t *testing.T) {
+	store, err := NewStore(config.IdempotencyConfig{}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewStore(default) error = %v", err)
+	}
+	if _, ok := store.(*MemoryStore); !ok {
+		t.Errorf("NewStore(default) = %T, want *MemoryStore", store)
+	}
+
+	for _, name := range []string{"database", "redis"} {
+		if _, err := NewStore(config.IdempotencyConfig{Store: name}, nil, nil); err == nil {
+			t.Errorf("NewStore(%s) without backend error = nil, want error", name)
+		}
+	}
+	if _, err := NewStore(config.IdempotencyConfig{Store: "etcd"}, nil, nil); !errors.Is(err, ErrUnsupportedStore) {
+		t.Errorf("NewStore(etcd) error = %v, want ErrUnsupportedStore", err)
+	}
+}
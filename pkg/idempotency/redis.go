@@ -0,0 +1,96 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// reserveScript 键不存在时写入处理中记录，否则返回已有记录
+var reserveScript = redis.NewScript(`
+local existing = redis.call('GET', KEYS[1])
+if existing then
+	return existing
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return false
+`)
+
+// ownedScript 记录仍属于token时写入新值（ARGV[2]为空时删除），返回是否成功
+var ownedScript = redis.NewScript(`
+local existing = redis.call('GET', KEYS[1])
+if not existing or cjson.decode(existing).token ~= ARGV[1] then
+	return 0
+end
+if ARGV[2] == '' then
+	redis.call('DEL', KEYS[1])
+else
+	redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+end
+return 1
+`)
+
+// RedisStore 基于Redis的存储，多实例共享，记录按过期时间自动删除
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisStore 创建Redis存储，所有键自动加上prefix前缀
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Reserve 实现Store接口
+func (s *RedisStore) Reserve(ctx context.Context, rec *Record, lockTTL time.Duration) (*Record, bool, error) {
+	now := time.Now()
+	reserved := *rec
+	reserved.Completed = false
+	reserved.ExpiresAt = now.Add(lockTTL)
+	reserved.CreatedAt = now
+	data, err := json.Marshal(&reserved)
+	if err != nil {
+		return nil, false, err
+	}
+
+	value, err := reserveScript.Run(ctx, s.client, []string{s.prefix + rec.Key}, data, lockTTL.Milliseconds()).Text()
+	if errors.Is(err, redis.Nil) {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var existing Record
+	if err := json.Unmarshal([]byte(value), &existing); err != nil {
+		return nil, false, err
+	}
+	return &existing, false, nil
+}
+
+// Complete 实现Store接口
+func (s *RedisStore) Complete(ctx context.Context, rec *Record, ttl time.Duration) error {
+	completed := *rec
+	completed.Completed = true
+	completed.ExpiresAt = time.Now().Add(ttl)
+	data, err := json.Marshal(&completed)
+	if err != nil {
+		return err
+	}
+
+	ok, err := ownedScript.Run(ctx, s.client, []string{s.prefix + rec.Key}, rec.Token, data, ttl.Milliseconds()).Int()
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return ErrLockLost
+	}
+	return nil
+}
+
+// Release 实现Store接口
+func (s *RedisStore) Release(ctx context.Context, rec *Record) error {
+	return ownedScript.Run(ctx, s.client, []string{s.prefix + rec.Key}, rec.Token, "", 0).Err()
+}
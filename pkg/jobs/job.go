@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+)
+
+// Job 后台任务
+// Run返回错误时按退避策略重试，返回Permanent包装的错误时不再重试
+type Job interface {
+	Name() string
+	Run(ctx context.Context, payload []byte) error
+}
+
+// funcJob 函数形式的任务
+type funcJob struct {
+	name string
+	fn   func(ctx context.Context, payload []byte) error
+}
+
+// Func 将函数包装为任务
+func Func(name string, fn func(ctx context.Context, payload []byte) error) Job {
+	return &funcJob{name: name, fn: fn}
+}
+
+// Name 实现Job接口
+func (j *funcJob) Name() string { return j.name }
+
+// Run 实现Job接口
+func (j *funcJob) Run(ctx context.Context, payload []byte) error { return j.fn(ctx, payload) }
+
+// permanentError 不可重试的错误
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 标记错误不可重试，任务直接进入失败状态
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent 判断错误是否不可重试
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
@@ -0,0 +1,226 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"go-api-scaffold/pkg/strutil"
+)
+
+// 任务状态
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// defaultMaxAttempts 未指定时的最大执行次数
+const defaultMaxAttempts = 5
+
+// ErrNoJobName 任务名称为空
+var ErrNoJobName = errors.New("job name is required")
+
+// Record 任务记录
+type Record struct {
+	ID          uint       `json:"id" gorm:"primarykey"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	Name        string     `json:"name" gorm:"size:128;not null;index:idx_jobs_due"`
+	Payload     string     `json:"payload" gorm:"type:text"`
+	Status      string     `json:"status" gorm:"size:16;not null;index:idx_jobs_due"`
+	RunAt       time.Time  `json:"run_at" gorm:"index:idx_jobs_due"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	LockedBy    string     `json:"locked_by" gorm:"size:64"`
+	LockedUntil *time.Time `json:"locked_until"`
+	LastError   string     `json:"last_error" gorm:"size:1024"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+// TableName 表名
+func (Record) TableName() string {
+	return "jobs"
+}
+
+// EnqueueOption 入队选项
+type EnqueueOption func(*Record)
+
+// Delay 延迟d后执行
+func Delay(d time.Duration) EnqueueOption {
+	return func(r *Record) { r.RunAt = time.Now().Add(d) }
+}
+
+// At 在指定时间执行
+func At(t time.Time) EnqueueOption {
+	return func(r *Record) { r.RunAt = t }
+}
+
+// MaxAttempts 最大执行次数（含首次）
+func MaxAttempts(n int) EnqueueOption {
+	return func(r *Record) {
+		if n > 0 {
+			r.MaxAttempts = n
+		}
+	}
+}
+
+// ConnFunc 返回ctx应使用的数据库连接，ctx处于调用方开启的事务中时返回事务连接
+type ConnFunc func(ctx context.Context, db *gorm.DB) *gorm.DB
+
+// Queue 基于数据库的任务队列，复用现有GORM连接
+type Queue struct {
+	db   *gorm.DB
+	conn ConnFunc
+}
+
+// NewQueue 创建任务队列，conn用于在调用方的事务中入队，为nil时总是使用db
+func NewQueue(db *gorm.DB, conn ConnFunc) *Queue {
+	if conn == nil {
+		conn = func(ctx context.Context, db *gorm.DB) *gorm.DB { return db.WithContext(ctx) }
+	}
+	return &Queue{db: db, conn: conn}
+}
+
+// Migrate 创建任务表
+func (q *Queue) Migrate() error {
+	return q.db.AutoMigrate(&Record{})
+}
+
+// Enqueue 任务入队，payload按JSON编码（[]byte和json.RawMessage原样保存）
+// conn返回事务连接时随该事务写入，任务在事务提交后才可见，回滚时一并撤销
+func (q *Queue) Enqueue(ctx context.Context, name string, payload interface{}, opts ...EnqueueOption) (*Record, error) {
+	if name == "" {
+		return nil, ErrNoJobName
+	}
+
+	data, err := encodePayload(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &Record{
+		Name:        name,
+		Payload:     string(data),
+		Status:      StatusPending,
+		RunAt:       time.Now(),
+		MaxAttempts: defaultMaxAttempts,
+	}
+	for _, opt := range opts {
+		opt(record)
+	}
+
+	if err := q.conn(ctx, q.db).Create(record).Error; err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Get 获取任务记录
+func (q *Queue) Get(ctx context.Context, id uint) (*Record, error) {
+	var record Record
+	if err := q.db.WithContext(ctx).First(&record, id).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// claim 领取到期任务，租约过期的运行中任务（执行进程已退出）也会被重新领取
+func (q *Queue) claim(ctx context.Context, names []string, limit int, owner string, lease time.Duration) ([]*Record, error) {
+	if len(names) == 0 || limit <= 0 {
+		return nil, nil
+	}
+
+	var records []*Record
+	err := q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("name IN ?", names).
+			Where("(status = ? AND run_at <= ?) OR (status = ? AND locked_until < ?)",
+				StatusPending, now, StatusRunning, now).
+			Order("run_at, id").
+			Limit(limit).
+			Find(&records).Error
+		if err != nil || len(records) == 0 {
+			return err
+		}
+
+		lockedUntil := now.Add(lease)
+		ids := make([]uint, len(records))
+		for i, r := range records {
+			ids[i] = r.ID
+			r.Status = StatusRunning
+			r.Attempts++
+			r.LockedBy = owner
+			r.LockedUntil = &lockedUntil
+		}
+		return tx.Model(&Record{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":       StatusRunning,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"locked_by":    owner,
+			"locked_until": lockedUntil,
+		}).Error
+	})
+	return records, err
+}
+
+// complete 标记任务成功
+func (q *Queue) complete(ctx context.Context, r *Record) error {
+	now := time.Now()
+	return q.finish(ctx, r, map[string]interface{}{
+		"status":       StatusSucceeded,
+		"finished_at":  now,
+		"locked_by":    "",
+		"locked_until": nil,
+		"last_error":   "",
+	})
+}
+
+// retry 任务失败后重新排队
+func (q *Queue) retry(ctx context.Context, r *Record, runAt time.Time, cause error) error {
+	return q.finish(ctx, r, map[string]interface{}{
+		"status":       StatusPending,
+		"run_at":       runAt,
+		"locked_by":    "",
+		"locked_until": nil,
+		"last_error":   strutil.Truncate(cause.Error(), 1024),
+	})
+}
+
+// fail 任务最终失败
+func (q *Queue) fail(ctx context.Context, r *Record, cause error) error {
+	now := time.Now()
+	return q.finish(ctx, r, map[string]interface{}{
+		"status":       StatusFailed,
+		"finished_at":  now,
+		"locked_by":    "",
+		"locked_until": nil,
+		"last_error":   strutil.Truncate(cause.Error(), 1024),
+	})
+}
+
+// finish 更新任务状态，仅在仍持有租约时生效，避免覆盖已被其他进程重新领取的任务
+func (q *Queue) finish(ctx context.Context, r *Record, updates map[string]interface{}) error {
+	return q.db.WithContext(ctx).Model(&Record{}).
+		Where("id = ? AND status = ? AND locked_by = ?", r.ID, StatusRunning, r.LockedBy).
+		Updates(updates).Error
+}
+
+// encodePayload 编码任务参数
+func encodePayload(payload interface{}) ([]byte, error) {
+	switch p := payload.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return p, nil
+	case json.RawMessage:
+		return p, nil
+	default:
+		return json.Marshal(p)
+	}
+}
@@ -0,0 +1,174 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// newTestQueue 基于临时SQLite数据库的任务队列，conn为nil时使用默认连接
+func newTestQueue(t *testing.T, conn ConnFunc) *Queue {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "jobs.db")), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// SQLite不支持并发写入，串行使用连接
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+
+	q := NewQueue(db, conn)
+	if err := q.Migrate(); err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+func TestEnqueue(t *testing.T) {
+	q := newTestQueue(t, nil)
+	ctx := context.Background()
+
+	if _, err := q.Enqueue(ctx, "", nil); !errors.Is(err, ErrNoJobName) {
+		t.Errorf("Enqueue(\"\") error = %v, want ErrNoJobName", err)
+	}
+
+	before := time.Now()
+	record, err := q.Enqueue(ctx, "send", map[string]int{"id": 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored, err := q.Get(ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != StatusPending || stored.MaxAttempts != defaultMaxAttempts || stored.Payload != `{"id":1}` {
+		t.Errorf("stored = %+v, want pending job with default max attempts and JSON payload", stored)
+	}
+	if stored.RunAt.Before(before.Add(-time.Second)) || stored.RunAt.After(time.Now()) {
+		t.Errorf("RunAt = %s, want now", stored.RunAt)
+	}
+
+	raw, err := q.Enqueue(ctx, "send", []byte("raw"), MaxAttempts(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if raw.Payload != "raw" || raw.MaxAttempts != 2 {
+		t.Errorf("raw = %+v, want payload stored as is and 2 max attempts", raw)
+	}
+}
+
+func TestClaimDueJobs(t *testing.T) {
+	q := newTestQueue(t, nil)
+	ctx := context.Background()
+
+	due, _ := q.Enqueue(ctx, "send", nil)
+	delayed, _ := q.Enqueue(ctx, "send", nil, Delay(time.Hour))
+	at, _ := q.Enqueue(ctx, "send", nil, At(time.Now().Add(-time.Minute)))
+	other, _ := q.Enqueue(ctx, "other", nil)
+
+	records, err := q.claim(ctx, []string{"send"}, 10, "worker-a", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 按run_at排序，At指定的过去时间排在前面，延迟任务和未请求的任务不会被领取
+	if len(records) != 2 || records[0].ID != at.ID || records[1].ID != due.ID {
+		t.Fatalf("claimed %+v, want jobs %d and %d", records, at.ID, due.ID)
+	}
+	for _, r := range records {
+		stored, _ := q.Get(ctx, r.ID)
+		if stored.Status != StatusRunning || stored.Attempts != 1 || stored.LockedBy != "worker-a" || stored.LockedUntil == nil {
+			t.Errorf("claimed job = %+v, want running and locked by worker-a", stored)
+		}
+	}
+
+	// 已领取的任务不会被重复领取
+	if again, _ := q.claim(ctx, []string{"send"}, 10, "worker-b", time.Minute); len(again) != 0 {
+		t.Errorf("claimed %d jobs again, want 0", len(again))
+	}
+	for _, id := range []uint{delayed.ID, other.ID} {
+		if stored, _ := q.Get(ctx, id); stored.Status != StatusPending {
+			t.Errorf("job %d status = %s, want pending", id, stored.Status)
+		}
+	}
+
+	// 超过limit的任务留给下次领取
+	if limited, _ := q.claim(ctx, []string{"other"}, 0, "worker-b", time.Minute); len(limited) != 0 {
+		t.Errorf("claim with limit 0 returned %d jobs", len(limited))
+	}
+}
+
+// TestClaimReclaimsExpiredLease 执行进程退出后，租约到期的任务被其他进程重新领取，原进程的结果不会覆盖
+func TestClaimReclaimsExpiredLease(t *testing.T) {
+	q := newTestQueue(t, nil)
+	ctx := context.Background()
+	record, _ := q.Enqueue(ctx, "send", nil)
+
+	first, err := q.claim(ctx, []string{"send"}, 1, "worker-a", -time.Second)
+	if err != nil || len(first) != 1 {
+		t.Fatalf("first claim = %v, %v", first, err)
+	}
+	second, err := q.claim(ctx, []string{"send"}, 1, "worker-b", time.Minute)
+	if err != nil || len(second) != 1 {
+		t.Fatalf("reclaim = %v, %v, want the expired job", second, err)
+	}
+	if second[0].Attempts != 2 {
+		t.Errorf("attempts = %d, want 2", second[0].Attempts)
+	}
+
+	// 原持有者的完成状态被忽略
+	if err := q.complete(ctx, first[0]); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := q.Get(ctx, record.ID); stored.Status != StatusRunning || stored.LockedBy != "worker-b" {
+		t.Errorf("stored = %+v, want still running under worker-b", stored)
+	}
+	if err := q.complete(ctx, second[0]); err != nil {
+		t.Fatal(err)
+	}
+	if stored, _ := q.Get(ctx, record.ID); stored.Status != StatusSucceeded || stored.FinishedAt == nil {
+		t.Errorf("stored = %+v, want succeeded", stored)
+	}
+}
+
+// txKey 测试用事务上下文键
+type txKey struct{}
+
+// TestEnqueueInTransaction 入队使用ConnFunc返回的事务连接，事务回滚时任务一并撤销
+func TestEnqueueInTransaction(t *testing.T) {
+	q := newTestQueue(t, func(ctx context.Context, db *gorm.DB) *gorm.DB {
+		if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+			return tx.WithContext(ctx)
+		}
+		return db.WithContext(ctx)
+	})
+	ctx := context.Background()
+
+	rollback := errors.New("rollback")
+	err := q.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := q.Enqueue(context.WithValue(ctx, txKey{}, tx), "send", nil); err != nil {
+			return err
+		}
+		return rollback
+	})
+	if !errors.Is(err, rollback) {
+		t.Fatal(err)
+	}
+
+	var count int64
+	if err := q.db.Model(&Record{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("jobs after rollback = %d, want 0", count)
+	}
+}
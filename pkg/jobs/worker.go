@@ -0,0 +1,278 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/retry"
+)
+
+// Options 工作池选项
+type Options struct {
+	Concurrency    int           // 同时执行的任务数上限
+	PollInterval   time.Duration // 轮询周期
+	Timeout        time.Duration // 单个任务执行超时，同时作为租约时长，超时未完成的任务可被重新领取
+	InitialBackoff time.Duration // 首次重试间隔，之后指数增长
+	MaxBackoff     time.Duration
+}
+
+// RegisterOption 任务注册选项
+type RegisterOption func(*registration)
+
+// Concurrency 限制同一任务的并发数
+func Concurrency(n int) RegisterOption {
+	return func(r *registration) { r.limit = n }
+}
+
+// registration 已注册的任务
+type registration struct {
+	job     Job
+	limit   int // 0表示仅受工作池并发数限制
+	running int
+}
+
+// Worker 任务工作池
+// 轮询领取到期任务并在并发限制内执行，失败按指数退避重试，关闭时停止领取并等待执行中的任务完成
+type Worker struct {
+	queue  *Queue
+	opts   Options
+	owner  string
+	logger logger.Logger
+
+	mu      sync.Mutex
+	jobs    map[string]*registration
+	running int
+
+	ctx    context.Context // 任务执行上下文，强制关闭时取消
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	wake    chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	started bool
+}
+
+// NewWorker 创建任务工作池，注册任务后调用Start开始执行
+func NewWorker(queue *Queue, opts Options, logger logger.Logger) *Worker {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Minute
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = 10 * time.Second
+	}
+	if opts.MaxBackoff < opts.InitialBackoff {
+		opts.MaxBackoff = opts.InitialBackoff
+	}
+
+	hostname, _ := os.Hostname()
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Worker{
+		queue:  queue,
+		opts:   opts,
+		owner:  fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		logger: logger.WithField("component", "jobs"),
+		jobs:   make(map[string]*registration),
+		ctx:    ctx,
+		cancel: cancel,
+		wake:   make(chan struct{}, 1),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Register 注册任务，需在Start之前调用
+func (w *Worker) Register(job Job, opts ...RegisterOption) {
+	reg := &registration{job: job}
+	for _, opt := range opts {
+		opt(reg)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.jobs[job.Name()] = reg
+}
+
+// Start 开始轮询执行任务
+func (w *Worker) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started {
+		return
+	}
+	w.started = true
+	go w.run()
+}
+
+// Notify 唤醒工作池立即领取任务，不阻塞，适合入队后调用
+func (w *Worker) Notify() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Shutdown 停止领取新任务并等待执行中的任务完成
+// ctx到期时取消执行中任务的上下文并返回ctx.Err()，未完成的任务在租约到期后会被重新领取
+func (w *Worker) Shutdown(ctx context.Context) error {
+	w.mu.Lock()
+	started := w.started
+	w.mu.Unlock()
+	if started {
+		close(w.stop)
+		<-w.done
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		w.cancel()
+		return nil
+	case <-ctx.Done():
+		w.cancel()
+		return ctx.Err()
+	}
+}
+
+// run 轮询主循环
+func (w *Worker) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		case <-w.wake:
+		}
+		w.poll()
+	}
+}
+
+// poll 在空闲并发额度内领取任务，受限任务单独领取以遵守各自的并发上限
+func (w *Worker) poll() {
+	free, shared, limited := w.capacity()
+	if free <= 0 {
+		return
+	}
+
+	if len(shared) > 0 {
+		free -= w.claim(shared, free)
+	}
+	for name, remaining := range limited {
+		if free <= 0 {
+			return
+		}
+		free -= w.claim([]string{name}, min(free, remaining))
+	}
+}
+
+// capacity 计算空闲额度：不限并发的任务名列表，以及受限任务名的剩余额度
+func (w *Worker) capacity() (free int, shared []string, limited map[string]int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	limited = make(map[string]int)
+	for name, reg := range w.jobs {
+		switch {
+		case reg.limit <= 0:
+			shared = append(shared, name)
+		case reg.running < reg.limit:
+			limited[name] = reg.limit - reg.running
+		}
+	}
+	return w.opts.Concurrency - w.running, shared, limited
+}
+
+// claim 领取并执行任务，返回领取数量
+func (w *Worker) claim(names []string, limit int) int {
+	records, err := w.queue.claim(w.ctx, names, limit, w.owner, w.opts.Timeout)
+	if err != nil {
+		w.logger.WithError(err).Error("Failed to claim jobs")
+		return 0
+	}
+
+	for _, record := range records {
+		w.mu.Lock()
+		reg := w.jobs[record.Name]
+		reg.running++
+		w.running++
+		w.mu.Unlock()
+
+		w.wg.Add(1)
+		go w.execute(reg, record)
+	}
+	return len(records)
+}
+
+// execute 执行任务并更新状态
+func (w *Worker) execute(reg *registration, record *Record) {
+	defer func() {
+		w.mu.Lock()
+		reg.running--
+		w.running--
+		w.mu.Unlock()
+		w.wg.Done()
+		// 释放额度后立即尝试领取下一个任务
+		w.Notify()
+	}()
+
+	log := w.logger.WithFields(map[string]interface{}{
+		"job_id":   record.ID,
+		"job_name": record.Name,
+		"attempts": record.Attempts,
+	})
+
+	start := time.Now()
+	err := w.runJob(reg.job, record)
+
+	// 状态更新不受关闭取消影响
+	ctx := context.WithoutCancel(w.ctx)
+	var updateErr error
+	switch {
+	case err == nil:
+		updateErr = w.queue.complete(ctx, record)
+		log.WithField("duration", time.Since(start).String()).Debug("Job succeeded")
+	case IsPermanent(err) || record.Attempts >= record.MaxAttempts:
+		updateErr = w.queue.fail(ctx, record, err)
+		log.WithError(err).Error("Job failed permanently")
+	default:
+		delay := retry.Backoff(w.opts.InitialBackoff, w.opts.MaxBackoff, record.Attempts)
+		updateErr = w.queue.retry(ctx, record, time.Now().Add(delay), err)
+		log.WithError(err).WithField("retry_in", delay.String()).Warn("Job failed, will retry")
+	}
+	if updateErr != nil {
+		log.WithError(updateErr).Error("Failed to update job status")
+	}
+}
+
+// runJob 执行任务，捕获panic
+func (w *Worker) runJob(job Job, record *Record) (err error) {
+	ctx, cancel := context.WithTimeout(w.ctx, w.opts.Timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panic: %v", r)
+		}
+	}()
+	return job.Run(ctx, []byte(record.Payload))
+}
@@ -0,0 +1,273 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/pkg/logger"
+)
+
+func newTestWorker(q *Queue, opts Options) *Worker {
+	if opts.PollInterval == 0 {
+		opts.PollInterval = 10 * time.Millisecond
+	}
+	log := logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"})
+	return NewWorker(q, opts, log)
+}
+
+// waitStatus 等待任务进入指定状态
+func waitStatus(t *testing.T, q *Queue, id uint, status string) *Record {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		record, err := q.Get(context.Background(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.Status == status {
+			return record
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("job %d status = %s, want %s", id, record.Status, status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func shutdown(t *testing.T, w *Worker) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := w.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+}
+
+func TestWorkerRunsJob(t *testing.T) {
+	q := newTestQueue(t, nil)
+	w := newTestWorker(q, Options{})
+
+	var payload atomic.Value
+	w.Register(Func("send", func(_ context.Context, p []byte) error {
+		payload.Store(string(p))
+		return nil
+	}))
+	w.Start()
+	defer shutdown(t, w)
+
+	record, err := q.Enqueue(context.Background(), "send", map[string]string{"to": "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Notify()
+
+	done := waitStatus(t, q, record.ID, StatusSucceeded)
+	if done.Attempts != 1 || done.LockedBy != "" || done.FinishedAt == nil {
+		t.Errorf("record = %+v, want finished after one attempt and unlocked", done)
+	}
+	if got := payload.Load(); got != `{"to":"alice"}` {
+		t.Errorf("payload = %v", got)
+	}
+}
+
+func TestWorkerRetriesWithBackoff(t *testing.T) {
+	q := newTestQueue(t, nil)
+	w := newTestWorker(q, Options{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 20 * time.Millisecond})
+
+	var calls atomic.Int32
+	w.Register(Func("flaky", func(context.Context, []byte) error {
+		if calls.Add(1) < 3 {
+			return errors.New("temporary")
+		}
+		return nil
+	}))
+	w.Start()
+	defer shutdown(t, w)
+
+	record, _ := q.Enqueue(context.Background(), "flaky", nil)
+	done := waitStatus(t, q, record.ID, StatusSucceeded)
+	if done.Attempts != 3 || done.LastError != "" {
+		t.Errorf("record = %+v, want succeeded on the third attempt with last_error cleared", done)
+	}
+}
+
+func TestWorkerSchedulesRetry(t *testing.T) {
+	q := newTestQueue(t, nil)
+	w := newTestWorker(q, Options{InitialBackoff: time.Hour})
+
+	w.Register(Func("flaky", func(context.Context, []byte) error { return errors.New("temporary") }))
+	w.Start()
+
+	record, _ := q.Enqueue(context.Background(), "flaky", nil)
+	w.Notify()
+
+	// 首次失败后按InitialBackoff延后重新排队
+	deadline := time.Now().Add(5 * time.Second)
+	var stored *Record
+	for {
+		stored, _ = q.Get(context.Background(), record.ID)
+		if stored.Attempts == 1 && stored.Status == StatusPending {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("record = %+v, want pending after first failure", stored)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	shutdown(t, w)
+
+	if stored.LastError != "temporary" {
+		t.Errorf("last_error = %q, want temporary", stored.LastError)
+	}
+	if wait := time.Until(stored.RunAt); wait < 50*time.Minute {
+		t.Errorf("retry in %s, want about InitialBackoff", wait)
+	}
+}
+
+func TestWorkerFailsPermanently(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		opts []EnqueueOption
+		want int
+	}{
+		{"permanent error", Permanent(errors.New("bad payload")), nil, 1},
+		{"max attempts", errors.New("temporary"), []EnqueueOption{MaxAttempts(2)}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newTestQueue(t, nil)
+			w := newTestWorker(q, Options{InitialBackoff: time.Millisecond})
+			w.Register(Func("job", func(context.Context, []byte) error { return tt.err }))
+			w.Start()
+			defer shutdown(t, w)
+
+			record, _ := q.Enqueue(context.Background(), "job", nil, tt.opts...)
+			failed := waitStatus(t, q, record.ID, StatusFailed)
+			if failed.Attempts != tt.want || failed.LastError == "" {
+				t.Errorf("record = %+v, want failed after %d attempts with last_error", failed, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkerRecoversPanic(t *testing.T) {
+	q := newTestQueue(t, nil)
+	w := newTestWorker(q, Options{})
+	w.Register(Func("panics", func(context.Context, []byte) error { panic("boom") }))
+	w.Start()
+	defer shutdown(t, w)
+
+	record, _ := q.Enqueue(context.Background(), "panics", nil, MaxAttempts(1))
+	failed := waitStatus(t, q, record.ID, StatusFailed)
+	if failed.LastError != "job panic: boom" {
+		t.Errorf("last_error = %q", failed.LastError)
+	}
+}
+
+func TestWorkerConcurrencyLimit(t *testing.T) {
+	q := newTestQueue(t, nil)
+	w := newTestWorker(q, Options{Concurrency: 4})
+
+	var mu sync.Mutex
+	running := map[string]int{}
+	peak := map[string]int{}
+	track := func(name string) Job {
+		return Func(name, func(context.Context, []byte) error {
+			mu.Lock()
+			running[name]++
+			peak[name] = max(peak[name], running[name])
+			mu.Unlock()
+
+			time.Sleep(30 * time.Millisecond)
+
+			mu.Lock()
+			running[name]--
+			mu.Unlock()
+			return nil
+		})
+	}
+	w.Register(track("limited"), Concurrency(1))
+	w.Register(track("shared"))
+
+	var ids []uint
+	for i := 0; i < 3; i++ {
+		for _, name := range []string{"limited", "shared"} {
+			record, err := q.Enqueue(context.Background(), name, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, record.ID)
+		}
+	}
+	w.Start()
+	defer shutdown(t, w)
+
+	for _, id := range ids {
+		waitStatus(t, q, id, StatusSucceeded)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if peak["limited"] != 1 {
+		t.Errorf("limited job peak concurrency = %d, want 1", peak["limited"])
+	}
+	if peak["shared"] < 2 {
+		t.Errorf("shared job peak concurrency = %d, want jobs to run in parallel", peak["shared"])
+	}
+}
+
+func TestWorkerTimeout(t *testing.T) {
+	q := newTestQueue(t, nil)
+	w := newTestWorker(q, Options{Timeout: 20 * time.Millisecond})
+	w.Register(Func("slow", func(ctx context.Context, _ []byte) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	w.Start()
+	defer shutdown(t, w)
+
+	record, _ := q.Enqueue(context.Background(), "slow", nil, MaxAttempts(1))
+	failed := waitStatus(t, q, record.ID, StatusFailed)
+	if failed.LastError != context.DeadlineExceeded.Error() {
+		t.Errorf("last_error = %q, want deadline exceeded", failed.LastError)
+	}
+}
+
+// TestWorkerShutdownDeadline 关闭超时时返回ctx错误并取消执行中任务的上下文
+func TestWorkerShutdownDeadline(t *testing.T) {
+	q := newTestQueue(t, nil)
+	w := newTestWorker(q, Options{})
+
+	started := make(chan struct{})
+	canceled := make(chan struct{})
+	w.Register(Func("slow", func(ctx context.Context, _ []byte) error {
+		close(started)
+		<-ctx.Done()
+		close(canceled)
+		return ctx.Err()
+	}))
+	w.Start()
+
+	if _, err := q.Enqueue(context.Background(), "slow", nil); err != nil {
+		t.Fatal(err)
+	}
+	w.Notify()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := w.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want context.DeadlineExceeded", err)
+	}
+	select {
+	case <-canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("in-flight job was not canceled")
+	}
+}
@@ -0,0 +1,164 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
+	"time"
+
+	"go-api-scaffold/pkg/logger"
+)
+
+// Phase 关闭阶段，按数值从小到大依次执行
+type Phase int
+
+// 关闭阶段
+const (
+	PhaseReadiness Phase = iota // 就绪检查置为失败，负载均衡停止分发新流量
+	PhaseServer                 // 停止接收连接并等待处理中的请求完成
+	PhaseWorkers                // 停止后台任务、定时任务、事件投递
+	PhaseFlush                  // 刷新追踪、指标等缓冲数据
+	PhaseResources              // 关闭数据库、Redis等连接
+)
+
+// String 阶段名称
+func (p Phase) String() string {
+	switch p {
+	case PhaseReadiness:
+		return "readiness"
+	case PhaseServer:
+		return "server"
+	case PhaseWorkers:
+		return "workers"
+	case PhaseFlush:
+		return "flush"
+	case PhaseResources:
+		return "resources"
+	default:
+		return fmt.Sprintf("phase(%d)", int(p))
+	}
+}
+
+// HookFunc 关闭钩子，ctx在总超时到期时取消
+type HookFunc func(ctx context.Context) error
+
+// hook 已注册的关闭钩子
+type hook struct {
+	phase Phase
+	name  string
+	seq   int
+	fn    HookFunc
+}
+
+// Options 生命周期选项
+type Options struct {
+	// Timeout 关闭钩子的总超时，不含PreStopDelay
+	Timeout time.Duration
+	// PreStopDelay 就绪检查置为失败后、停止接收连接前的等待时间，
+	// 使Kubernetes有时间将Pod从Endpoints中摘除，terminationGracePeriodSeconds应大于PreStopDelay+Timeout
+	PreStopDelay time.Duration
+}
+
+// Manager 生命周期管理器
+// 按阶段顺序执行关闭钩子，同一阶段内按注册的逆序执行（与defer一致，后创建的先关闭）
+// 关闭过程会持续写日志，日志输出应在Shutdown返回后由调用方关闭，不要注册为钩子
+type Manager struct {
+	opts   Options
+	logger logger.Logger
+
+	mu    sync.Mutex
+	hooks []hook
+	once  sync.Once
+	err   error
+}
+
+// New 创建生命周期管理器
+func New(opts Options, logger logger.Logger) *Manager {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 30 * time.Second
+	}
+	return &Manager{
+		opts:   opts,
+		logger: logger.WithField("component", "lifecycle"),
+	}
+}
+
+// OnShutdown 注册关闭钩子
+func (m *Manager) OnShutdown(phase Phase, name string, fn HookFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{phase: phase, name: name, seq: len(m.hooks), fn: fn})
+}
+
+// Wait 阻塞直到收到SIGINT或SIGTERM，之后再次收到信号时立即退出进程
+func (m *Manager) Wait() os.Signal {
+	quit := make(chan os.Signal, 2)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-quit
+
+	go func() {
+		<-quit
+		m.logger.Warn("Received second signal, forcing exit")
+		os.Exit(1)
+	}()
+	return sig
+}
+
+// Shutdown 执行关闭流程，返回所有钩子错误的合并结果，重复调用只执行一次
+func (m *Manager) Shutdown() error {
+	m.once.Do(func() {
+		m.err = m.shutdown()
+	})
+	return m.err
+}
+
+// shutdown 依次执行各阶段钩子，单个钩子失败不影响后续钩子
+func (m *Manager) shutdown() error {
+	m.mu.Lock()
+	hooks := append([]hook(nil), m.hooks...)
+	m.mu.Unlock()
+
+	sort.SliceStable(hooks, func(i, j int) bool {
+		if hooks[i].phase != hooks[j].phase {
+			return hooks[i].phase < hooks[j].phase
+		}
+		return hooks[i].seq > hooks[j].seq
+	})
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	var errs []error
+	start := time.Now()
+	for _, h := range hooks {
+		// 就绪检查置为失败后等待流量摘除，再开始计算关闭超时
+		if ctx == nil && h.phase > PhaseReadiness {
+			if m.opts.PreStopDelay > 0 {
+				m.logger.Infof("Waiting %s for load balancers to drain traffic", m.opts.PreStopDelay)
+				time.Sleep(m.opts.PreStopDelay)
+			}
+			ctx, cancel = context.WithTimeout(context.Background(), m.opts.Timeout)
+			defer cancel()
+		}
+		hookCtx := ctx
+		if hookCtx == nil {
+			hookCtx = context.Background()
+		}
+
+		log := m.logger.WithFields(map[string]interface{}{"phase": h.phase.String(), "hook": h.name})
+		hookStart := time.Now()
+		if err := h.fn(hookCtx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+			log.WithError(err).Error("Shutdown hook failed")
+			continue
+		}
+		log.WithField("duration", time.Since(hookStart).String()).Debug("Shutdown hook completed")
+	}
+
+	m.logger.WithField("duration", time.Since(start).String()).Info("Shutdown completed")
+	return errors.Join(errs...)
+}
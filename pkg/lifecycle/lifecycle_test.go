@@ -0,0 +1,176 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/pkg/logger"
+)
+
+func newTestManager(opts Options) *Manager {
+	return New(opts, logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"}))
+}
+
+func TestShutdownOrder(t *testing.T) {
+	m := newTestManager(Options{})
+
+	var order []string
+	record := func(name string) HookFunc {
+		return func(context.Context) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+	// 注册顺序与阶段无关
+	m.OnShutdown(PhaseResources, "database", record("database"))
+	m.OnShutdown(PhaseFlush, "tracing", record("tracing"))
+	m.OnShutdown(PhaseResources, "redis", record("redis"))
+	m.OnShutdown(PhaseWorkers, "event_bus", record("event_bus"))
+	m.OnShutdown(PhaseWorkers, "jobs", record("jobs"))
+	m.OnShutdown(PhaseReadiness, "readiness", record("readiness"))
+	m.OnShutdown(PhaseServer, "http_server", record("http_server"))
+
+	if err := m.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	// 按阶段执行，同一阶段内后注册的先执行
+	want := []string{"readiness", "http_server", "jobs", "event_bus", "tracing", "redis", "database"}
+	if !slices.Equal(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+}
+
+func TestShutdownErrors(t *testing.T) {
+	m := newTestManager(Options{})
+	failed := errors.New("close failed")
+
+	var ran []string
+	m.OnShutdown(PhaseWorkers, "jobs", func(context.Context) error {
+		ran = append(ran, "jobs")
+		return failed
+	})
+	m.OnShutdown(PhaseResources, "database", func(context.Context) error {
+		ran = append(ran, "database")
+		return nil
+	})
+
+	err := m.Shutdown()
+	if !errors.Is(err, failed) || err.Error() != "jobs: close failed" {
+		t.Errorf("Shutdown() error = %v, want jobs: close failed", err)
+	}
+	// 单个钩子失败不影响后续钩子
+	if !slices.Equal(ran, []string{"jobs", "database"}) {
+		t.Errorf("ran = %v, want all hooks", ran)
+	}
+}
+
+func TestShutdownPreStopDelay(t *testing.T) {
+	const delay = 50 * time.Millisecond
+	m := newTestManager(Options{PreStopDelay: delay, Timeout: time.Second})
+
+	var readinessAt, serverAt time.Time
+	m.OnShutdown(PhaseReadiness, "readiness", func(context.Context) error {
+		readinessAt = time.Now()
+		return nil
+	})
+	m.OnShutdown(PhaseServer, "http_server", func(ctx context.Context) error {
+		serverAt = time.Now()
+		// 关闭超时从等待结束后开始计算
+		if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) < 900*time.Millisecond {
+			t.Errorf("deadline = %v, %v, want the full timeout after the delay", deadline, ok)
+		}
+		return nil
+	})
+
+	if err := m.Shutdown(); err != nil {
+		t.Fatal(err)
+	}
+	if gap := serverAt.Sub(readinessAt); gap < delay {
+		t.Errorf("server stopped %s after readiness, want at least %s", gap, delay)
+	}
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	m := newTestManager(Options{Timeout: 20 * time.Millisecond})
+
+	var readinessDeadline bool
+	var laterErr error
+	m.OnShutdown(PhaseReadiness, "readiness", func(ctx context.Context) error {
+		_, readinessDeadline = ctx.Deadline()
+		return nil
+	})
+	m.OnShutdown(PhaseServer, "http_server", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	m.OnShutdown(PhaseResources, "database", func(ctx context.Context) error {
+		laterErr = ctx.Err()
+		return nil
+	})
+
+	err := m.Shutdown()
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown() error = %v, want context.DeadlineExceeded", err)
+	}
+	if readinessDeadline {
+		t.Error("readiness hook got a deadline, want it to run before the timeout starts")
+	}
+	// 超时在各阶段共享，后续钩子收到已取消的ctx
+	if !errors.Is(laterErr, context.DeadlineExceeded) {
+		t.Errorf("later hook ctx.Err() = %v, want context.DeadlineExceeded", laterErr)
+	}
+}
+
+func TestShutdownOnce(t *testing.T) {
+	m := newTestManager(Options{})
+	failed := errors.New("close failed")
+
+	var calls atomic.Int32
+	m.OnShutdown(PhaseResources, "database", func(context.Context) error {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return failed
+	})
+
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = m.Shutdown()
+		}()
+	}
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("hook ran %d times, want 1", calls.Load())
+	}
+	for i, err := range errs {
+		if !errors.Is(err, failed) {
+			t.Errorf("Shutdown() call %d error = %v, want the first result", i, err)
+		}
+	}
+}
+
+func TestPhaseString(t *testing.T) {
+	tests := map[Phase]string{
+		PhaseReadiness: "readiness",
+		PhaseServer:    "server",
+		PhaseWorkers:   "workers",
+		PhaseFlush:     "flush",
+		PhaseResources: "resources",
+		Phase(9):       "phase(9)",
+	}
+	for phase, want := range tests {
+		if got := phase.String(); got != want {
+			t.Errorf("Phase(%d).String() = %q, want %q", int(phase), got, want)
+		}
+	}
+}
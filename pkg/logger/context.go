@@ -0,0 +1,90 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 日志中使用的标准字段名
+const (
+	FieldRequestID = "request_id"
+	FieldUserID    = "user_id"
+	FieldTraceID   = "trace_id"
+	FieldSpanID    = "span_id"
+	FieldError     = "error"
+)
+
+// contextKey 上下文键类型，避免与其他包冲突
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	userIDKey
+	clientInfoKey
+)
+
+// ClientInfo 请求方信息
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// ContextWithRequestID 将请求ID写入上下文
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext 从上下文读取请求ID
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// ContextWithUserID 将当前用户ID写入上下文
+func ContextWithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+// UserIDFromContext 从上下文读取当前用户ID
+func UserIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(userIDKey).(string)
+	return id
+}
+
+// ContextWithClientInfo 将请求方IP、User-Agent写入上下文
+func ContextWithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey, info)
+}
+
+// ClientInfoFromContext 从上下文读取请求方信息
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	if ctx == nil {
+		return ClientInfo{}
+	}
+	info, _ := ctx.Value(clientInfoKey).(ClientInfo)
+	return info
+}
+
+// contextFields 提取上下文中需要记录的字段
+func contextFields(ctx context.Context) map[string]interface{} {
+	fields := make(map[string]interface{}, 4)
+	if id := RequestIDFromContext(ctx); id != "" {
+		fields[FieldRequestID] = id
+	}
+	if id := UserIDFromContext(ctx); id != "" {
+		fields[FieldUserID] = id
+	}
+	// 关联追踪系统中的链路
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields[FieldTraceID] = sc.TraceID().String()
+		fields[FieldSpanID] = sc.SpanID().String()
+	}
+	return fields
+}
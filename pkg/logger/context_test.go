@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/pkg/tracing"
+)
+
+// newBufferLogger 输出到缓冲区的JSON日志
+func newBufferLogger(cfg config.LogConfig) (Logger, *bytes.Buffer) {
+	cfg.Format = "json"
+	l := New(cfg)
+	buf := &bytes.Buffer{}
+	l.(*logrusLogger).logger.SetOutput(buf)
+	return l, buf
+}
+
+// decodeLines 解析每行一条的JSON日志
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var entries []map[string]interface{}
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var entry map[string]interface{}
+		if err := json.Unmarshal(line, &entry); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestWithContextTraceID(t *testing.T) {
+	tp, err := tracing.New(config.TracingConfig{Enabled: true, Exporter: "memory"}, config.AppConfig{Name: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = tp.Shutdown(context.Background()) }()
+
+	l, buf := newBufferLogger(config.LogConfig{Level: "info"})
+	ctx, span := tracing.Start(context.Background(), "request")
+	l.WithContext(ctx).Info("traced")
+	l.WithContext(context.Background()).Info("untraced")
+	span.End()
+
+	spans := tp.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("spans = %d, want 1", len(spans))
+	}
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("log lines = %d, want 2", len(entries))
+	}
+	if entries[0][FieldTraceID] != spans[0].SpanContext.TraceID().String() ||
+		entries[0][FieldSpanID] != spans[0].SpanContext.SpanID().String() {
+		t.Errorf("traced entry = %v, want trace_id and span_id of the exported span", entries[0])
+	}
+	if _, ok := entries[1][FieldTraceID]; ok {
+		t.Errorf("untraced entry = %v, want no trace_id", entries[1])
+	}
+}
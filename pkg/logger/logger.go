@@ -1,9 +1,17 @@
 package logger
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"go-api-scaffold/internal/config"
 )
 
 // Logger 日志接口
@@ -13,22 +21,30 @@ type Logger interface {
 	Warn(msg string)
 	Error(msg string)
 	Fatal(msg string)
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
 	WithField(key string, value interface{}) Logger
 	WithFields(fields map[string]interface{}) Logger
+	WithError(err error) Logger
+	WithContext(ctx context.Context) Logger
 }
 
 // logrusLogger logrus实现
 type logrusLogger struct {
-	logger *logrus.Logger
-	entry  *logrus.Entry
+	logger  *logrus.Logger
+	entry   *logrus.Entry
+	sampler *sampler
 }
 
 // New 创建新的日志实例
-func New(level string) Logger {
+func New(cfg config.LogConfig) Logger {
 	logger := logrus.New()
 
 	// 设置日志级别
-	switch level {
+	switch cfg.Level {
 	case "debug":
 		logger.SetLevel(logrus.DebugLevel)
 	case "info":
@@ -43,18 +59,85 @@ func New(level string) Logger {
 		logger.SetLevel(logrus.InfoLevel)
 	}
 
-	// 设置JSON格式
-	logger.SetFormatter(&logrus.JSONFormatter{
-		TimestampFormat: "2006-01-02 15:04:05",
-	})
+	// 设置日志格式
+	logger.SetFormatter(newFormatter(cfg.Format))
 
 	// 设置输出
-	logger.SetOutput(os.Stdout)
+	out, err := newOutput(cfg)
+	if err != nil {
+		// 输出目标不可用时退回标准输出，避免日志丢失
+		logger.SetOutput(os.Stdout)
+		logger.Warn(fmt.Sprintf("Failed to open log output %q, falling back to stdout: %v", cfg.Output, err))
+	} else {
+		logger.SetOutput(out)
+	}
 
-	return &logrusLogger{
+	l := &logrusLogger{
 		logger: logger,
 		entry:  logrus.NewEntry(logger),
 	}
+	if cfg.Sampling.Enabled {
+		l.sampler = newSampler(cfg.Sampling)
+	}
+	return l
+}
+
+// newFormatter 根据配置创建日志格式化器
+func newFormatter(format string) logrus.Formatter {
+	const timestampFormat = "2006-01-02 15:04:05"
+
+	if strings.EqualFold(format, "text") {
+		return &logrus.TextFormatter{
+			FullTimestamp:   true,
+			TimestampFormat: timestampFormat,
+		}
+	}
+	return &logrus.JSONFormatter{
+		TimestampFormat: timestampFormat,
+	}
+}
+
+// newOutput 根据配置创建日志输出目标
+func newOutput(cfg config.LogConfig) (io.Writer, error) {
+	switch strings.ToLower(cfg.Output) {
+	case "", "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	case "file":
+		if cfg.File.Path == "" {
+			return nil, fmt.Errorf("log.file.path is required when output is file")
+		}
+		if err := os.MkdirAll(filepath.Dir(cfg.File.Path), 0o755); err != nil {
+			return nil, err
+		}
+		// lumberjack负责按大小、时间滚动及压缩
+		return &lumberjack.Logger{
+			Filename:   cfg.File.Path,
+			MaxSize:    cfg.File.MaxSize,
+			MaxAge:     cfg.File.MaxAge,
+			MaxBackups: cfg.File.MaxBackups,
+			Compress:   cfg.File.Compress,
+			LocalTime:  true,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported log output: %s", cfg.Output)
+	}
+}
+
+// Close 刷新并关闭日志输出，用于进程退出前确保文件日志落盘，标准输出不会被关闭
+func Close(l Logger) error {
+	ll, ok := l.(*logrusLogger)
+	if !ok {
+		return nil
+	}
+	switch out := ll.logger.Out.(type) {
+	case *lumberjack.Logger:
+		return out.Close()
+	case *os.File:
+		return out.Sync()
+	}
+	return nil
 }
 
 // Debug 调试日志
@@ -64,6 +147,9 @@ func (l *logrusLogger) Debug(msg string) {
 
 // Info 信息日志
 func (l *logrusLogger) Info(msg string) {
+	if l.sampler != nil && !l.sampler.allow(msg) {
+		return
+	}
 	l.entry.Info(msg)
 }
 
@@ -82,18 +168,63 @@ func (l *logrusLogger) Fatal(msg string) {
 	l.entry.Fatal(msg)
 }
 
+// Debugf 格式化调试日志
+func (l *logrusLogger) Debugf(format string, args ...interface{}) {
+	l.entry.Debugf(format, args...)
+}
+
+// Infof 格式化信息日志，采样按格式模板计数
+func (l *logrusLogger) Infof(format string, args ...interface{}) {
+	if l.sampler != nil && !l.sampler.allow(format) {
+		return
+	}
+	l.entry.Infof(format, args...)
+}
+
+// Warnf 格式化警告日志
+func (l *logrusLogger) Warnf(format string, args ...interface{}) {
+	l.entry.Warnf(format, args...)
+}
+
+// Errorf 格式化错误日志
+func (l *logrusLogger) Errorf(format string, args ...interface{}) {
+	l.entry.Errorf(format, args...)
+}
+
+// Fatalf 格式化致命错误日志
+func (l *logrusLogger) Fatalf(format string, args ...interface{}) {
+	l.entry.Fatalf(format, args...)
+}
+
 // WithField 添加单个字段
 func (l *logrusLogger) WithField(key string, value interface{}) Logger {
-	return &logrusLogger{
-		logger: l.logger,
-		entry:  l.entry.WithField(key, value),
-	}
+	return l.withEntry(l.entry.WithField(key, value))
 }
 
 // WithFields 添加多个字段
 func (l *logrusLogger) WithFields(fields map[string]interface{}) Logger {
+	return l.withEntry(l.entry.WithFields(fields))
+}
+
+// WithError 以error字段附加错误
+func (l *logrusLogger) WithError(err error) Logger {
+	return l.withEntry(l.entry.WithError(err))
+}
+
+// WithContext 附加上下文中的请求ID、用户ID等字段
+func (l *logrusLogger) WithContext(ctx context.Context) Logger {
+	entry := l.entry.WithContext(ctx)
+	if fields := contextFields(ctx); len(fields) > 0 {
+		entry = entry.WithFields(fields)
+	}
+	return l.withEntry(entry)
+}
+
+// withEntry 基于新的entry派生日志实例
+func (l *logrusLogger) withEntry(entry *logrus.Entry) Logger {
 	return &logrusLogger{
-		logger: l.logger,
-		entry:  l.entry.WithFields(fields),
+		logger:  l.logger,
+		entry:   entry,
+		sampler: l.sampler,
 	}
 }
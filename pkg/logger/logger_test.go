@@ -0,0 +1,180 @@
+package logger
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/natefinch/lumberjack.v2"
+
+	"go-api-scaffold/internal/config"
+)
+
+func TestNewLevel(t *testing.T) {
+	tests := map[string]logrus.Level{
+		"debug":   logrus.DebugLevel,
+		"info":    logrus.InfoLevel,
+		"warn":    logrus.WarnLevel,
+		"error":   logrus.ErrorLevel,
+		"fatal":   logrus.FatalLevel,
+		"":        logrus.InfoLevel,
+		"verbose": logrus.InfoLevel,
+	}
+	for level, want := range tests {
+		l := New(config.LogConfig{Level: level, Output: "stderr"}).(*logrusLogger)
+		if got := l.logger.GetLevel(); got != want {
+			t.Errorf("level %q = %s, want %s", level, got, want)
+		}
+	}
+}
+
+func TestNewFormatter(t *testing.T) {
+	for _, format := range []string{"text", "TEXT"} {
+		if _, ok := newFormatter(format).(*logrus.TextFormatter); !ok {
+			t.Errorf("newFormatter(%q) = %T, want text", format, newFormatter(format))
+		}
+	}
+	for _, format := range []string{"json", "", "yaml"} {
+		if _, ok := newFormatter(format).(*logrus.JSONFormatter); !ok {
+			t.Errorf("newFormatter(%q) = %T, want JSON", format, newFormatter(format))
+		}
+	}
+}
+
+func TestNewOutput(t *testing.T) {
+	tests := []struct {
+		output string
+		want   interface{}
+	}{
+		{"", os.Stdout},
+		{"stdout", os.Stdout},
+		{"STDERR", os.Stderr},
+	}
+	for _, tt := range tests {
+		out, err := newOutput(config.LogConfig{Output: tt.output})
+		if err != nil || out != tt.want {
+			t.Errorf("newOutput(%q) = %v, %v, want %v", tt.output, out, err, tt.want)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "logs", "app.log")
+	out, err := newOutput(config.LogConfig{Output: "file", File: config.LogFileConfig{
+		Path:       path,
+		MaxSize:    100,
+		MaxAge:     7,
+		MaxBackups: 3,
+		Compress:   true,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lj, ok := out.(*lumberjack.Logger)
+	if !ok {
+		t.Fatalf("file output = %T, want *lumberjack.Logger", out)
+	}
+	if lj.Filename != path || lj.MaxSize != 100 || lj.MaxAge != 7 || lj.MaxBackups != 3 || !lj.Compress || !lj.LocalTime {
+		t.Errorf("lumberjack = %+v, want rotation settings from config", lj)
+	}
+	// 日志目录自动创建
+	if info, err := os.Stat(filepath.Dir(path)); err != nil || !info.IsDir() {
+		t.Errorf("log directory not created: %v", err)
+	}
+
+	if _, err := newOutput(config.LogConfig{Output: "file"}); err == nil {
+		t.Error("file output without path: error = nil")
+	}
+	if _, err := newOutput(config.LogConfig{Output: "syslog"}); err == nil {
+		t.Error("unsupported output: error = nil")
+	}
+	// 输出目标不可用时退回标准输出
+	if l := New(config.LogConfig{Level: "fatal", Output: "syslog"}).(*logrusLogger); l.logger.Out != os.Stdout {
+		t.Errorf("fallback output = %v, want stdout", l.logger.Out)
+	}
+}
+
+func TestFileOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	l := New(config.LogConfig{Level: "info", Format: "json", Output: "file", File: config.LogFileConfig{Path: path}})
+	l.WithField("component", "test").Info("written to file")
+	if err := Close(l); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("invalid log file %q: %v", data, err)
+	}
+	if entry["msg"] != "written to file" || entry["component"] != "test" || entry["level"] != "info" {
+		t.Errorf("entry = %v", entry)
+	}
+}
+
+func TestTextFormat(t *testing.T) {
+	l, buf := newBufferLogger(config.LogConfig{Level: "info"})
+	l.(*logrusLogger).logger.SetFormatter(newFormatter("text"))
+	l.WithField("user_id", 7).Info("hello")
+
+	line := buf.String()
+	if !strings.Contains(line, `msg=hello`) || !strings.Contains(line, "user_id=7") || !strings.HasPrefix(line, "time=") {
+		t.Errorf("text line = %q", line)
+	}
+}
+
+func TestSampler(t *testing.T) {
+	s := newSampler(config.LogSamplingConfig{Initial: 2, Thereafter: 3, Tick: time.Hour})
+
+	// 前2条全部输出，之后每3条输出1条
+	want := []bool{true, true, false, false, true, false, false, true}
+	for i, w := range want {
+		if got := s.allow("hot"); got != w {
+			t.Errorf("message %d allowed = %v, want %v", i+1, got, w)
+		}
+	}
+	// 不同消息分别计数
+	if !s.allow("cold") {
+		t.Error("first occurrence of another message was dropped")
+	}
+
+	// 新周期重新计数
+	s.resetAt = time.Now().Add(-time.Second)
+	if !s.allow("hot") || !s.allow("hot") || s.allow("hot") {
+		t.Error("counts were not reset for the new tick")
+	}
+
+	none := newSampler(config.LogSamplingConfig{Initial: 1})
+	if !none.allow("x") || none.allow("x") || none.allow("x") {
+		t.Error("thereafter 0: want only the initial messages")
+	}
+	if none.tick != time.Second {
+		t.Errorf("default tick = %s, want 1s", none.tick)
+	}
+}
+
+func TestSamplingOnlyInfo(t *testing.T) {
+	l, buf := newBufferLogger(config.LogConfig{
+		Level:    "info",
+		Sampling: config.LogSamplingConfig{Enabled: true, Initial: 1, Thereafter: 0, Tick: time.Hour},
+	})
+	for i := 0; i < 3; i++ {
+		l.Info("polling")
+		l.Infof("polled %d", i)
+		l.Warn("slow")
+	}
+
+	counts := map[string]int{}
+	for _, entry := range decodeLines(t, buf) {
+		counts[entry["msg"].(string)]++
+	}
+	// Info按消息采样，Infof按格式模板采样，Warn不采样
+	if counts["polling"] != 1 || counts["polled 0"] != 1 || counts["polled 1"] != 0 || counts["slow"] != 3 {
+		t.Errorf("counts = %v", counts)
+	}
+}
@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"sync"
+	"time"
+
+	"go-api-scaffold/internal/config"
+)
+
+// sampler 按消息内容对高频日志进行采样
+// 每个周期内同一消息前initial条全部输出，之后每thereafter条输出1条
+type sampler struct {
+	mu         sync.Mutex
+	initial    int
+	thereafter int
+	tick       time.Duration
+	resetAt    time.Time
+	counts     map[string]int
+}
+
+// newSampler 创建采样器
+func newSampler(cfg config.LogSamplingConfig) *sampler {
+	s := &sampler{
+		initial:    cfg.Initial,
+		thereafter: cfg.Thereafter,
+		tick:       cfg.Tick,
+		counts:     make(map[string]int),
+	}
+	if s.tick <= 0 {
+		s.tick = time.Second
+	}
+	return s
+}
+
+// allow 判断当前消息是否需要输出
+func (s *sampler) allow(msg string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.After(s.resetAt) {
+		// 进入新的采样周期，清空计数
+		s.counts = make(map[string]int, len(s.counts))
+		s.resetAt = now.Add(s.tick)
+	}
+
+	s.counts[msg]++
+	n := s.counts[msg]
+	if n <= s.initial {
+		return true
+	}
+	if s.thereafter <= 0 {
+		return false
+	}
+	return (n-s.initial)%s.thereafter == 0
+}
@@ -0,0 +1,116 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// LevelFatal slog中表示致命错误的级别
+const LevelFatal = slog.LevelError + 4
+
+// slogLogger 基于log/slog的实现，可接入任意slog.Handler
+type slogLogger struct {
+	logger *slog.Logger
+	ctx    context.Context
+}
+
+// NewSlog 创建由slog.Handler驱动的日志实例
+func NewSlog(handler slog.Handler) Logger {
+	return &slogLogger{
+		logger: slog.New(handler),
+		ctx:    context.Background(),
+	}
+}
+
+// Debug 调试日志
+func (l *slogLogger) Debug(msg string) {
+	l.logger.Log(l.ctx, slog.LevelDebug, msg)
+}
+
+// Info 信息日志
+func (l *slogLogger) Info(msg string) {
+	l.logger.Log(l.ctx, slog.LevelInfo, msg)
+}
+
+// Warn 警告日志
+func (l *slogLogger) Warn(msg string) {
+	l.logger.Log(l.ctx, slog.LevelWarn, msg)
+}
+
+// Error 错误日志
+func (l *slogLogger) Error(msg string) {
+	l.logger.Log(l.ctx, slog.LevelError, msg)
+}
+
+// Fatal 致命错误日志，记录后退出进程
+func (l *slogLogger) Fatal(msg string) {
+	l.logger.Log(l.ctx, LevelFatal, msg)
+	os.Exit(1)
+}
+
+// Debugf 格式化调试日志
+func (l *slogLogger) Debugf(format string, args ...interface{}) {
+	l.logf(slog.LevelDebug, format, args...)
+}
+
+// Infof 格式化信息日志
+func (l *slogLogger) Infof(format string, args ...interface{}) {
+	l.logf(slog.LevelInfo, format, args...)
+}
+
+// Warnf 格式化警告日志
+func (l *slogLogger) Warnf(format string, args ...interface{}) {
+	l.logf(slog.LevelWarn, format, args...)
+}
+
+// Errorf 格式化错误日志
+func (l *slogLogger) Errorf(format string, args ...interface{}) {
+	l.logf(slog.LevelError, format, args...)
+}
+
+// Fatalf 格式化致命错误日志，记录后退出进程
+func (l *slogLogger) Fatalf(format string, args ...interface{}) {
+	l.logf(LevelFatal, format, args...)
+	os.Exit(1)
+}
+
+// WithField 添加单个字段
+func (l *slogLogger) WithField(key string, value interface{}) Logger {
+	return &slogLogger{logger: l.logger.With(key, value), ctx: l.ctx}
+}
+
+// WithFields 添加多个字段
+func (l *slogLogger) WithFields(fields map[string]interface{}) Logger {
+	args := make([]any, 0, len(fields)*2)
+	for k, v := range fields {
+		args = append(args, k, v)
+	}
+	return &slogLogger{logger: l.logger.With(args...), ctx: l.ctx}
+}
+
+// WithError 以error字段附加错误
+func (l *slogLogger) WithError(err error) Logger {
+	if err == nil {
+		return l
+	}
+	return l.WithField(FieldError, err.Error())
+}
+
+// WithContext 附加上下文，并记录其中的请求ID、用户ID等字段
+func (l *slogLogger) WithContext(ctx context.Context) Logger {
+	next := &slogLogger{logger: l.logger, ctx: ctx}
+	if fields := contextFields(ctx); len(fields) > 0 {
+		return next.WithFields(fields)
+	}
+	return next
+}
+
+// logf 格式化输出，级别未启用时跳过格式化开销
+func (l *slogLogger) logf(level slog.Level, format string, args ...interface{}) {
+	if !l.logger.Enabled(l.ctx, level) {
+		return
+	}
+	l.logger.Log(l.ctx, level, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"go-api-scaffold/internal/config"
+)
+
+// newBufferSlog 输出到缓冲区的slog JSON日志
+func newBufferSlog(level slog.Level) (*slog.JSONHandler, *bytes.Buffer) {
+	buf := &bytes.Buffer{}
+	return slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: level}), buf
+}
+
+func TestSlogLevels(t *testing.T) {
+	handler, buf := newBufferSlog(slog.LevelInfo)
+	l := NewSlog(handler)
+
+	l.Debug("hidden")
+	l.Debugf("hidden %d", 1)
+	l.Info("info")
+	l.Warnf("warn %d", 2)
+	l.Error("error")
+	l.Errorf("error %s", "formatted")
+
+	var got []string
+	for _, entry := range decodeLines(t, buf) {
+		got = append(got, entry["level"].(string)+":"+entry["msg"].(string))
+	}
+	want := []string{"INFO:info", "WARN:warn 2", "ERROR:error", "ERROR:error formatted"}
+	if len(got) != len(want) {
+		t.Fatalf("entries = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("entry %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSlogAttrs(t *testing.T) {
+	handler, buf := newBufferSlog(slog.LevelDebug)
+	l := NewSlog(handler)
+
+	base := l.WithField("component", "jobs")
+	base.WithFields(map[string]interface{}{"job": "send", "attempt": 2}).
+		WithError(errors.New("timeout")).
+		Warn("Job failed")
+	// 派生的日志实例互不影响
+	base.WithError(nil).Info("Job succeeded")
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("entries = %d, want 2", len(entries))
+	}
+	failed := entries[0]
+	if failed["component"] != "jobs" || failed["job"] != "send" || failed["attempt"] != float64(2) || failed[FieldError] != "timeout" {
+		t.Errorf("failed entry = %v", failed)
+	}
+	succeeded := entries[1]
+	if succeeded["component"] != "jobs" || succeeded["job"] != nil || succeeded[FieldError] != nil {
+		t.Errorf("succeeded entry = %v, want only the base attribute", succeeded)
+	}
+}
+
+func TestSlogGroups(t *testing.T) {
+	handler, buf := newBufferSlog(slog.LevelInfo)
+	l := NewSlog(handler.WithGroup("app"))
+
+	l.WithField("user_id", "7").Info("grouped")
+
+	var entry struct {
+		Msg string `json:"msg"`
+		App struct {
+			UserID string `json:"user_id"`
+		} `json:"app"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Msg != "grouped" || entry.App.UserID != "7" {
+		t.Errorf("entry = %s, want fields nested under the handler group", buf.String())
+	}
+}
+
+// ctxHandler 记录日志调用时传入的上下文
+type ctxHandler struct {
+	slog.Handler
+	got *context.Context
+}
+
+func (h ctxHandler) Handle(ctx context.Context, r slog.Record) error {
+	*h.got = ctx
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h ctxHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return ctxHandler{Handler: h.Handler.WithAttrs(attrs), got: h.got}
+}
+
+func TestSlogWithContext(t *testing.T) {
+	handler, buf := newBufferSlog(slog.LevelInfo)
+	var got context.Context
+	l := NewSlog(ctxHandler{Handler: handler, got: &got})
+
+	ctx := ContextWithUserID(ContextWithRequestID(context.Background(), "req-1"), "admin")
+	l.WithContext(ctx).Info("with context")
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 1 || entries[0][FieldRequestID] != "req-1" || entries[0][FieldUserID] != "admin" {
+		t.Errorf("entries = %v, want request_id and user_id", entries)
+	}
+	// 上下文传给handler，便于自定义handler读取
+	if RequestIDFromContext(got) != "req-1" {
+		t.Error("handler did not receive the logging context")
+	}
+}
+
+func TestWithContextFields(t *testing.T) {
+	l, buf := newBufferLogger(config.LogConfig{Level: "info"})
+
+	ctx := ContextWithUserID(ContextWithRequestID(context.Background(), "req-1"), "admin")
+	l.WithContext(ctx).WithError(errors.New("boom")).Errorf("failed %d", 3)
+	l.WithContext(context.Background()).Info("plain")
+
+	entries := decodeLines(t, buf)
+	if len(entries) != 2 {
+		t.Fatalf("entries = %d, want 2", len(entries))
+	}
+	first := entries[0]
+	if first[FieldRequestID] != "req-1" || first[FieldUserID] != "admin" || first[FieldError] != "boom" || first["msg"] != "failed 3" {
+		t.Errorf("entry = %v", first)
+	}
+	for _, key := range []string{FieldRequestID, FieldUserID, FieldError} {
+		if _, ok := entries[1][key]; ok {
+			t.Errorf("plain entry has %s: %v", key, entries[1])
+		}
+	}
+}
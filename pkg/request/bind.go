@@ -0,0 +1,69 @@
+// Package request 请求体解析，按Content-Type选择与 pkg/response 响应格式对应的解码方式
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"go-api-scaffold/pkg/response"
+)
+
+// ErrUnsupportedMediaType 请求体格式不受支持
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// Bind 按Content-Type解析请求体，未指定时按JSON解析
+// XML按模型的xml标签解析；MessagePack按json标签解析；Protobuf请求体为 google.protobuf.Struct 消息，
+// 与JSON请求体结构相同
+func Bind(c *gin.Context, obj interface{}) error {
+	format := response.FormatJSON
+	if contentType := c.GetHeader("Content-Type"); contentType != "" {
+		var ok bool
+		if format, ok = response.ParseFormat(contentType); !ok {
+			return fmt.Errorf("%w: %s", ErrUnsupportedMediaType, contentType)
+		}
+	}
+
+	switch format {
+	case response.FormatXML:
+		return c.ShouldBindWith(obj, binding.XML)
+	case response.FormatMsgPack:
+		return c.ShouldBindWith(obj, binding.MsgPack)
+	case response.FormatProtobuf:
+		return bindProtobuf(c, obj)
+	default:
+		return c.ShouldBindWith(obj, binding.JSON)
+	}
+}
+
+// bindProtobuf 解码 google.protobuf.Struct 并按JSON字段映射到模型
+func bindProtobuf(c *gin.Context, obj interface{}) error {
+	if c.Request.Body == nil {
+		return errors.New("invalid request")
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		return err
+	}
+	var message structpb.Struct
+	if err := proto.Unmarshal(body, &message); err != nil {
+		return err
+	}
+	data, err := json.Marshal(message.AsMap())
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, obj); err != nil {
+		return err
+	}
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
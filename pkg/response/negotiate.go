@@ -0,0 +1,163 @@
+package response
+
+import (
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Format 响应/请求体格式，值为规范的媒体类型
+type Format string
+
+// 支持的格式
+const (
+	FormatJSON     Format = "application/json"
+	FormatXML      Format = "application/xml"
+	FormatMsgPack  Format = "application/msgpack"
+	FormatProtobuf Format = "application/x-protobuf"
+)
+
+// Formats 支持的格式，同等优先级时按此顺序选择
+var Formats = []Format{FormatJSON, FormatXML, FormatMsgPack, FormatProtobuf}
+
+// formatAliases 各格式可接受的媒体类型
+var formatAliases = map[string]Format{
+	"application/json":                FormatJSON,
+	"application/xml":                 FormatXML,
+	"text/xml":                        FormatXML,
+	"application/problem+xml":         FormatXML,
+	"application/msgpack":             FormatMsgPack,
+	"application/x-msgpack":           FormatMsgPack,
+	"application/vnd.msgpack":         FormatMsgPack,
+	"application/x-protobuf":          FormatProtobuf,
+	"application/protobuf":            FormatProtobuf,
+	"application/vnd.google.protobuf": FormatProtobuf,
+}
+
+// supportedFormats 支持的格式列表，用于错误提示
+func supportedFormats() string {
+	names := make([]string, len(Formats))
+	for i, format := range Formats {
+		names[i] = string(format)
+	}
+	return strings.Join(names, ", ")
+}
+
+// formatKey gin上下文中保存协商结果的键
+const formatKey = "response_format"
+
+// ParseFormat 根据媒体类型（可带参数）识别格式
+// application/*+json（如接口版本使用的 application/vnd.go-api-scaffold+json）按JSON处理
+func ParseFormat(mediaType string) (Format, bool) {
+	typ, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return "", false
+	}
+	if format, ok := formatAliases[typ]; ok {
+		return format, true
+	}
+	if strings.HasPrefix(typ, "application/") && strings.HasSuffix(typ, "+json") {
+		return FormatJSON, true
+	}
+	return "", false
+}
+
+// acceptRange Accept头中的一项
+type acceptRange struct {
+	typ     string // 主类型，如 application
+	subtype string // 子类型，如 json 或 *
+	format  Format // 具体媒体类型对应的格式
+	q       float64
+}
+
+// parseAccept 解析Accept头，忽略格式错误的项
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		mediaType, params, err := mime.ParseMediaType(part)
+		if err != nil {
+			continue
+		}
+		typ, subtype, ok := strings.Cut(mediaType, "/")
+		if !ok {
+			continue
+		}
+		r := acceptRange{typ: typ, subtype: subtype, q: 1}
+		if q, err := strconv.ParseFloat(params["q"], 64); err == nil {
+			r.q = q
+		}
+		if subtype != "*" {
+			r.format, _ = ParseFormat(mediaType)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// match 返回该项对格式的匹配程度：2为具体类型，1为 type/*，0为 */*，-1为不匹配
+func (r acceptRange) match(format Format) int {
+	switch {
+	case r.typ == "*" && r.subtype == "*":
+		return 0
+	case r.subtype == "*":
+		for alias, f := range formatAliases {
+			if f == format && strings.HasPrefix(alias, r.typ+"/") {
+				return 1
+			}
+		}
+		return -1
+	case r.format == format:
+		return 2
+	default:
+		return -1
+	}
+}
+
+// NegotiateFormat 按Accept头选择响应格式
+// 每种格式取最具体的匹配项的q值，选择q值最高的格式，相同时依次按Accept中的顺序和 Formats 的顺序
+// Accept为空时使用JSON，没有可接受的格式时返回false
+func NegotiateFormat(accept string) (Format, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return FormatJSON, true
+	}
+	ranges := parseAccept(accept)
+
+	var (
+		best      Format
+		bestQ     float64
+		bestIndex int
+	)
+	for _, format := range Formats {
+		level, q, index := -1, 0.0, 0
+		for i, r := range ranges {
+			if m := r.match(format); m > level {
+				level, q, index = m, r.q, i
+			}
+		}
+		if level < 0 || q <= 0 {
+			continue
+		}
+		if best == "" || q > bestQ || (q == bestQ && index < bestIndex) {
+			best, bestQ, bestIndex = format, q, index
+		}
+	}
+	return best, best != ""
+}
+
+// Negotiate 协商当前请求的响应格式并保存到上下文，供后续响应复用
+func Negotiate(c *gin.Context) (Format, bool) {
+	if value, ok := c.Get(formatKey); ok {
+		return value.(Format), true
+	}
+	format, ok := NegotiateFormat(c.GetHeader("Accept"))
+	if ok {
+		c.Set(formatKey, format)
+	}
+	return format, ok
+}
@@ -0,0 +1,61 @@
+package response
+
+import "testing"
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   Format
+		wantOK bool
+	}{
+		{"", FormatJSON, true},
+		{"application/json", FormatJSON, true},
+		{"application/xml", FormatXML, true},
+		{"text/xml", FormatXML, true},
+		{"application/x-msgpack", FormatMsgPack, true},
+		{"application/protobuf", FormatProtobuf, true},
+		{"application/vnd.go-api-scaffold+json; version=2", FormatJSON, true},
+		{"application/problem+json", FormatJSON, true},
+		{"*/*", FormatJSON, true},
+		{"application/*", FormatJSON, true},
+		{"text/*", FormatXML, true},
+		{"application/xml, application/json", FormatXML, true},
+		{"application/json;q=0.5, application/xml", FormatXML, true},
+		{"application/xml;q=0.9, */*;q=0.1", FormatXML, true},
+		{"application/json;q=0, */*", FormatXML, true},
+		{"text/html, application/msgpack;q=0.8", FormatMsgPack, true},
+		{"text/html", "", false},
+		{"application/json;q=0", "", false},
+		{"not a media type", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := NegotiateFormat(tt.accept)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("NegotiateFormat(%q) = %q, %v, want %q, %v", tt.accept, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		want      Format
+		wantOK    bool
+	}{
+		{"application/json", FormatJSON, true},
+		{"application/json; charset=utf-8", FormatJSON, true},
+		{"application/merge-patch+json", FormatJSON, true},
+		{"application/problem+xml", FormatXML, true},
+		{"application/vnd.msgpack", FormatMsgPack, true},
+		{"application/vnd.google.protobuf", FormatProtobuf, true},
+		{"text/plain", "", false},
+		{"text/+json", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseFormat(tt.mediaType)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("ParseFormat(%q) = %q, %v, want %q, %v", tt.mediaType, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package response
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api-scaffold/pkg/logger"
+)
+
+// ErrorFormat 错误响应格式
+type ErrorFormat string
+
+const (
+	// ErrorFormatEnvelope 统一响应结构 {code,message,data}
+	ErrorFormatEnvelope ErrorFormat = "envelope"
+	// ErrorFormatProblem RFC 7807 问题详情（application/problem+json）
+	ErrorFormatProblem ErrorFormat = "problem"
+)
+
+// 问题详情媒体类型
+const (
+	MIMEProblemJSON = "application/problem+json"
+	MIMEProblemXML  = "application/problem+xml"
+)
+
+// problemNamespace RFC 7807 XML格式的命名空间
+const problemNamespace = "urn:ietf:rfc:7807"
+
+// ErrorOptions 错误响应选项
+type ErrorOptions struct {
+	Format ErrorFormat
+	// TypeBaseURL 问题类型URI前缀，如 https://api.example.com/problems，type为前缀加类型名（如 /not-found）；
+	// 为空时type为about:blank，title为HTTP状态说明
+	TypeBaseURL string
+}
+
+// errorOptions 当前错误响应选项
+var errorOptions = ErrorOptions{Format: ErrorFormatEnvelope}
+
+// SetErrorOptions 设置错误响应格式，应在启动时、处理请求前调用
+// 无论如何配置，Accept中明确请求 application/problem+json 或 application/problem+xml 的客户端都会收到问题详情
+func SetErrorOptions(opts ErrorOptions) error {
+	switch opts.Format {
+	case "":
+		opts.Format = ErrorFormatEnvelope
+	case ErrorFormatEnvelope, ErrorFormatProblem:
+	default:
+		return fmt.Errorf("unsupported error format: %s", opts.Format)
+	}
+	opts.TypeBaseURL = strings.TrimSuffix(opts.TypeBaseURL, "/")
+	errorOptions = opts
+	return nil
+}
+
+// Problem RFC 7807 问题详情，request_id与errors为扩展成员
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// problemValidation 参数校验失败的问题类型名
+const problemValidation = "validation-error"
+
+// useProblem 当前请求的错误响应是否使用问题详情
+func useProblem(c *gin.Context) bool {
+	return errorOptions.Format == ErrorFormatProblem || acceptsProblem(c.GetHeader("Accept"))
+}
+
+// renderError 按配置输出错误响应，kind为问题类型名，errs为字段校验错误
+func renderError(c *gin.Context, status, code int, kind, message string, errs []FieldError) {
+	if useProblem(c) {
+		RenderProblem(c, NewProblem(c, status, kind, message, errs))
+		return
+	}
+	resp := Response{Code: code, Message: message}
+	if errs != nil {
+		resp.Data = errs
+	}
+	Render(c, status, resp)
+}
+
+// NewProblem 创建问题详情，kind为问题类型名，为空时由状态码生成（如 not-found）
+func NewProblem(c *gin.Context, status int, kind, detail string, errs []FieldError) Problem {
+	p := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		RequestID: logger.RequestIDFromContext(c.Request.Context()),
+		Errors:    errs,
+	}
+	if errorOptions.TypeBaseURL != "" {
+		if kind == "" {
+			kind = strings.ToLower(strings.ReplaceAll(p.Title, " ", "-"))
+		}
+		p.Type = errorOptions.TypeBaseURL + "/" + kind
+	}
+	return p
+}
+
+// RenderProblem 输出问题详情，JSON、XML分别使用 application/problem+json、application/problem+xml，
+// 其余格式与普通响应相同；没有可接受的格式时使用JSON
+func RenderProblem(c *gin.Context, p Problem) {
+	format, ok := Negotiate(c)
+	if !ok {
+		format = FormatJSON
+	}
+
+	data, _ := json.Marshal(p)
+	switch format {
+	case FormatJSON:
+		c.Data(p.Status, MIMEProblemJSON, data)
+		return
+	case FormatXML:
+		root := xml.StartElement{Name: xml.Name{Space: problemNamespace, Local: "problem"}}
+		if body, err := jsonToXML(data, root, "i"); err == nil {
+			c.Data(p.Status, MIMEProblemXML+"; charset=utf-8", body)
+			return
+		}
+	default:
+		if body, err := Encode(format, p); err == nil {
+			c.Data(p.Status, contentType(format), body)
+			return
+		}
+	}
+	c.Data(p.Status, MIMEProblemJSON, data)
+}
+
+// acceptsProblem Accept中是否明确请求问题详情
+func acceptsProblem(accept string) bool {
+	if accept == "" {
+		return false
+	}
+	for _, r := range parseAccept(accept) {
+		if r.typ == "application" && (r.subtype == "problem+json" || r.subtype == "problem+xml") && r.q > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,189 @@
+package response
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"go-api-scaffold/pkg/logger"
+)
+
+// useErrorOptions 在测试期间使用指定的错误响应选项
+func useErrorOptions(t *testing.T, opts ErrorOptions) {
+	t.Helper()
+	if err := SetErrorOptions(opts); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = SetErrorOptions(ErrorOptions{}) })
+}
+
+// renderErrorFor 以指定Accept请求调用render并返回响应
+func renderErrorFor(accept string, render func(c *gin.Context)) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/42", nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	c.Request = req.WithContext(logger.ContextWithRequestID(req.Context(), "req-1"))
+	render(c)
+	return w
+}
+
+func notFound(c *gin.Context) { NotFound(c, "User not found") }
+
+func TestSetErrorOptions(t *testing.T) {
+	t.Cleanup(func() { _ = SetErrorOptions(ErrorOptions{}) })
+
+	if err := SetErrorOptions(ErrorOptions{Format: "html"}); err == nil {
+		t.Error("SetErrorOptions(html) error = nil, want unsupported format")
+	}
+	if err := SetErrorOptions(ErrorOptions{TypeBaseURL: "https://api.example.com/problems/"}); err != nil {
+		t.Fatal(err)
+	}
+	if errorOptions.Format != ErrorFormatEnvelope || errorOptions.TypeBaseURL != "https://api.example.com/problems" {
+		t.Errorf("options = %+v, want envelope default and trimmed base URL", errorOptions)
+	}
+}
+
+func TestErrorFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		format      ErrorFormat
+		accept      string
+		wantProblem bool
+		wantType    string
+	}{
+		{"envelope", ErrorFormatEnvelope, "", false, "application/json; charset=utf-8"},
+		{"envelope with json accept", ErrorFormatEnvelope, "application/json", false, "application/json; charset=utf-8"},
+		// 明确请求问题详情时忽略envelope配置
+		{"envelope with problem accept", ErrorFormatEnvelope, "application/problem+json", true, MIMEProblemJSON},
+		{"problem", ErrorFormatProblem, "", true, MIMEProblemJSON},
+		{"problem with json accept", ErrorFormatProblem, "application/json", true, MIMEProblemJSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useErrorOptions(t, ErrorOptions{Format: tt.format})
+			w := renderErrorFor(tt.accept, notFound)
+
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("status = %d, want 404", w.Code)
+			}
+			if got := w.Header().Get("Content-Type"); got != tt.wantType {
+				t.Errorf("Content-Type = %q, want %q", got, tt.wantType)
+			}
+			var body map[string]interface{}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("invalid JSON %q: %v", w.Body.String(), err)
+			}
+			if tt.wantProblem {
+				if body["detail"] != "User not found" || body["status"] != float64(404) || body["type"] != "about:blank" ||
+					body["title"] != "Not Found" || body["instance"] != "/api/v1/users/42" {
+					t.Errorf("problem = %v", body)
+				}
+				if _, ok := body["code"]; ok {
+					t.Errorf("problem = %v, want no envelope members", body)
+				}
+				return
+			}
+			if body["code"] != float64(CodeNotFound) || body["message"] != "User not found" {
+				t.Errorf("envelope = %v", body)
+			}
+		})
+	}
+}
+
+func TestProblemType(t *testing.T) {
+	useErrorOptions(t, ErrorOptions{Format: ErrorFormatProblem, TypeBaseURL: "https://api.example.com/problems"})
+
+	tests := []struct {
+		name   string
+		render func(c *gin.Context)
+		want   string
+	}{
+		{"from status", notFound, "https://api.example.com/problems/not-found"},
+		{"multi-word status", func(c *gin.Context) { ServerError(c, "boom") }, "https://api.example.com/problems/internal-server-error"},
+		{"explicit kind", func(c *gin.Context) { ValidationError(c, "Validation failed", nil) }, "https://api.example.com/problems/validation-error"},
+	}
+	for _, tt := range tests {
+		var p Problem
+		w := renderErrorFor("", tt.render)
+		if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+			t.Fatal(err)
+		}
+		if p.Type != tt.want {
+			t.Errorf("%s: type = %q, want %q", tt.name, p.Type, tt.want)
+		}
+	}
+}
+
+func TestProblemExtensions(t *testing.T) {
+	useErrorOptions(t, ErrorOptions{Format: ErrorFormatProblem})
+	errs := []FieldError{{In: "body", Field: "email", Message: "must be a valid email"}}
+
+	w := renderErrorFor("", func(c *gin.Context) { ValidationError(c, "Validation failed", errs) })
+	var p Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Status != http.StatusBadRequest || p.RequestID != "req-1" {
+		t.Errorf("problem = %+v, want status 400 and request_id", p)
+	}
+	if len(p.Errors) != 1 || p.Errors[0] != errs[0] {
+		t.Errorf("errors = %+v, want %+v", p.Errors, errs)
+	}
+
+	// 没有字段错误时省略errors
+	w = renderErrorFor("", notFound)
+	if strings.Contains(w.Body.String(), `"errors"`) {
+		t.Errorf("body = %s, want errors omitted", w.Body.String())
+	}
+}
+
+func TestProblemXML(t *testing.T) {
+	useErrorOptions(t, ErrorOptions{Format: ErrorFormatProblem})
+	errs := []FieldError{{In: "query", Field: "page", Message: "must be a number"}}
+
+	for _, accept := range []string{"application/problem+xml", "application/xml"} {
+		w := renderErrorFor(accept, func(c *gin.Context) { ValidationError(c, "Validation failed", errs) })
+		if got := w.Header().Get("Content-Type"); got != MIMEProblemXML+"; charset=utf-8" {
+			t.Fatalf("Accept %s: Content-Type = %q", accept, got)
+		}
+
+		var p struct {
+			XMLName   xml.Name `xml:"urn:ietf:rfc:7807 problem"`
+			Type      string   `xml:"type"`
+			Title     string   `xml:"title"`
+			Status    int      `xml:"status"`
+			Detail    string   `xml:"detail"`
+			RequestID string   `xml:"request_id"`
+			Errors    []struct {
+				In      string `xml:"in"`
+				Field   string `xml:"field"`
+				Message string `xml:"message"`
+			} `xml:"errors>i"`
+		}
+		if err := xml.Unmarshal(w.Body.Bytes(), &p); err != nil {
+			t.Fatalf("Accept %s: invalid XML %q: %v", accept, w.Body.String(), err)
+		}
+		if p.Type != "about:blank" || p.Status != http.StatusBadRequest || p.Detail != "Validation failed" || p.RequestID != "req-1" {
+			t.Errorf("Accept %s: problem = %+v", accept, p)
+		}
+		if len(p.Errors) != 1 || p.Errors[0].Field != "page" || p.Errors[0].In != "query" {
+			t.Errorf("Accept %s: errors = %+v", accept, p.Errors)
+		}
+	}
+
+	// envelope模式下明确请求XML问题详情
+	useErrorOptions(t, ErrorOptions{})
+	w := renderErrorFor("application/problem+xml", notFound)
+	if got := w.Header().Get("Content-Type"); got != MIMEProblemXML+"; charset=utf-8" || !strings.Contains(w.Body.String(), "<detail>User not found</detail>") {
+		t.Errorf("Content-Type = %q, body = %s, want XML problem", got, w.Body.String())
+	}
+}
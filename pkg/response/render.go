@@ -0,0 +1,195 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// msgpackHandle MessagePack编码配置，使用新版规范区分字符串与二进制
+var msgpackHandle = &codec.MsgpackHandle{WriteExt: true}
+
+// Render 按协商的格式输出响应，没有可接受的格式时返回406
+// XML、MessagePack、Protobuf均由JSON表示转换而来，字段名与结构与JSON响应一致：
+// XML根元素为response，数组元素为item；Protobuf为 google.protobuf.Struct 消息
+func Render(c *gin.Context, status int, obj interface{}) {
+	format, ok := Negotiate(c)
+	if !ok {
+		NotAcceptable(c)
+		return
+	}
+	if format == FormatJSON {
+		c.JSON(status, obj)
+		return
+	}
+
+	body, err := Encode(format, obj)
+	if err != nil {
+		_ = c.Error(err)
+		c.JSON(http.StatusInternalServerError, Response{
+			Code:    CodeServerError,
+			Message: "Failed to encode response",
+		})
+		return
+	}
+	c.Data(status, contentType(format), body)
+}
+
+// Encode 将对象编码为指定格式
+func Encode(format Format, obj interface{}) ([]byte, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatJSON:
+		return data, nil
+	case FormatXML:
+		return jsonToXML(data, xml.StartElement{Name: xml.Name{Local: "response"}}, "item")
+	case FormatMsgPack:
+		value, err := decodeGeneric(data)
+		if err != nil {
+			return nil, err
+		}
+		var out []byte
+		err = codec.NewEncoderBytes(&out, msgpackHandle).Encode(normalizeNumbers(value))
+		return out, err
+	case FormatProtobuf:
+		value, err := decodeGeneric(data)
+		if err != nil {
+			return nil, err
+		}
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, errors.New("protobuf response must be an object")
+		}
+		message, err := structpb.NewStruct(fields)
+		if err != nil {
+			return nil, err
+		}
+		return proto.Marshal(message)
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+// contentType 响应的Content-Type
+func contentType(format Format) string {
+	if format == FormatXML {
+		return string(format) + "; charset=utf-8"
+	}
+	return string(format)
+}
+
+// decodeGeneric 将JSON解码为通用结构，数字保留为json.Number以免丢失精度
+func decodeGeneric(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// normalizeNumbers 将json.Number转换为整数或浮点数
+func normalizeNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+	}
+	return value
+}
+
+// jsonToXML 按JSON的字段顺序输出XML，数组元素使用item指定的元素名，null字段省略
+func jsonToXML(data []byte, root xml.StartElement, item string) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	if err := writeXML(dec, enc, root, item); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeXML 读取一个JSON值并写为start元素
+func writeXML(dec *json.Decoder, enc *xml.Encoder, start xml.StartElement, item string) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	switch t := token.(type) {
+	case json.Delim:
+		for dec.More() {
+			child := item
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				child = xmlName(key.(string))
+			}
+			if err := writeXML(dec, enc, xml.StartElement{Name: xml.Name{Local: child}}, item); err != nil {
+				return err
+			}
+		}
+		// 读取结束的 } 或 ]
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	default:
+		if err := enc.EncodeToken(xml.CharData(fmt.Sprint(t))); err != nil {
+			return err
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+// xmlName 将JSON字段名转换为合法的XML元素名
+func xmlName(key string) string {
+	if key == "" {
+		return "_"
+	}
+	name := []rune(key)
+	for i, r := range name {
+		valid := r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' ||
+			i > 0 && (r == '-' || r == '.' || r >= '0' && r <= '9')
+		if !valid {
+			name[i] = '_'
+		}
+	}
+	return string(name)
+}
@@ -36,12 +36,18 @@ const (
 	CodeUnauthorized = 401
 	CodeForbidden    = 403
 	CodeNotFound     = 404
+	CodeConflict     = 409
 	CodeServerError  = 500
+
+	CodeNotAcceptable        = 406
+	CodeRequestTooLarge      = 413
+	CodeUnsupportedMediaType = 415
+	CodeUnprocessableEntity  = 422
 )
 
 // Success 成功响应
 func Success(c *gin.Context, data interface{}) {
-	c.JSON(http.StatusOK, Response{
+	Render(c, http.StatusOK, Response{
 		Code:    CodeSuccess,
 		Message: "success",
 		Data:    data,
@@ -50,7 +56,7 @@ func Success(c *gin.Context, data interface{}) {
 
 // SuccessWithMessage 带消息的成功响应
 func SuccessWithMessage(c *gin.Context, message string, data interface{}) {
-	c.JSON(http.StatusOK, Response{
+	Render(c, http.StatusOK, Response{
 		Code:    CodeSuccess,
 		Message: message,
 		Data:    data,
@@ -59,7 +65,7 @@ func SuccessWithMessage(c *gin.Context, message string, data interface{}) {
 
 // SuccessPage 分页成功响应
 func SuccessPage(c *gin.Context, data interface{}, meta PageMeta) {
-	c.JSON(http.StatusOK, PageResponse{
+	Render(c, http.StatusOK, PageResponse{
 		Code:    CodeSuccess,
 		Message: "success",
 		Data:    data,
@@ -69,51 +75,78 @@ func SuccessPage(c *gin.Context, data interface{}, meta PageMeta) {
 
 // Error 错误响应
 func Error(c *gin.Context, code int, message string) {
-	httpStatus := getHTTPStatus(code)
-	c.JSON(httpStatus, Response{
-		Code:    code,
-		Message: message,
-	})
+	renderError(c, getHTTPStatus(code), code, "", message, nil)
 }
 
 // BadRequest 400错误响应
 func BadRequest(c *gin.Context, message string) {
-	c.JSON(http.StatusBadRequest, Response{
-		Code:    CodeBadRequest,
-		Message: message,
-	})
+	renderError(c, http.StatusBadRequest, CodeBadRequest, "", message, nil)
+}
+
+// FieldError 字段校验错误
+type FieldError struct {
+	In      string `json:"in"`              // path、query、header 或 body
+	Field   string `json:"field,omitempty"` // 参数名或请求体中的字段路径
+	Message string `json:"message"`
+}
+
+// ValidationError 400参数校验错误响应，data中列出各字段的错误
+func ValidationError(c *gin.Context, message string, errs []FieldError) {
+	renderError(c, http.StatusBadRequest, CodeBadRequest, problemValidation, message, errs)
 }
 
 // Unauthorized 401错误响应
 func Unauthorized(c *gin.Context, message string) {
-	c.JSON(http.StatusUnauthorized, Response{
-		Code:    CodeUnauthorized,
-		Message: message,
-	})
+	renderError(c, http.StatusUnauthorized, CodeUnauthorized, "", message, nil)
 }
 
 // Forbidden 403错误响应
 func Forbidden(c *gin.Context, message string) {
-	c.JSON(http.StatusForbidden, Response{
-		Code:    CodeForbidden,
-		Message: message,
-	})
+	renderError(c, http.StatusForbidden, CodeForbidden, "", message, nil)
 }
 
 // NotFound 404错误响应
 func NotFound(c *gin.Context, message string) {
-	c.JSON(http.StatusNotFound, Response{
-		Code:    CodeNotFound,
+	renderError(c, http.StatusNotFound, CodeNotFound, "", message, nil)
+}
+
+// Conflict 409错误响应
+func Conflict(c *gin.Context, message string) {
+	renderError(c, http.StatusConflict, CodeConflict, "", message, nil)
+}
+
+// RequestTooLarge 413错误响应，请求体超过限制
+func RequestTooLarge(c *gin.Context, message string) {
+	renderError(c, http.StatusRequestEntityTooLarge, CodeRequestTooLarge, "", message, nil)
+}
+
+// UnprocessableEntity 422错误响应，请求格式正确但无法处理
+func UnprocessableEntity(c *gin.Context, message string) {
+	renderError(c, http.StatusUnprocessableEntity, CodeUnprocessableEntity, "", message, nil)
+}
+
+// NotAcceptable 406错误响应，没有客户端可接受的格式，因此固定使用JSON
+func NotAcceptable(c *gin.Context) {
+	message := "No acceptable response format, supported: " + supportedFormats()
+	if useProblem(c) {
+		RenderProblem(c, NewProblem(c, http.StatusNotAcceptable, "", message, nil))
+		return
+	}
+	c.JSON(http.StatusNotAcceptable, Response{
+		Code:    CodeNotAcceptable,
 		Message: message,
 	})
 }
 
+// UnsupportedMediaType 415错误响应，请求体格式不受支持
+func UnsupportedMediaType(c *gin.Context) {
+	message := "Unsupported request content type, supported: " + supportedFormats()
+	renderError(c, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, "", message, nil)
+}
+
 // ServerError 500错误响应
 func ServerError(c *gin.Context, message string) {
-	c.JSON(http.StatusInternalServerError, Response{
-		Code:    CodeServerError,
-		Message: message,
-	})
+	renderError(c, http.StatusInternalServerError, CodeServerError, "", message, nil)
 }
 
 // getHTTPStatus 根据业务码获取HTTP状态码
@@ -129,6 +162,14 @@ func getHTTPStatus(code int) int {
 		return http.StatusForbidden
 	case CodeNotFound:
 		return http.StatusNotFound
+	case CodeConflict:
+		return http.StatusConflict
+	case CodeNotAcceptable:
+		return http.StatusNotAcceptable
+	case CodeUnsupportedMediaType:
+		return http.StatusUnsupportedMediaType
+	case CodeUnprocessableEntity:
+		return http.StatusUnprocessableEntity
 	case CodeServerError:
 		return http.StatusInternalServerError
 	default:
@@ -0,0 +1,19 @@
+package retry
+
+import "time"
+
+// Backoff 第attempts次失败后的重试间隔，从initial开始每次翻倍，不超过max
+// attempts小于等于1时返回initial，max小于initial时按initial处理
+func Backoff(initial, max time.Duration, attempts int) time.Duration {
+	if max < initial {
+		max = initial
+	}
+	delay := initial
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
@@ -0,0 +1,42 @@
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		initial  time.Duration
+		max      time.Duration
+		attempts int
+		want     time.Duration
+	}{
+		{time.Second, time.Minute, 0, time.Second},
+		{time.Second, time.Minute, 1, time.Second},
+		{time.Second, time.Minute, 2, 2 * time.Second},
+		{time.Second, time.Minute, 4, 8 * time.Second},
+		{time.Second, time.Minute, 7, time.Minute},
+		{time.Second, time.Minute, 1000, time.Minute},
+		{30 * time.Second, time.Hour, 8, time.Hour},
+		{time.Minute, time.Second, 3, time.Minute},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.initial, tt.max, tt.attempts); got != tt.want {
+			t.Errorf("Backoff(%s, %s, %d) = %s, want %s", tt.initial, tt.max, tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestJitter(t *testing.T) {
+	if got := Jitter(0); got != 0 {
+		t.Errorf("Jitter(0) = %s, want 0", got)
+	}
+	for _, d := range []time.Duration{time.Nanosecond, time.Millisecond, time.Minute} {
+		for i := 0; i < 100; i++ {
+			if got := Jitter(d); got < d/2 || got > d {
+				t.Fatalf("Jitter(%s) = %s, want between %s and %s", d, got, d/2, d)
+			}
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package retry
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Jitter 在[d/2, d]之间随机取值，避免多个实例按相同节奏同时重试
+func Jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
@@ -0,0 +1,45 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// acquireLease 获取或续期租约，返回是否为持有者
+// 租约过期判断依赖各实例时钟，时钟偏差需明显小于租约时长
+func acquireLease(ctx context.Context, db *gorm.DB, name, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+
+	// 续期自己的租约或接管已过期的租约
+	result := db.WithContext(ctx).Model(&Lease{}).
+		Where("name = ? AND (holder = ? OR expires_at < ?)", name, holder, now).
+		Updates(map[string]interface{}{"holder": holder, "expires_at": expiresAt})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	// 租约不存在时创建，并发创建由主键冲突保证只有一个成功
+	result = db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&Lease{
+		Name:      name,
+		Holder:    holder,
+		ExpiresAt: expiresAt,
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// releaseLease 释放自己持有的租约，便于其他实例立即接管
+func releaseLease(ctx context.Context, db *gorm.DB, name, holder string) error {
+	return db.WithContext(ctx).Model(&Lease{}).
+		Where("name = ? AND holder = ?", name, holder).
+		Update("expires_at", time.Now().Add(-time.Second)).Error
+}
@@ -0,0 +1,49 @@
+package scheduler
+
+import (
+	"time"
+)
+
+// 执行状态
+const (
+	RunStatusRunning   = "running"
+	RunStatusSucceeded = "succeeded"
+	RunStatusFailed    = "failed"
+)
+
+// Lease 调度器领导者租约，同一时刻只有持有者执行定时任务
+type Lease struct {
+	Name      string    `json:"name" gorm:"primaryKey;size:64"`
+	Holder    string    `json:"holder" gorm:"size:128;not null"`
+	ExpiresAt time.Time `json:"expires_at" gorm:"not null"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// TableName 表名
+func (Lease) TableName() string {
+	return "scheduler_leases"
+}
+
+// Run 定时任务执行记录，(task, scheduled_at)唯一，保证每次调度只执行一次
+type Run struct {
+	ID          uint       `json:"id" gorm:"primarykey"`
+	Task        string     `json:"task" gorm:"size:128;not null;uniqueIndex:idx_scheduler_runs_slot"`
+	ScheduledAt time.Time  `json:"scheduled_at" gorm:"not null;uniqueIndex:idx_scheduler_runs_slot"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+	DurationMs  int64      `json:"duration_ms"`
+	Status      string     `json:"status" gorm:"size:16;index"`
+	Error       string     `json:"error" gorm:"size:1024"`
+	Holder      string     `json:"holder" gorm:"size:128"`
+}
+
+// TableName 表名
+func (Run) TableName() string {
+	return "scheduler_runs"
+}
+
+// RunFilter 执行记录查询条件
+type RunFilter struct {
+	Task   string
+	Status string
+}
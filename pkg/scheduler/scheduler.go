@@ -0,0 +1,426 @@
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"go-api-scaffold/pkg/logger"
+	"go-api-scaffold/pkg/strutil"
+)
+
+// leaseName 调度器租约名称
+const leaseName = "scheduler"
+
+// 注册错误
+var (
+	ErrTaskExists  = errors.New("scheduler task already registered")
+	ErrInvalidSpec = errors.New("invalid cron expression")
+)
+
+// cronParser 标准5段cron表达式，支持@every、@daily等描述符
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// TaskFunc 定时任务函数
+type TaskFunc func(ctx context.Context) error
+
+// Options 调度器选项
+type Options struct {
+	LeaseTTL         time.Duration  // 领导者租约时长，持有者崩溃后其他实例最迟在此时长后接管
+	Timeout          time.Duration  // 单次执行超时
+	Location         *time.Location // cron表达式时区，默认本地时区
+	HistoryRetention time.Duration  // 执行记录保留时长，为0时不清理
+}
+
+// task 已注册的定时任务
+type task struct {
+	name     string
+	spec     string
+	schedule cron.Schedule
+	fn       TaskFunc
+	next     time.Time
+	running  bool
+}
+
+// TaskStatus 定时任务状态
+type TaskStatus struct {
+	Name    string    `json:"name"`
+	Spec    string    `json:"spec"`
+	Next    time.Time `json:"next"`
+	Running bool      `json:"running"`
+	LastRun *Run      `json:"last_run,omitempty"`
+}
+
+// Status 调度器状态
+type Status struct {
+	Holder string       `json:"holder"`
+	Leader bool         `json:"leader"`
+	Tasks  []TaskStatus `json:"tasks"`
+}
+
+// Scheduler 分布式定时任务调度器
+// 多个副本通过数据库租约选出领导者，只有领导者触发任务；每次调度写入唯一执行记录，
+// 领导者切换期间也不会重复执行同一次调度
+type Scheduler struct {
+	db     *gorm.DB
+	opts   Options
+	holder string
+	logger logger.Logger
+
+	mu     sync.Mutex
+	tasks  map[string]*task
+	leader bool
+
+	ctx      context.Context // 任务执行上下文，强制关闭时取消
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	started  bool
+}
+
+// New 创建调度器，注册任务后调用Start
+func New(db *gorm.DB, opts Options, logger logger.Logger) *Scheduler {
+	if opts.LeaseTTL <= 0 {
+		opts.LeaseTTL = 30 * time.Second
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Minute
+	}
+	if opts.Location == nil {
+		opts.Location = time.Local
+	}
+
+	// 持有者标识加入随机后缀，同一进程内的多个调度器也互不相同
+	hostname, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Scheduler{
+		db:     db,
+		opts:   opts,
+		holder: fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), hex.EncodeToString(suffix)),
+		logger: logger.WithField("component", "scheduler"),
+		tasks:  make(map[string]*task),
+		ctx:    ctx,
+		cancel: cancel,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if opts.HistoryRetention > 0 {
+		_ = s.Register("scheduler.prune_history", "@hourly", s.pruneHistory)
+	}
+	return s
+}
+
+// Migrate 创建调度器相关表
+func (s *Scheduler) Migrate() error {
+	return s.db.AutoMigrate(&Lease{}, &Run{})
+}
+
+// Register 注册定时任务，spec为cron表达式（如"0 3 * * *"、"@every 10m"），@every按间隔对齐到整点，如每10分钟的:00、:10
+func (s *Scheduler) Register(name, spec string, fn TaskFunc) error {
+	schedule, err := cronParser.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("%w %q for task %s: %v", ErrInvalidSpec, spec, name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.tasks[name]; ok {
+		return fmt.Errorf("%w: %s", ErrTaskExists, name)
+	}
+	s.tasks[name] = &task{
+		name:     name,
+		spec:     spec,
+		schedule: schedule,
+		fn:       fn,
+		next:     nextRun(schedule, time.Now().In(s.opts.Location)),
+	}
+	return nil
+}
+
+// Start 开始调度
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return
+	}
+	s.started = true
+	go s.run()
+}
+
+// Shutdown 停止调度，等待执行中的任务完成并释放租约
+// ctx到期时取消执行中任务的上下文并返回ctx.Err()，此时不释放租约，其他实例在租约到期后接管
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	started := s.started
+	s.mu.Unlock()
+	if !started {
+		return nil
+	}
+
+	s.stopOnce.Do(func() { close(s.stop) })
+	stopped := make(chan struct{})
+	go func() {
+		<-s.done
+		s.wg.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		s.cancel()
+	case <-ctx.Done():
+		s.cancel()
+		return ctx.Err()
+	}
+
+	s.mu.Lock()
+	leader := s.leader
+	s.leader = false
+	s.mu.Unlock()
+	if leader {
+		if err := releaseLease(ctx, s.db, leaseName, s.holder); err != nil {
+			s.logger.WithError(err).Warn("Failed to release scheduler lease")
+		}
+	}
+	return nil
+}
+
+// Status 返回调度器及各任务状态，包含最近一次执行记录
+func (s *Scheduler) Status(ctx context.Context) (*Status, error) {
+	s.mu.Lock()
+	status := &Status{Holder: s.holder, Leader: s.leader}
+	for _, t := range s.tasks {
+		status.Tasks = append(status.Tasks, TaskStatus{
+			Name:    t.name,
+			Spec:    t.spec,
+			Next:    t.next,
+			Running: t.running,
+		})
+	}
+	s.mu.Unlock()
+
+	sort.Slice(status.Tasks, func(i, j int) bool { return status.Tasks[i].Name < status.Tasks[j].Name })
+	for i := range status.Tasks {
+		var run Run
+		err := s.db.WithContext(ctx).Where("task = ?", status.Tasks[i].Name).Order("scheduled_at DESC").Limit(1).Find(&run).Error
+		if err != nil {
+			return nil, err
+		}
+		if run.ID != 0 {
+			status.Tasks[i].LastRun = &run
+		}
+	}
+	return status, nil
+}
+
+// Runs 按调度时间倒序查询执行记录
+func (s *Scheduler) Runs(ctx context.Context, filter RunFilter, offset, limit int) ([]*Run, int64, error) {
+	var runs []*Run
+	var total int64
+
+	query := s.db.WithContext(ctx).Model(&Run{})
+	if filter.Task != "" {
+		query = query.Where("task = ?", filter.Task)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("scheduled_at DESC, id DESC").Offset(offset).Limit(limit).Find(&runs).Error; err != nil {
+		return nil, 0, err
+	}
+	return runs, total, nil
+}
+
+// run 调度主循环：定期续租，并在领导者身份下触发到期任务
+func (s *Scheduler) run() {
+	defer close(s.done)
+
+	tick := time.NewTicker(time.Second)
+	defer tick.Stop()
+
+	var renewAt time.Time
+	for {
+		now := time.Now()
+		if !now.Before(renewAt) {
+			s.renew()
+			renewAt = now.Add(s.opts.LeaseTTL / 3)
+		}
+		s.dispatch(now)
+
+		select {
+		case <-s.stop:
+			return
+		case <-tick.C:
+		}
+	}
+}
+
+// renew 获取或续期领导者租约，身份变化时记录日志
+func (s *Scheduler) renew() {
+	ctx, cancel := context.WithTimeout(context.Background(), s.opts.LeaseTTL/3)
+	defer cancel()
+
+	leader, err := acquireLease(ctx, s.db, leaseName, s.holder, s.opts.LeaseTTL)
+	if err != nil {
+		// 无法确认租约时放弃领导者身份，避免与新领导者同时执行
+		s.logger.WithError(err).Error("Failed to renew scheduler lease")
+		leader = false
+	}
+
+	s.mu.Lock()
+	changed := leader != s.leader
+	s.leader = leader
+	s.mu.Unlock()
+
+	if changed && leader {
+		s.logger.WithField("holder", s.holder).Info("Acquired scheduler leadership")
+	} else if changed {
+		s.logger.WithField("holder", s.holder).Info("Lost scheduler leadership")
+	}
+	if leader {
+		s.failAbandonedRuns(ctx)
+	}
+}
+
+// failAbandonedRuns 将超时仍处于running的执行记录标记为失败
+// 任务上下文在Timeout后取消，超过Timeout加一个租约周期仍未结束的记录说明执行实例已退出
+func (s *Scheduler) failAbandonedRuns(ctx context.Context) {
+	now := time.Now()
+	result := s.db.WithContext(ctx).Model(&Run{}).
+		Where("status = ? AND started_at < ?", RunStatusRunning, now.Add(-s.opts.Timeout-s.opts.LeaseTTL)).
+		Updates(map[string]interface{}{
+			"status":      RunStatusFailed,
+			"error":       "abandoned: holder stopped before the run finished",
+			"finished_at": now,
+		})
+	if result.Error != nil {
+		s.logger.WithError(result.Error).Warn("Failed to mark abandoned scheduled runs")
+	} else if result.RowsAffected > 0 {
+		s.logger.WithField("count", result.RowsAffected).Warn("Marked abandoned scheduled runs as failed")
+	}
+}
+
+// nextRun 计算下次调度时间
+// @every按间隔对齐到固定时间点，各副本算出的调度时间一致，执行记录的唯一索引才能去重
+func nextRun(schedule cron.Schedule, now time.Time) time.Time {
+	if every, ok := schedule.(cron.ConstantDelaySchedule); ok {
+		return now.Truncate(every.Delay).Add(every.Delay)
+	}
+	return schedule.Next(now)
+}
+
+// dispatch 触发到期任务，非领导者只推进下次调度时间
+func (s *Scheduler) dispatch(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tasks {
+		if now.Before(t.next) {
+			continue
+		}
+		scheduledAt := t.next
+		t.next = nextRun(t.schedule, now.In(s.opts.Location))
+
+		if !s.leader {
+			continue
+		}
+		if t.running {
+			s.logger.WithField("task", t.name).Warn("Skipping scheduled run, previous run still in progress")
+			continue
+		}
+
+		t.running = true
+		s.wg.Add(1)
+		go s.execute(t, scheduledAt)
+	}
+}
+
+// execute 抢占执行记录后执行任务
+func (s *Scheduler) execute(t *task, scheduledAt time.Time) {
+	defer func() {
+		s.mu.Lock()
+		t.running = false
+		s.mu.Unlock()
+		s.wg.Done()
+	}()
+
+	log := s.logger.WithFields(map[string]interface{}{
+		"task":         t.name,
+		"scheduled_at": scheduledAt.Format(time.RFC3339),
+	})
+
+	run := &Run{
+		Task:        t.name,
+		ScheduledAt: scheduledAt,
+		StartedAt:   time.Now(),
+		Status:      RunStatusRunning,
+		Holder:      s.holder,
+	}
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(run)
+	if result.Error != nil {
+		log.WithError(result.Error).Error("Failed to record scheduled run")
+		return
+	}
+	if result.RowsAffected == 0 {
+		// 其他实例已执行本次调度
+		return
+	}
+
+	err := s.call(t)
+
+	finished := time.Now()
+	updates := map[string]interface{}{
+		"finished_at": finished,
+		"duration_ms": finished.Sub(run.StartedAt).Milliseconds(),
+		"status":      RunStatusSucceeded,
+		"error":       "",
+	}
+	if err != nil {
+		updates["status"] = RunStatusFailed
+		updates["error"] = strutil.Truncate(err.Error(), 1024)
+		log.WithError(err).Error("Scheduled task failed")
+	} else {
+		log.Debug("Scheduled task succeeded")
+	}
+	if err := s.db.Model(&Run{}).Where("id = ?", run.ID).Updates(updates).Error; err != nil {
+		log.WithError(err).Error("Failed to update scheduled run")
+	}
+}
+
+// call 执行任务函数，捕获panic
+func (s *Scheduler) call(t *task) (err error) {
+	ctx, cancel := context.WithTimeout(s.ctx, s.opts.Timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("task panic: %v", r)
+		}
+	}()
+	return t.fn(ctx)
+}
+
+// pruneHistory 清理过期执行记录
+func (s *Scheduler) pruneHistory(ctx context.Context) error {
+	before := time.Now().Add(-s.opts.HistoryRetention)
+	return s.db.WithContext(ctx).Where("scheduled_at < ? AND status <> ?", before, RunStatusRunning).Delete(&Run{}).Error
+}
@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-api-scaffold/internal/config"
+	"go-api-scaffold/pkg/logger"
+)
+
+func TestNextRun(t *testing.T) {
+	base := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		spec string
+		now  time.Time
+		want time.Time
+	}{
+		{"@every 10m", base.Add(3*time.Minute + 7*time.Second), base.Add(10 * time.Minute)},
+		{"@every 10m", base, base.Add(10 * time.Minute)},
+		{"@every 1h", base.Add(59 * time.Minute), base.Add(time.Hour)},
+		{"@every 90s", base.Add(10 * time.Second), base.Add(90 * time.Second)},
+		{"0 3 * * *", base, time.Date(2026, 5, 2, 3, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", base.Add(time.Minute), base.Add(15 * time.Minute)},
+	}
+	for _, tt := range tests {
+		schedule, err := cronParser.Parse(tt.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := nextRun(schedule, tt.now); !got.Equal(tt.want) {
+			t.Errorf("nextRun(%q, %s) = %s, want %s", tt.spec, tt.now.Format(time.TimeOnly), got, tt.want)
+		}
+	}
+}
+
+// TestNextRunSameSlotAcrossReplicas 不同时间启动的副本对@every任务算出相同的调度时间
+func TestNextRunSameSlotAcrossReplicas(t *testing.T) {
+	schedule, err := cronParser.Parse("@every 5m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2026, 5, 1, 10, 1, 0, 0, time.UTC)
+	replicaA := nextRun(schedule, start)
+	replicaB := nextRun(schedule, start.Add(2*time.Minute+333*time.Millisecond))
+	if !replicaA.Equal(replicaB) {
+		t.Fatalf("replica slots differ: %s vs %s", replicaA, replicaB)
+	}
+}
+
+func TestShutdownTwice(t *testing.T) {
+	log := logger.New(config.LogConfig{Level: "fatal", Format: "text", Output: "stderr"})
+	s := New(nil, Options{}, log)
+	// 未启动时直接返回
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 已启动的调度器重复关闭不会panic；跳过run循环，避免访问数据库
+	s.started = true
+	close(s.done)
+	for i := 0; i < 2; i++ {
+		if err := s.Shutdown(context.Background()); err != nil {
+			t.Fatalf("Shutdown() #%d = %v", i+1, err)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package strutil
+
+// Truncate 截断到最多n个字符，不会截断多字节字符
+// 按字符而不是字节计数，与MySQL varchar(n)的长度限制一致
+func Truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if len(s) <= n {
+		return s
+	}
+	count := 0
+	for i := range s {
+		if count == n {
+			return s[:i]
+		}
+		count++
+	}
+	return s
+}
@@ -0,0 +1,40 @@
+package strutil
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"", 5, ""},
+		{"hello", 5, "hello"},
+		{"hello", 3, "hel"},
+		{"hello", 0, ""},
+		{"hello", -1, ""},
+		{"数据库连接失败", 3, "数据库"},
+		{"数据库连接失败", 7, "数据库连接失败"},
+		{"数据库连接失败", 10, "数据库连接失败"},
+		{"ab数据", 3, "ab数"},
+		{"emoji 😀😀", 7, "emoji 😀"},
+	}
+	for _, tt := range tests {
+		got := Truncate(tt.s, tt.n)
+		if got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("Truncate(%q, %d) = %q is not valid UTF-8", tt.s, tt.n, got)
+		}
+	}
+
+	long := strings.Repeat("错", 2000)
+	if got := Truncate(long, 1024); utf8.RuneCountInString(got) != 1024 {
+		t.Errorf("Truncate(long, 1024) has %d characters", utf8.RuneCountInString(got))
+	}
+}
@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// parentContextKey 保存span开始前的上下文，结束后恢复，避免复用的Statement挂上已结束的span
+const parentContextKey = "tracing:parent_context"
+
+// GormPlugin 为每条SQL创建span的GORM插件
+// 只记录带占位符的SQL，不记录参数值，避免密码哈希等敏感数据进入追踪系统
+type GormPlugin struct {
+	system attribute.KeyValue
+}
+
+// NewGormPlugin 创建GORM追踪插件
+func NewGormPlugin() *GormPlugin {
+	return &GormPlugin{system: semconv.DBSystemNameMySQL}
+}
+
+// Name 插件名称
+func (p *GormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize 在各类操作前后注册回调
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("gorm:create").Register("tracing:before_create", p.before("create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", p.after),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", p.before("query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", p.after),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", p.before("update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", p.after),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", p.before("delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", p.after),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", p.before("row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", p.after),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", p.before("raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", p.after),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// before 开始span并写入Statement上下文，使同一语句内的嵌套调用成为子span
+func (p *GormPlugin) before(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		db.InstanceSet(parentContextKey, ctx)
+
+		spanCtx, _ := Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(p.system, semconv.DBOperationName(operation)),
+		)
+		db.Statement.Context = spanCtx
+	}
+}
+
+// after 补充SQL、影响行数等属性后结束span
+func (p *GormPlugin) after(db *gorm.DB) {
+	span := trace.SpanFromContext(db.Statement.Context)
+	if parent, ok := db.InstanceGet(parentContextKey); ok {
+		db.Statement.Context = parent.(context.Context)
+	}
+	if !span.IsRecording() {
+		span.End()
+		return
+	}
+
+	if db.Statement.Table != "" {
+		span.SetAttributes(semconv.DBCollectionName(db.Statement.Table))
+	}
+	if sql := db.Statement.SQL.String(); sql != "" {
+		span.SetAttributes(semconv.DBQueryText(sql))
+	}
+	span.SetAttributes(attribute.Int64("db.rows_affected", db.RowsAffected))
+
+	// 记录不存在属于正常的业务分支，不标记为错误
+	err := db.Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = nil
+	}
+	End(span, err)
+}
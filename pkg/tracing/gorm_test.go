@@ -0,0 +1,126 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"go-api-scaffold/internal/config"
+)
+
+type account struct {
+	ID       uint
+	Name     string
+	Password string
+}
+
+// newTracedDB 注册memory导出器并返回启用追踪插件的SQLite数据库
+func newTracedDB(t *testing.T) (*Provider, *gorm.DB) {
+	t.Helper()
+	tp, err := New(config.TracingConfig{Enabled: true, Exporter: "memory"}, config.AppConfig{Name: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "trace.db")), &gorm.Config{Logger: gormlogger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	if err := db.AutoMigrate(&account{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Use(NewGormPlugin()); err != nil {
+		t.Fatal(err)
+	}
+	return tp, db
+}
+
+// attrs 以字符串形式返回span属性
+func attrs(span tracetest.SpanStub) map[string]string {
+	m := make(map[string]string, len(span.Attributes))
+	for _, kv := range span.Attributes {
+		m[string(kv.Key)] = kv.Value.Emit()
+	}
+	return m
+}
+
+func TestGormPlugin(t *testing.T) {
+	tp, db := newTracedDB(t)
+	ctx, parent := Start(context.Background(), "UserService.Create")
+
+	if err := db.WithContext(ctx).Create(&account{Name: "alice", Password: "secret-hash"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var found account
+	if err := db.WithContext(ctx).Where("name = ?", "alice").First(&found).Error; err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	spans := tp.Spans()
+	if len(spans) != 3 {
+		t.Fatalf("spans = %d, want create, query and parent", len(spans))
+	}
+	for i, want := range []string{"gorm.create", "gorm.query"} {
+		span := spans[i]
+		if span.Name != want {
+			t.Errorf("span %d = %q, want %q", i, span.Name, want)
+		}
+		if span.Parent.SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("%s is not a child of the service span", span.Name)
+		}
+		a := attrs(span)
+		if a["db.collection.name"] != "accounts" || a["db.rows_affected"] != "1" {
+			t.Errorf("%s attributes = %v", span.Name, a)
+		}
+		// 只记录带占位符的SQL，不记录参数值
+		if sql := a["db.query.text"]; sql == "" || strings.Contains(sql, "alice") || strings.Contains(sql, "secret-hash") {
+			t.Errorf("%s db.query.text = %q, want SQL without values", span.Name, sql)
+		}
+	}
+}
+
+func TestGormPluginErrors(t *testing.T) {
+	tp, db := newTracedDB(t)
+	ctx := context.Background()
+
+	// 记录不存在不标记为错误
+	var missing account
+	if err := db.WithContext(ctx).First(&missing, 42).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("First() error = %v", err)
+	}
+	if err := db.WithContext(ctx).Exec("INSERT INTO missing_table VALUES (1)").Error; err == nil {
+		t.Fatal("Exec() error = nil, want missing table")
+	}
+
+	spans := tp.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("spans = %d, want 2", len(spans))
+	}
+	if spans[0].Name != "gorm.query" || spans[0].Status.Code != codes.Unset {
+		t.Errorf("not found span = %q status %v, want unset", spans[0].Name, spans[0].Status)
+	}
+	if spans[1].Name != "gorm.raw" || spans[1].Status.Code != codes.Error || len(spans[1].Events) != 1 {
+		t.Errorf("failed span = %q status %v events %d, want error", spans[1].Name, spans[1].Status, len(spans[1].Events))
+	}
+	// 复用的Statement恢复为原上下文，不再挂着已结束的span
+	for _, span := range spans {
+		if span.Parent.IsValid() {
+			t.Errorf("%s has parent %s, want root span", span.Name, span.Parent.SpanID())
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"go-api-scaffold/internal/config"
+)
+
+// TracerName 应用内创建span使用的tracer名称
+const TracerName = "go-api-scaffold"
+
+// Provider 追踪提供者，持有导出器以便关闭时刷新缓冲的span
+type Provider struct {
+	tp     *sdktrace.TracerProvider
+	memory *tracetest.InMemoryExporter
+}
+
+// New 根据配置创建追踪提供者并注册为全局TracerProvider和W3C传播器
+// 未启用或导出器为none时返回nil，全局使用no-op实现
+func New(cfg config.TracingConfig, app config.AppConfig) (*Provider, error) {
+	// 无论是否启用都注册传播器，保证traceparent能透传给下游
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporterName := strings.ToLower(cfg.Exporter)
+	if !cfg.Enabled || exporterName == "none" {
+		return nil, nil
+	}
+
+	p := &Provider{}
+	var spanProcessor sdktrace.SpanProcessor
+	switch exporterName {
+	case "otlp", "":
+		var opts []otlptracehttp.Option
+		if cfg.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+		}
+		if cfg.Timeout > 0 {
+			opts = append(opts, otlptracehttp.WithTimeout(cfg.Timeout))
+		}
+		exporter, err := otlptracehttp.New(context.Background(), opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create otlp exporter: %w", err)
+		}
+		spanProcessor = sdktrace.NewBatchSpanProcessor(exporter, sdktrace.WithExportTimeout(cfg.Timeout))
+	case "stdout":
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout exporter: %w", err)
+		}
+		spanProcessor = sdktrace.NewBatchSpanProcessor(exporter)
+	case "memory":
+		// 同步导出，span结束后立即可查
+		p.memory = tracetest.NewInMemoryExporter()
+		spanProcessor = sdktrace.NewSimpleSpanProcessor(p.memory)
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter: %s", cfg.Exporter)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(
+		semconv.ServiceName(app.Name),
+		semconv.ServiceVersion(app.Version),
+		semconv.DeploymentEnvironmentName(app.Environment),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build tracing resource: %w", err)
+	}
+
+	ratio := cfg.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	p.tp = sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(spanProcessor),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(p.tp)
+	return p, nil
+}
+
+// Shutdown 刷新缓冲的span并关闭导出器
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	return p.tp.Shutdown(ctx)
+}
+
+// Spans 返回已结束的span，仅memory导出器可用
+func (p *Provider) Spans() tracetest.SpanStubs {
+	if p == nil || p.memory == nil {
+		return nil
+	}
+	return p.memory.GetSpans()
+}
+
+// Reset 清空memory导出器中的span
+func (p *Provider) Reset() {
+	if p != nil && p.memory != nil {
+		p.memory.Reset()
+	}
+}
+
+// Tracer 返回应用tracer，始终从全局提供者获取以便在初始化后生效
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Start 创建子span
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, opts...)
+}
+
+// End 结束span，err非nil时记录错误并将状态置为Error
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}